@@ -4,6 +4,9 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 	"trackprosto/utils/common"
 )
 
@@ -23,8 +26,48 @@ func (db *DbConfig) ToDSN() string {
 	)
 }
 
+// ShopConfig is printed on the head of invoices and receipts. The footer
+// closes the receipts of the thermal printers. Invoice totals are rounded to
+// the nearest multiple of RoundingUnit rupiah.
+type ShopConfig struct {
+	Name          string
+	Address       string
+	PhoneNumber   string
+	ReceiptFooter string
+	RoundingUnit  float64
+}
+
+// JWTConfig holds the keys access tokens are signed with, by key id. New
+// tokens are signed with the active key and tokens signed with any listed key
+// are accepted, so a key is rotated by adding the new one, making it active
+// and dropping the old one once its tokens have expired.
+type JWTConfig struct {
+	Keys            map[string][]byte
+	ActiveKeyID     string
+	AccessTokenTTL  time.Duration
+	RefreshTokenTTL time.Duration
+}
+
+// PasswordPolicy is what every new password has to satisfy. Passwords with
+// fewer than MinLength characters or missing a required kind of character
+// are refused.
+type PasswordPolicy struct {
+	MinLength     int  `json:"min_length"`
+	RequireUpper  bool `json:"require_upper"`
+	RequireLower  bool `json:"require_lower"`
+	RequireDigit  bool `json:"require_digit"`
+	RequireSymbol bool `json:"require_symbol"`
+}
+
 type Config struct {
 	DbConfig
+	Shop ShopConfig
+	JWT  JWTConfig
+	// TrustedProxies lists the reverse proxies whose X-Forwarded-For header
+	// is believed when telling the client IP address, which failed logins
+	// are counted against. Without any the address of the connection is used.
+	TrustedProxies []string
+	PasswordPolicy PasswordPolicy
 }
 
 func (c *Config) readConfigFile() error {
@@ -40,6 +83,38 @@ func (c *Config) readConfigFile() error {
 		Password: os.Getenv("DB_PASSWORD"),
 		Driver:   os.Getenv("DB_DRIVER"),
 	}
+	c.Shop = ShopConfig{
+		Name:          os.Getenv("SHOP_NAME"),
+		Address:       os.Getenv("SHOP_ADDRESS"),
+		PhoneNumber:   os.Getenv("SHOP_PHONE"),
+		ReceiptFooter: os.Getenv("SHOP_RECEIPT_FOOTER"),
+	}
+	if c.Shop.Name == "" {
+		c.Shop.Name = "TrackPro"
+	}
+	c.Shop.RoundingUnit = 1
+	if unit := os.Getenv("SHOP_ROUNDING_UNIT"); unit != "" {
+		roundingUnit, err := strconv.ParseFloat(unit, 64)
+		if err != nil || roundingUnit <= 0 {
+			return errors.New("invalid SHOP_ROUNDING_UNIT")
+		}
+		c.Shop.RoundingUnit = roundingUnit
+	}
+	jwtConfig, err := readJWTConfig()
+	if err != nil {
+		return err
+	}
+	c.JWT = jwtConfig
+	passwordPolicy, err := readPasswordPolicy()
+	if err != nil {
+		return err
+	}
+	c.PasswordPolicy = passwordPolicy
+	for _, proxy := range strings.Split(os.Getenv("TRUSTED_PROXIES"), ",") {
+		if proxy = strings.TrimSpace(proxy); proxy != "" {
+			c.TrustedProxies = append(c.TrustedProxies, proxy)
+		}
+	}
 
 	if c.DbConfig.Host == "" || c.DbConfig.Port == "" || c.DbConfig.Name == "" ||
 		c.DbConfig.User == "" || c.DbConfig.Password == "" || c.DbConfig.Driver == "" {
@@ -47,6 +122,91 @@ func (c *Config) readConfigFile() error {
 	}
 	return nil
 }
+
+// minJWTSecretLength is the shortest secret accepted for signing tokens.
+const minJWTSecretLength = 32
+
+// readJWTConfig reads JWT_KEYS as comma separated kid:secret pairs. The first
+// key signs new tokens unless JWT_ACTIVE_KID names another one. Short secrets
+// are refused so a placeholder cannot end up signing tokens.
+func readJWTConfig() (JWTConfig, error) {
+	cfg := JWTConfig{
+		Keys:            make(map[string][]byte),
+		ActiveKeyID:     os.Getenv("JWT_ACTIVE_KID"),
+		AccessTokenTTL:  15 * time.Minute,
+		RefreshTokenTTL: 30 * 24 * time.Hour,
+	}
+	for _, pair := range strings.Split(os.Getenv("JWT_KEYS"), ",") {
+		if strings.TrimSpace(pair) == "" {
+			continue
+		}
+		kid, secret, ok := strings.Cut(strings.TrimSpace(pair), ":")
+		if !ok || kid == "" || secret == "" {
+			return JWTConfig{}, errors.New("invalid JWT_KEYS, expected kid:secret pairs")
+		}
+		if len(secret) < minJWTSecretLength {
+			return JWTConfig{}, fmt.Errorf("JWT_KEYS secret of %s is shorter than %d characters", kid, minJWTSecretLength)
+		}
+		cfg.Keys[kid] = []byte(secret)
+		if cfg.ActiveKeyID == "" {
+			cfg.ActiveKeyID = kid
+		}
+	}
+	if len(cfg.Keys) == 0 {
+		return JWTConfig{}, errors.New("missing JWT_KEYS")
+	}
+	if _, ok := cfg.Keys[cfg.ActiveKeyID]; !ok {
+		return JWTConfig{}, errors.New("JWT_ACTIVE_KID is not one of JWT_KEYS")
+	}
+	for name, ttl := range map[string]*time.Duration{
+		"JWT_ACCESS_TTL":  &cfg.AccessTokenTTL,
+		"JWT_REFRESH_TTL": &cfg.RefreshTokenTTL,
+	} {
+		if value := os.Getenv(name); value != "" {
+			duration, err := time.ParseDuration(value)
+			if err != nil || duration <= 0 {
+				return JWTConfig{}, fmt.Errorf("invalid %s", name)
+			}
+			*ttl = duration
+		}
+	}
+	return cfg, nil
+}
+
+// readPasswordPolicy reads PASSWORD_MIN_LENGTH and the PASSWORD_REQUIRE_*
+// switches. By default passwords need 8 characters with upper and lower case
+// letters and a digit.
+func readPasswordPolicy() (PasswordPolicy, error) {
+	policy := PasswordPolicy{
+		MinLength:    8,
+		RequireUpper: true,
+		RequireLower: true,
+		RequireDigit: true,
+	}
+	if value := os.Getenv("PASSWORD_MIN_LENGTH"); value != "" {
+		minLength, err := strconv.Atoi(value)
+		if err != nil || minLength < 1 {
+			return PasswordPolicy{}, errors.New("invalid PASSWORD_MIN_LENGTH")
+		}
+		policy.MinLength = minLength
+	}
+	for name, require := range map[string]*bool{
+		"PASSWORD_REQUIRE_UPPER":  &policy.RequireUpper,
+		"PASSWORD_REQUIRE_LOWER":  &policy.RequireLower,
+		"PASSWORD_REQUIRE_DIGIT":  &policy.RequireDigit,
+		"PASSWORD_REQUIRE_SYMBOL": &policy.RequireSymbol,
+	} {
+		if value := os.Getenv(name); value != "" {
+			required, err := strconv.ParseBool(value)
+			if err != nil {
+				return PasswordPolicy{}, fmt.Errorf("invalid %s", name)
+			}
+			*require = required
+		}
+	}
+	return policy, nil
+}
+
 func NewConfig() (Config, error) {
 	cfg := Config{}
 	err := cfg.readConfigFile()
@@ -0,0 +1,2 @@
+DROP INDEX credit_payments_idempotency_key_idx;
+ALTER TABLE credit_payments DROP COLUMN idempotency_key;
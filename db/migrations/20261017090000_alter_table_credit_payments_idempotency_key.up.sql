@@ -0,0 +1,2 @@
+ALTER TABLE credit_payments ADD COLUMN idempotency_key VARCHAR;
+CREATE UNIQUE INDEX credit_payments_idempotency_key_idx ON credit_payments (idempotency_key) WHERE idempotency_key IS NOT NULL AND idempotency_key <> '';
@@ -0,0 +1,3 @@
+ALTER TABLE customers ADD COLUMN debt NUMERIC;
+UPDATE customers SET debt = COALESCE((SELECT SUM(debit - credit) FROM customer_ledger WHERE customer_ledger.customer_id = customers.id), 0);
+DROP TABLE customer_ledger;
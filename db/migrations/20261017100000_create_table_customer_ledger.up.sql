@@ -0,0 +1,35 @@
+CREATE TABLE customer_ledger (
+    id VARCHAR PRIMARY KEY,
+    customer_id VARCHAR,
+    entry_date DATE,
+    source_type VARCHAR,
+    source_id VARCHAR,
+    reference VARCHAR,
+    debit NUMERIC DEFAULT 0,
+    credit NUMERIC DEFAULT 0,
+    description VARCHAR,
+    created_at TIMESTAMP,
+    created_by VARCHAR
+);
+CREATE INDEX customer_ledger_customer_id_entry_date_idx ON customer_ledger (customer_id, entry_date);
+
+-- Rebuild the ledger from existing invoices and their payments. "out" invoices
+-- are owed to us (debit), "in" invoices are owed by us (credit).
+INSERT INTO customer_ledger (id, customer_id, entry_date, source_type, source_id, reference, debit, credit, description, created_at, created_by)
+SELECT gen_random_uuid()::VARCHAR, th.customer_id, th.date, 'transaction', th.id, th.inv_number,
+    CASE WHEN th.tx_type = 'out' THEN th.total ELSE 0 END,
+    CASE WHEN th.tx_type = 'in' THEN th.total ELSE 0 END,
+    'Invoice ' || th.inv_number, th.created_at, th.created_by
+FROM transaction_headers th
+WHERE th.is_active = true;
+
+INSERT INTO customer_ledger (id, customer_id, entry_date, source_type, source_id, reference, debit, credit, description, created_at, created_by)
+SELECT gen_random_uuid()::VARCHAR, th.customer_id, cp.payment_date, 'credit_payment', cp.id, cp.inv_number,
+    CASE WHEN th.tx_type = 'in' THEN cp.amount ELSE 0 END,
+    CASE WHEN th.tx_type = 'out' THEN cp.amount ELSE 0 END,
+    COALESCE(cp.notes, 'Payment'), cp.created_at, cp.created_by
+FROM credit_payments cp
+JOIN transaction_headers th ON th.inv_number = cp.inv_number
+WHERE th.is_active = true;
+
+ALTER TABLE customers DROP COLUMN debt;
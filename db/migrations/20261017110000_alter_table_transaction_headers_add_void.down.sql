@@ -0,0 +1,4 @@
+ALTER TABLE credit_payments DROP COLUMN is_active;
+ALTER TABLE transaction_headers DROP COLUMN void_reason;
+ALTER TABLE transaction_headers DROP COLUMN voided_by;
+ALTER TABLE transaction_headers DROP COLUMN voided_at;
@@ -0,0 +1,5 @@
+ALTER TABLE transaction_headers ADD COLUMN voided_at TIMESTAMP;
+ALTER TABLE transaction_headers ADD COLUMN voided_by VARCHAR;
+ALTER TABLE transaction_headers ADD COLUMN void_reason VARCHAR;
+ALTER TABLE credit_payments ADD COLUMN is_active BOOLEAN DEFAULT true;
+UPDATE credit_payments SET is_active = true;
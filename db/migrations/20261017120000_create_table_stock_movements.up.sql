@@ -0,0 +1,19 @@
+CREATE TABLE stock_movements (
+    id VARCHAR PRIMARY KEY,
+    meat_id VARCHAR,
+    qty NUMERIC,
+    stock_after NUMERIC,
+    source_type VARCHAR,
+    source_id VARCHAR,
+    reference VARCHAR,
+    notes VARCHAR,
+    created_at TIMESTAMP,
+    created_by VARCHAR
+);
+CREATE INDEX stock_movements_meat_id_created_at_idx ON stock_movements (meat_id, created_at);
+
+-- Open the journal with the current stock so the movements add up to meats.stock.
+INSERT INTO stock_movements (id, meat_id, qty, stock_after, source_type, source_id, reference, notes, created_at, created_by)
+SELECT gen_random_uuid()::VARCHAR, id, stock, stock, 'adjustment', id, NULL, 'Opening balance', CURRENT_TIMESTAMP, 'system'
+FROM meats
+WHERE stock IS NOT NULL AND stock <> 0;
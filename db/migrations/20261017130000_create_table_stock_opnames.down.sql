@@ -0,0 +1,3 @@
+ALTER TABLE stock_movements DROP COLUMN reason_code;
+DROP TABLE stock_opname_items;
+DROP TABLE stock_opnames;
@@ -0,0 +1,29 @@
+CREATE TABLE stock_opnames (
+    id VARCHAR PRIMARY KEY,
+    status VARCHAR,
+    notes VARCHAR,
+    started_at TIMESTAMP,
+    started_by VARCHAR,
+    posted_at TIMESTAMP,
+    posted_by VARCHAR,
+    created_at TIMESTAMP,
+    updated_at TIMESTAMP,
+    updated_by VARCHAR
+);
+
+CREATE TABLE stock_opname_items (
+    id VARCHAR PRIMARY KEY,
+    opname_id VARCHAR,
+    meat_id VARCHAR,
+    meat_name VARCHAR,
+    system_stock NUMERIC,
+    counted_stock NUMERIC,
+    variance NUMERIC,
+    reason_code VARCHAR,
+    notes VARCHAR,
+    counted_at TIMESTAMP,
+    counted_by VARCHAR,
+    UNIQUE (opname_id, meat_id)
+);
+
+ALTER TABLE stock_movements ADD COLUMN reason_code VARCHAR;
@@ -0,0 +1,4 @@
+DROP TABLE lot_consumptions;
+DROP TABLE meat_lots;
+ALTER TABLE meats DROP COLUMN shelf_life_days;
+ALTER TABLE meats DROP COLUMN picking_method;
@@ -0,0 +1,51 @@
+ALTER TABLE meats ADD COLUMN picking_method VARCHAR DEFAULT 'fifo';
+ALTER TABLE meats ADD COLUMN shelf_life_days INTEGER DEFAULT 0;
+
+CREATE TABLE meat_lots (
+    id VARCHAR PRIMARY KEY,
+    meat_id VARCHAR,
+    meat_name VARCHAR,
+    transaction_detail_id VARCHAR,
+    reference VARCHAR,
+    supplier_id VARCHAR,
+    supplier_name VARCHAR,
+    received_date DATE,
+    expiry_date DATE,
+    cost_price NUMERIC,
+    qty_received NUMERIC,
+    qty_remaining NUMERIC,
+    is_active BOOLEAN DEFAULT TRUE,
+    created_at TIMESTAMP,
+    created_by VARCHAR
+);
+CREATE INDEX meat_lots_meat_id_idx ON meat_lots (meat_id, received_date);
+CREATE INDEX meat_lots_transaction_detail_id_idx ON meat_lots (transaction_detail_id);
+
+CREATE TABLE lot_consumptions (
+    id VARCHAR PRIMARY KEY,
+    lot_id VARCHAR,
+    meat_id VARCHAR,
+    movement_id VARCHAR,
+    source_type VARCHAR,
+    source_id VARCHAR,
+    qty NUMERIC,
+    unit_cost NUMERIC,
+    is_active BOOLEAN DEFAULT TRUE,
+    created_at TIMESTAMP,
+    created_by VARCHAR
+);
+CREATE INDEX lot_consumptions_source_idx ON lot_consumptions (source_type, source_id);
+
+-- Stock on hand before lot tracking becomes one opening lot per meat, costed at
+-- the last purchase price of that meat.
+INSERT INTO meat_lots (id, meat_id, meat_name, reference, received_date, cost_price, qty_received, qty_remaining, is_active, created_at, created_by)
+SELECT gen_random_uuid()::VARCHAR, m.id, m.name, 'Opening balance', CURRENT_DATE,
+       COALESCE((SELECT d.price
+                 FROM transaction_details d
+                 JOIN transaction_headers h ON h.id = d.transaction_id
+                 WHERE d.meat_id = m.id AND h.tx_type = 'in' AND h.is_active = true
+                 ORDER BY h.created_at DESC
+                 LIMIT 1), 0),
+       m.stock, m.stock, true, CURRENT_TIMESTAMP, 'system'
+FROM meats m
+WHERE m.stock > 0;
@@ -0,0 +1,14 @@
+ALTER TABLE transaction_details ADD COLUMN cogs NUMERIC DEFAULT 0;
+
+-- Sales made before lot tracking are costed at the last purchase price of the
+-- meat on or before the sale date.
+UPDATE transaction_details d
+SET cogs = d.qty * COALESCE((SELECT pd.price
+                             FROM transaction_details pd
+                             JOIN transaction_headers ph ON ph.id = pd.transaction_id
+                             WHERE pd.meat_id = d.meat_id AND ph.tx_type = 'in' AND ph.is_active = true
+                               AND ph.date <= h.date
+                             ORDER BY ph.date DESC, ph.created_at DESC
+                             LIMIT 1), 0)
+FROM transaction_headers h
+WHERE h.id = d.transaction_id AND h.tx_type = 'out';
@@ -0,0 +1,12 @@
+CREATE TABLE day_closings (
+    date DATE PRIMARY KEY,
+    opening_balance NUMERIC,
+    total_inflow NUMERIC,
+    total_outflow NUMERIC,
+    closing_balance NUMERIC,
+    counted_cash NUMERIC,
+    cash_difference NUMERIC,
+    notes VARCHAR,
+    closed_at TIMESTAMP,
+    closed_by VARCHAR
+);
@@ -0,0 +1,2 @@
+ALTER TABLE transaction_headers DROP COLUMN due_date;
+ALTER TABLE customers DROP COLUMN payment_term_days;
@@ -0,0 +1,3 @@
+ALTER TABLE customers ADD COLUMN payment_term_days INTEGER DEFAULT 0;
+ALTER TABLE transaction_headers ADD COLUMN due_date DATE;
+UPDATE transaction_headers SET due_date = date WHERE due_date IS NULL;
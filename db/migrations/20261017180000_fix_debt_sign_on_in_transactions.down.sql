@@ -0,0 +1 @@
+UPDATE transaction_headers SET debt = -debt WHERE tx_type = 'in' AND debt > 0;
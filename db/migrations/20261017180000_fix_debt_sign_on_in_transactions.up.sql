@@ -0,0 +1,3 @@
+-- "in" invoices stored what we owe the supplier as a negative debt on creation
+-- while installments stored it as positive. Debt is now always positive.
+UPDATE transaction_headers SET debt = -debt WHERE tx_type = 'in' AND debt < 0;
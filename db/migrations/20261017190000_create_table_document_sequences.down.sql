@@ -0,0 +1,2 @@
+ALTER TABLE transaction_headers DROP CONSTRAINT transaction_headers_inv_number_key;
+DROP TABLE document_sequences;
@@ -0,0 +1,33 @@
+CREATE TABLE document_sequences (
+    prefix VARCHAR,
+    period VARCHAR,
+    last_value INTEGER NOT NULL DEFAULT 0,
+    updated_at TIMESTAMP,
+    PRIMARY KEY (prefix, period)
+);
+
+-- Continue every day's numbering after the highest number already issued.
+INSERT INTO document_sequences (prefix, period, last_value, updated_at)
+SELECT split_part(inv_number, '-', 1), split_part(inv_number, '-', 2),
+       MAX(split_part(inv_number, '-', 3)::INTEGER), CURRENT_TIMESTAMP
+FROM transaction_headers
+WHERE inv_number ~ '^(MJP|INV)-[0-9]{8}-[0-9]+$'
+GROUP BY 1, 2;
+
+INSERT INTO document_sequences (prefix, period, last_value, updated_at)
+SELECT 'DE', substring(de_note FROM 4 FOR 8), MAX(substring(de_note FROM 12)::INTEGER), CURRENT_TIMESTAMP
+FROM daily_expenditures
+WHERE de_note ~ '^DE-[0-9]{12,}$'
+GROUP BY 1, 2;
+
+-- Numbers handed out twice by the old counter keep their first holder; later
+-- duplicates get a suffix so the unique constraint can be created.
+UPDATE transaction_headers h
+SET inv_number = d.inv_number || '-' || d.rn
+FROM (
+    SELECT id, inv_number, ROW_NUMBER() OVER (PARTITION BY inv_number ORDER BY created_at, id) - 1 AS rn
+    FROM transaction_headers
+) d
+WHERE h.id = d.id AND d.rn > 0;
+
+ALTER TABLE transaction_headers ADD CONSTRAINT transaction_headers_inv_number_key UNIQUE (inv_number);
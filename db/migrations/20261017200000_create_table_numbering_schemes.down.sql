@@ -0,0 +1 @@
+DROP TABLE numbering_schemes;
@@ -0,0 +1,14 @@
+CREATE TABLE numbering_schemes (
+    document_type VARCHAR PRIMARY KEY,
+    prefix VARCHAR NOT NULL,
+    template VARCHAR NOT NULL,
+    reset_period VARCHAR NOT NULL,
+    updated_at TIMESTAMP,
+    updated_by VARCHAR
+);
+
+-- The formats used so far.
+INSERT INTO numbering_schemes (document_type, prefix, template, reset_period, updated_at, updated_by) VALUES
+    ('invoice_in', 'MJP', '{PREFIX}-{YYYY}{MM}{DD}-{SEQ:4}', 'daily', CURRENT_TIMESTAMP, 'system'),
+    ('invoice_out', 'INV', '{PREFIX}-{YYYY}{MM}{DD}-{SEQ:4}', 'daily', CURRENT_TIMESTAMP, 'system'),
+    ('expenditure', 'DE', '{PREFIX}-{YYYY}{MM}{DD}{SEQ:4}', 'daily', CURRENT_TIMESTAMP, 'system');
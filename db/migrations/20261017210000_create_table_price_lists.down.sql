@@ -0,0 +1,5 @@
+ALTER TABLE transaction_details DROP COLUMN list_price;
+ALTER TABLE transaction_headers DROP COLUMN price_approved_by;
+ALTER TABLE customers DROP COLUMN price_tier;
+DROP TABLE price_list_items;
+DROP TABLE price_lists;
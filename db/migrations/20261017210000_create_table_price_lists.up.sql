@@ -0,0 +1,23 @@
+CREATE TABLE price_lists (
+    id VARCHAR PRIMARY KEY,
+    name VARCHAR NOT NULL,
+    list_type VARCHAR NOT NULL,
+    company_id VARCHAR REFERENCES companies (id),
+    valid_from DATE NOT NULL,
+    valid_to DATE,
+    max_deviation_pct NUMERIC NOT NULL DEFAULT 10,
+    is_active BOOLEAN DEFAULT TRUE,
+    created_at TIMESTAMP,
+    updated_at TIMESTAMP,
+    created_by VARCHAR,
+    updated_by VARCHAR
+);
+CREATE TABLE price_list_items (
+    price_list_id VARCHAR REFERENCES price_lists (id),
+    meat_id VARCHAR REFERENCES meats (id),
+    price NUMERIC NOT NULL,
+    PRIMARY KEY (price_list_id, meat_id)
+);
+ALTER TABLE customers ADD COLUMN price_tier VARCHAR DEFAULT 'retail';
+ALTER TABLE transaction_headers ADD COLUMN price_approved_by VARCHAR;
+ALTER TABLE transaction_details ADD COLUMN list_price NUMERIC DEFAULT 0;
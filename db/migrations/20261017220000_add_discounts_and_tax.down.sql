@@ -0,0 +1,12 @@
+ALTER TABLE transaction_headers DROP COLUMN rounding;
+ALTER TABLE transaction_headers DROP COLUMN tax;
+ALTER TABLE transaction_headers DROP COLUMN tax_rate;
+ALTER TABLE transaction_headers DROP COLUMN discount;
+ALTER TABLE transaction_headers DROP COLUMN discount_value;
+ALTER TABLE transaction_headers DROP COLUMN discount_type;
+ALTER TABLE transaction_headers DROP COLUMN subtotal;
+ALTER TABLE transaction_details DROP COLUMN discount;
+ALTER TABLE transaction_details DROP COLUMN discount_value;
+ALTER TABLE transaction_details DROP COLUMN discount_type;
+ALTER TABLE transaction_details DROP COLUMN subtotal;
+ALTER TABLE companies DROP COLUMN tax_rate;
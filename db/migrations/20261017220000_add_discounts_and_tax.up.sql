@@ -0,0 +1,14 @@
+ALTER TABLE companies ADD COLUMN tax_rate NUMERIC NOT NULL DEFAULT 0;
+ALTER TABLE transaction_details ADD COLUMN subtotal NUMERIC DEFAULT 0;
+ALTER TABLE transaction_details ADD COLUMN discount_type VARCHAR;
+ALTER TABLE transaction_details ADD COLUMN discount_value NUMERIC DEFAULT 0;
+ALTER TABLE transaction_details ADD COLUMN discount NUMERIC DEFAULT 0;
+ALTER TABLE transaction_headers ADD COLUMN subtotal NUMERIC DEFAULT 0;
+ALTER TABLE transaction_headers ADD COLUMN discount_type VARCHAR;
+ALTER TABLE transaction_headers ADD COLUMN discount_value NUMERIC DEFAULT 0;
+ALTER TABLE transaction_headers ADD COLUMN discount NUMERIC DEFAULT 0;
+ALTER TABLE transaction_headers ADD COLUMN tax_rate NUMERIC DEFAULT 0;
+ALTER TABLE transaction_headers ADD COLUMN tax NUMERIC DEFAULT 0;
+ALTER TABLE transaction_headers ADD COLUMN rounding NUMERIC DEFAULT 0;
+UPDATE transaction_details SET subtotal = total;
+UPDATE transaction_headers SET subtotal = total;
@@ -0,0 +1,10 @@
+CREATE TABLE refresh_tokens (
+    id VARCHAR PRIMARY KEY,
+    user_id VARCHAR NOT NULL REFERENCES users (id),
+    token_hash VARCHAR NOT NULL UNIQUE,
+    expires_at TIMESTAMP NOT NULL,
+    revoked_at TIMESTAMP,
+    replaced_by VARCHAR,
+    created_at TIMESTAMP
+);
+CREATE INDEX idx_refresh_tokens_user_id ON refresh_tokens (user_id);
@@ -0,0 +1 @@
+ALTER TABLE users ADD COLUMN token_version INTEGER NOT NULL DEFAULT 1;
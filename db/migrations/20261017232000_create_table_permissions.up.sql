@@ -0,0 +1,167 @@
+CREATE TABLE permissions (
+    code VARCHAR PRIMARY KEY,
+    description VARCHAR
+);
+CREATE TABLE role_permissions (
+    role VARCHAR NOT NULL,
+    permission_code VARCHAR NOT NULL REFERENCES permissions (code),
+    PRIMARY KEY (role, permission_code)
+);
+INSERT INTO permissions (code, description) VALUES
+    ('transaction:view', 'View invoices and print them'),
+    ('transaction:create', 'Create invoices'),
+    ('transaction:void', 'Void invoices'),
+    ('transaction:export', 'Export invoices'),
+    ('price:approve_deviation', 'Approve sale prices that deviate from the price list'),
+    ('price:suggest', 'Look up the suggested price of a meat for a customer'),
+    ('price_list:view', 'View price lists'),
+    ('price_list:manage', 'Create, update and delete price lists'),
+    ('credit_payment:view', 'View installments and print their receipts'),
+    ('credit_payment:create', 'Record installments'),
+    ('customer:view', 'View customers and their invoices'),
+    ('customer:create', 'Create customers'),
+    ('customer:update', 'Update customers'),
+    ('customer:delete', 'Delete customers'),
+    ('customer:import', 'Import customers'),
+    ('customer:export', 'Export customers'),
+    ('customer:ledger', 'View customer ledgers'),
+    ('company:view', 'View companies'),
+    ('company:manage', 'Create, update and delete companies'),
+    ('company:import', 'Import companies'),
+    ('meat:view', 'View meats and their lots'),
+    ('meat:manage', 'Create, update and delete meats'),
+    ('meat:import', 'Import meats'),
+    ('stock:view', 'View stock movements'),
+    ('stock:adjust', 'Adjust stock'),
+    ('stock_opname:view', 'View stock opnames and their variance'),
+    ('stock_opname:start', 'Start stock opnames'),
+    ('stock_opname:count', 'Enter stock opname counts'),
+    ('stock_opname:post', 'Post and cancel stock opnames'),
+    ('expenditure:view', 'View daily expenditures'),
+    ('expenditure:manage', 'Create, update and delete daily expenditures'),
+    ('expenditure:export', 'Export daily expenditures'),
+    ('report:view', 'View daily closing, aging and payables reports'),
+    ('report:financial', 'View margin and profit and loss reports'),
+    ('day:close', 'Close and reopen days'),
+    ('numbering_scheme:manage', 'View and change document numbering'),
+    ('user:view', 'View users'),
+    ('user:manage', 'Create, update and delete users'),
+    ('log:send', 'Send frontend logs'),
+    ('permission:manage', 'Manage the permissions of roles');
+INSERT INTO role_permissions (role, permission_code) VALUES
+    ('employee', 'price:suggest'),
+    ('employee', 'customer:view'),
+    ('employee', 'company:view'),
+    ('employee', 'meat:view'),
+    ('employee', 'stock_opname:count'),
+    ('employee', 'log:send'),
+    ('admin', 'transaction:view'),
+    ('admin', 'transaction:create'),
+    ('admin', 'transaction:void'),
+    ('admin', 'transaction:export'),
+    ('admin', 'price:suggest'),
+    ('admin', 'price_list:view'),
+    ('admin', 'credit_payment:view'),
+    ('admin', 'credit_payment:create'),
+    ('admin', 'customer:view'),
+    ('admin', 'customer:create'),
+    ('admin', 'customer:update'),
+    ('admin', 'customer:import'),
+    ('admin', 'customer:export'),
+    ('admin', 'customer:ledger'),
+    ('admin', 'company:view'),
+    ('admin', 'company:manage'),
+    ('admin', 'company:import'),
+    ('admin', 'meat:view'),
+    ('admin', 'meat:manage'),
+    ('admin', 'meat:import'),
+    ('admin', 'stock:view'),
+    ('admin', 'stock:adjust'),
+    ('admin', 'stock_opname:view'),
+    ('admin', 'stock_opname:start'),
+    ('admin', 'stock_opname:count'),
+    ('admin', 'expenditure:view'),
+    ('admin', 'expenditure:manage'),
+    ('admin', 'expenditure:export'),
+    ('admin', 'report:view'),
+    ('admin', 'log:send'),
+    ('owner', 'transaction:view'),
+    ('owner', 'transaction:create'),
+    ('owner', 'transaction:void'),
+    ('owner', 'transaction:export'),
+    ('owner', 'price:approve_deviation'),
+    ('owner', 'price:suggest'),
+    ('owner', 'price_list:view'),
+    ('owner', 'price_list:manage'),
+    ('owner', 'credit_payment:view'),
+    ('owner', 'credit_payment:create'),
+    ('owner', 'customer:view'),
+    ('owner', 'customer:create'),
+    ('owner', 'customer:update'),
+    ('owner', 'customer:delete'),
+    ('owner', 'customer:import'),
+    ('owner', 'customer:export'),
+    ('owner', 'customer:ledger'),
+    ('owner', 'company:view'),
+    ('owner', 'company:manage'),
+    ('owner', 'company:import'),
+    ('owner', 'meat:view'),
+    ('owner', 'meat:manage'),
+    ('owner', 'meat:import'),
+    ('owner', 'stock:view'),
+    ('owner', 'stock:adjust'),
+    ('owner', 'stock_opname:view'),
+    ('owner', 'stock_opname:start'),
+    ('owner', 'stock_opname:count'),
+    ('owner', 'stock_opname:post'),
+    ('owner', 'expenditure:view'),
+    ('owner', 'expenditure:manage'),
+    ('owner', 'expenditure:export'),
+    ('owner', 'report:view'),
+    ('owner', 'report:financial'),
+    ('owner', 'day:close'),
+    ('owner', 'numbering_scheme:manage'),
+    ('owner', 'user:view'),
+    ('owner', 'user:manage'),
+    ('owner', 'log:send'),
+    ('owner', 'permission:manage'),
+    ('developer', 'transaction:view'),
+    ('developer', 'transaction:create'),
+    ('developer', 'transaction:void'),
+    ('developer', 'transaction:export'),
+    ('developer', 'price:approve_deviation'),
+    ('developer', 'price:suggest'),
+    ('developer', 'price_list:view'),
+    ('developer', 'price_list:manage'),
+    ('developer', 'credit_payment:view'),
+    ('developer', 'credit_payment:create'),
+    ('developer', 'customer:view'),
+    ('developer', 'customer:create'),
+    ('developer', 'customer:update'),
+    ('developer', 'customer:delete'),
+    ('developer', 'customer:import'),
+    ('developer', 'customer:export'),
+    ('developer', 'customer:ledger'),
+    ('developer', 'company:view'),
+    ('developer', 'company:manage'),
+    ('developer', 'company:import'),
+    ('developer', 'meat:view'),
+    ('developer', 'meat:manage'),
+    ('developer', 'meat:import'),
+    ('developer', 'stock:view'),
+    ('developer', 'stock:adjust'),
+    ('developer', 'stock_opname:view'),
+    ('developer', 'stock_opname:start'),
+    ('developer', 'stock_opname:count'),
+    ('developer', 'stock_opname:post'),
+    ('developer', 'expenditure:view'),
+    ('developer', 'expenditure:manage'),
+    ('developer', 'expenditure:export'),
+    ('developer', 'report:view'),
+    ('developer', 'report:financial'),
+    ('developer', 'day:close'),
+    ('developer', 'numbering_scheme:manage'),
+    ('developer', 'user:view'),
+    ('developer', 'user:manage'),
+    ('developer', 'log:send'),
+    ('developer', 'permission:manage');
@@ -0,0 +1,3 @@
+ALTER TABLE users DROP COLUMN must_change_password;
+DROP TABLE login_throttles;
+DROP TABLE login_attempts;
@@ -0,0 +1,21 @@
+CREATE TABLE login_attempts (
+    id VARCHAR PRIMARY KEY,
+    username VARCHAR,
+    user_id VARCHAR,
+    ip_address VARCHAR,
+    user_agent VARCHAR,
+    success BOOLEAN NOT NULL,
+    reason VARCHAR NOT NULL,
+    created_at TIMESTAMP
+);
+CREATE INDEX idx_login_attempts_username ON login_attempts (username, created_at);
+CREATE TABLE login_throttles (
+    key VARCHAR PRIMARY KEY,
+    failed_count INTEGER NOT NULL DEFAULT 0,
+    last_failed_at TIMESTAMP,
+    locked_until TIMESTAMP
+);
+ALTER TABLE users ADD COLUMN must_change_password BOOLEAN NOT NULL DEFAULT FALSE;
+-- The seeded accounts share one password; whoever still uses it must pick a new one.
+UPDATE users SET must_change_password = TRUE
+WHERE password = '$2a$10$nH1zPLd.h1kjDN0wJbbhR.Gbqew.BUCoRpxhipb1hCTwjRPbGAMRS';
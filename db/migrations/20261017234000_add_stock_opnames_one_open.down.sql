@@ -0,0 +1 @@
+DROP INDEX stock_opnames_one_open;
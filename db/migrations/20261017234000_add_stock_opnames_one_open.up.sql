@@ -0,0 +1 @@
+CREATE UNIQUE INDEX stock_opnames_one_open ON stock_opnames (status) WHERE status = 'open';
@@ -0,0 +1,2 @@
+DROP INDEX customer_ledger_customer_id_tx_type_idx;
+ALTER TABLE customer_ledger DROP COLUMN tx_type;
@@ -0,0 +1,14 @@
+-- Receivables ("out" invoices) and payables ("in" invoices) of the same
+-- customer are kept apart so purchases do not reduce what they owe us.
+ALTER TABLE customer_ledger ADD COLUMN tx_type VARCHAR NOT NULL DEFAULT 'out';
+
+UPDATE customer_ledger cl SET tx_type = th.tx_type
+FROM transaction_headers th
+WHERE cl.source_type IN ('transaction', 'void') AND th.id = cl.source_id;
+
+UPDATE customer_ledger cl SET tx_type = th.tx_type
+FROM credit_payments cp
+JOIN transaction_headers th ON th.inv_number = cp.inv_number
+WHERE cl.source_type = 'credit_payment' AND cp.id = cl.source_id;
+
+CREATE INDEX customer_ledger_customer_id_tx_type_idx ON customer_ledger (customer_id, tx_type, entry_date);
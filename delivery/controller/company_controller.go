@@ -21,11 +21,12 @@ func NewCompanyController(r *gin.Engine, companyUseCase usecase.CompanyUseCase)
 	controller := &CompanyController{
 		companyUseCase: companyUseCase,
 	}
-	r.POST("/companies", middleware.JWTAuthMiddleware("admin","owner","developer"), controller.CreateCompany)
-	r.PUT("/companies/:id", middleware.JWTAuthMiddleware("admin","owner","developer"), controller.UpdateCompany)
-	r.GET("/companies/:id", middleware.JWTAuthMiddleware("employee","admin","owner","developer"), controller.GetCompanyById)
-	r.GET("/companies", middleware.JWTAuthMiddleware("employee","admin","owner","developer"), controller.GetAllCompany)
-	r.DELETE("/companies/:id", middleware.JWTAuthMiddleware("admin","owner","developer"), controller.DeleteCompany)
+	r.POST("/companies", middleware.RequirePermission(model.PermissionCompanyManage), controller.CreateCompany)
+	r.POST("/companies/import", middleware.RequirePermission(model.PermissionCompanyImport), controller.ImportCompanies)
+	r.PUT("/companies/:id", middleware.RequirePermission(model.PermissionCompanyManage), controller.UpdateCompany)
+	r.GET("/companies/:id", middleware.RequirePermission(model.PermissionCompanyView), controller.GetCompanyById)
+	r.GET("/companies", middleware.RequirePermission(model.PermissionCompanyView), controller.GetAllCompany)
+	r.DELETE("/companies/:id", middleware.RequirePermission(model.PermissionCompanyManage), controller.DeleteCompany)
 
 	return controller
 }
@@ -142,3 +143,32 @@ func (cc *CompanyController) DeleteCompany(c *gin.Context) {
 	logrus.Infof("[%v] Deleted company %v", username, companyId)
 	utils.SendResponse(c, http.StatusOK, "Success delete company", nil)
 }
+
+// ImportCompanies takes a CSV file, either as the multipart field "file" or as the
+// request body. With dry_run=true the rows are only validated.
+func (cc *CompanyController) ImportCompanies(c *gin.Context) {
+	username, err := utils.GetUsernameFromContext(c)
+	if err != nil {
+		logrus.Errorf("[%v]%v", username, err)
+		utils.SendResponse(c, http.StatusUnauthorized, "Invalid token", nil)
+		return
+	}
+	file, err := utils.GetUploadedFile(c)
+	if err != nil {
+		logrus.Errorf("[%v]%v", username, err)
+		utils.SendResponse(c, http.StatusBadRequest, "Invalid request payload", nil)
+		return
+	}
+	defer file.Close()
+	dryRun := c.Query("dry_run") == "true"
+	logrus.Infof("[%s] is importing companies, dry run %v", username, dryRun)
+
+	result, err := cc.companyUseCase.ImportCompanies(file, dryRun, username)
+	if err != nil {
+		logrus.Errorf("[%v]%v", username, err)
+		utils.HandleError(c, err)
+		return
+	}
+	logrus.Infof("[%s] companies import: %d rows, %d imported, %d errors", username, result.Rows, result.Imported, len(result.Errors))
+	utils.SendImportResult(c, result)
+}
@@ -19,8 +19,8 @@ func NewCreditPaymentController(r *gin.Engine, creditPaymentUseCase usecase.Cred
 	controller := &CreditPaymentController{
 		creditPaymentUseCase: creditPaymentUseCase,
 	}
-	r.POST("/credit_payment", middleware.JWTAuthMiddleware("admin", "owner", "developer"), controller.CreateCreditPayment)
-	r.GET("/credit_payments/:invoice_number", middleware.JWTAuthMiddleware("admin", "owner", "developer"), controller.GetCreditPaymentsByInvoiceNumber)
+	r.POST("/credit_payment", middleware.RequirePermission(model.PermissionCreditPaymentCreate), controller.CreateCreditPayment)
+	r.GET("/credit_payments/:invoice_number", middleware.RequirePermission(model.PermissionCreditPaymentView), controller.GetCreditPaymentsByInvoiceNumber)
 	return controller
 }
 
@@ -41,6 +41,7 @@ func (cc *CreditPaymentController) CreateCreditPayment(c *gin.Context) {
 		return
 	}
 	payment.CreatedBy = username
+	payment.IdempotencyKey = c.GetHeader("Idempotency-Key")
 	transaction, err := cc.creditPaymentUseCase.CreateCreditPayment(&payment)
 	if err != nil {
 		utils.HandleError(c, err)
@@ -1,6 +1,7 @@
 package controller
 
 import (
+	"io"
 	"net/http"
 	"strconv"
 	"time"
@@ -9,6 +10,7 @@ import (
 	model "trackprosto/models"
 
 	"trackprosto/usecase"
+	"trackprosto/utils/export"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
@@ -23,13 +25,16 @@ func NewCustomerController(r *gin.Engine, customerUsecase usecase.CustomerUsecas
 	controller := &CustomerController{
 		customerUsecase: customerUsecase,
 	}
-	r.POST("/customers", middleware.JWTAuthMiddleware("admin", "owner", "developer"), controller.CreateCustomer)
-	r.GET("/customers", middleware.JWTAuthMiddleware("employee", "admin", "owner", "developer"), controller.GetAllCustomer)
-	r.GET("/customers/:id", middleware.JWTAuthMiddleware("employee", "admin", "owner", "developer"), controller.GetCustomerByID)
-	r.PUT("/customers/:id", middleware.JWTAuthMiddleware("admin", "owner", "developer"), controller.UpdateCustomer)
-	r.DELETE("/customers/:id", middleware.JWTAuthMiddleware("owner", "developer"), controller.DeleteCustomer)
-	r.GET("customers/company/:company_id", middleware.JWTAuthMiddleware("employee", "admin", "owner", "developer"), controller.GetAllCustomerByCompanyId)
-	r.GET("/customers/transaction/:id", middleware.JWTAuthMiddleware("employee", "admin", "owner", "developer"), controller.GetAllTransactionsByCustomerId)
+	r.POST("/customers", middleware.RequirePermission(model.PermissionCustomerCreate), controller.CreateCustomer)
+	r.POST("/customers/import", middleware.RequirePermission(model.PermissionCustomerImport), controller.ImportCustomers)
+	r.GET("/customers", middleware.RequirePermission(model.PermissionCustomerView), controller.GetAllCustomer)
+	r.GET("/customers/export", middleware.RequirePermission(model.PermissionCustomerExport), controller.ExportCustomers)
+	r.GET("/customers/:id", middleware.RequirePermission(model.PermissionCustomerView), controller.GetCustomerByID)
+	r.PUT("/customers/:id", middleware.RequirePermission(model.PermissionCustomerUpdate), controller.UpdateCustomer)
+	r.DELETE("/customers/:id", middleware.RequirePermission(model.PermissionCustomerDelete), controller.DeleteCustomer)
+	r.GET("customers/company/:company_id", middleware.RequirePermission(model.PermissionCustomerView), controller.GetAllCustomerByCompanyId)
+	r.GET("/customers/transaction/:id", middleware.RequirePermission(model.PermissionCustomerView), controller.GetAllTransactionsByCustomerId)
+	r.GET("/customers/:id/ledger", middleware.RequirePermission(model.PermissionCustomerLedger), controller.GetCustomerLedger)
 	return controller
 }
 
@@ -242,3 +247,79 @@ func (cc *CustomerController) GetAllTransactionsByCustomerId(c *gin.Context) {
 	utils.SendResponse(c, http.StatusOK, "Success", map[string]interface{}{"transactions": customerTransactions, "pagination": paginationData})
 
 }
+
+func (cc *CustomerController) GetCustomerLedger(c *gin.Context) {
+	customerId := c.Param("id")
+	username, err := utils.GetUsernameFromContext(c)
+	if err != nil {
+		logrus.Errorf("[%v]%v", username, err)
+		utils.SendResponse(c, http.StatusUnauthorized, "Invalid token", nil)
+		return
+	}
+	logrus.Infof("[%s] get ledger of customer %s", username, customerId)
+
+	startDate, endDate, err := utils.GetDateRangeFromQuery(c)
+	if err != nil {
+		utils.SendResponse(c, http.StatusBadRequest, err.Error(), nil)
+		return
+	}
+
+	txType := c.DefaultQuery("tx_type", "out")
+	ledger, err := cc.customerUsecase.GetCustomerLedger(customerId, txType, startDate, endDate)
+	if err != nil {
+		logrus.Errorf("[%v]%v", username, err)
+		utils.HandleError(c, err)
+		return
+	}
+
+	logrus.Infof("[%s] got ledger of customer %s", username, customerId)
+	utils.SendResponse(c, http.StatusOK, "Success", ledger)
+}
+
+func (cc *CustomerController) ExportCustomers(c *gin.Context) {
+	username, err := utils.GetUsernameFromContext(c)
+	if err != nil {
+		logrus.Errorf("[%v]%v", username, err)
+		utils.SendResponse(c, http.StatusUnauthorized, "Invalid token", nil)
+		return
+	}
+	companyID := c.Query("company_id")
+	format := c.DefaultQuery("format", export.FormatCSV)
+	logrus.Infof("[%s] is exporting customers as %s", username, format)
+
+	err = utils.SendExport(c, "customers", format, func(w io.Writer) error {
+		return cc.customerUsecase.ExportCustomers(companyID, format, w)
+	})
+	if err != nil {
+		logrus.Errorf("[%v]%v", username, err)
+	}
+}
+
+// ImportCustomers takes a CSV file, either as the multipart field "file" or as the
+// request body. With dry_run=true the rows are only validated.
+func (cc *CustomerController) ImportCustomers(c *gin.Context) {
+	username, err := utils.GetUsernameFromContext(c)
+	if err != nil {
+		logrus.Errorf("[%v]%v", username, err)
+		utils.SendResponse(c, http.StatusUnauthorized, "Invalid token", nil)
+		return
+	}
+	file, err := utils.GetUploadedFile(c)
+	if err != nil {
+		logrus.Errorf("[%v]%v", username, err)
+		utils.SendResponse(c, http.StatusBadRequest, "Invalid request payload", nil)
+		return
+	}
+	defer file.Close()
+	dryRun := c.Query("dry_run") == "true"
+	logrus.Infof("[%s] is importing customers, dry run %v", username, dryRun)
+
+	result, err := cc.customerUsecase.ImportCustomers(file, dryRun, username)
+	if err != nil {
+		logrus.Errorf("[%v]%v", username, err)
+		utils.HandleError(c, err)
+		return
+	}
+	logrus.Infof("[%s] customers import: %d rows, %d imported, %d errors", username, result.Rows, result.Imported, len(result.Errors))
+	utils.SendImportResult(c, result)
+}
@@ -1,12 +1,14 @@
 package controller
 
 import (
+	"io"
 	"net/http"
 	"time"
 	"trackprosto/delivery/middleware"
 	"trackprosto/delivery/utils"
 	model "trackprosto/models"
 	"trackprosto/usecase"
+	"trackprosto/utils/export"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
@@ -22,11 +24,12 @@ func NewDailyExpenditureController(r *gin.Engine, deUC usecase.DailyExpenditureU
 		dailyExpenditureUseCase: deUC,
 	}
 
-	r.POST("/daily-expenditures", middleware.JWTAuthMiddleware("admin", "owner", "developer"), controller.CreateDailyExpenditure)
-	r.PUT("/daily-expenditures/:id", middleware.JWTAuthMiddleware("admin", "owner", "developer"), controller.UpdateDailyExpenditure)
-	r.GET("/daily-expenditures/:id", middleware.JWTAuthMiddleware("admin", "owner", "developer"), controller.GetDailyExpenditureByID)
-	r.GET("/daily-expenditures", middleware.JWTAuthMiddleware("admin", "owner", "developer"), controller.GetAllDailyExpenditures)
-	r.DELETE("/daily-expenditures/:id", middleware.JWTAuthMiddleware("admin", "owner", "developer"), controller.DeleteDailyExpenditure)
+	r.POST("/daily-expenditures", middleware.RequirePermission(model.PermissionExpenditureManage), controller.CreateDailyExpenditure)
+	r.PUT("/daily-expenditures/:id", middleware.RequirePermission(model.PermissionExpenditureManage), controller.UpdateDailyExpenditure)
+	r.GET("/daily-expenditures/export", middleware.RequirePermission(model.PermissionExpenditureExport), controller.ExportDailyExpenditures)
+	r.GET("/daily-expenditures/:id", middleware.RequirePermission(model.PermissionExpenditureView), controller.GetDailyExpenditureByID)
+	r.GET("/daily-expenditures", middleware.RequirePermission(model.PermissionExpenditureView), controller.GetAllDailyExpenditures)
+	r.DELETE("/daily-expenditures/:id", middleware.RequirePermission(model.PermissionExpenditureManage), controller.DeleteDailyExpenditure)
 
 	return controller
 }
@@ -189,3 +192,30 @@ func (dec *DailyExpenditureController) DeleteDailyExpenditure(c *gin.Context) {
 	logrus.Infof("[%s] succes delete daily expenditure [%s]", username, expenditureID)
 	utils.SendResponse(c, http.StatusOK, "Success", nil)
 }
+
+func (dc *DailyExpenditureController) ExportDailyExpenditures(c *gin.Context) {
+	username, err := utils.GetUsernameFromContext(c)
+	if err != nil {
+		logrus.Errorf("[%v]%v", username, err)
+		utils.SendResponse(c, http.StatusUnauthorized, "Invalid token", nil)
+		return
+	}
+	startDate, endDate, err := utils.GetDateRangeFromQuery(c)
+	if err != nil {
+		utils.SendResponse(c, http.StatusBadRequest, err.Error(), nil)
+		return
+	}
+	filter := &model.ExportFilter{
+		StartDate: startDate,
+		EndDate:   endDate,
+	}
+	format := c.DefaultQuery("format", export.FormatCSV)
+	logrus.Infof("[%s] is exporting daily expenditures from %s to %s as %s", username, startDate, endDate, format)
+
+	err = utils.SendExport(c, "daily_expenditures_"+startDate+"_"+endDate, format, func(w io.Writer) error {
+		return dc.dailyExpenditureUseCase.ExportDailyExpenditures(filter, format, w)
+	})
+	if err != nil {
+		logrus.Errorf("[%v]%v", username, err)
+	}
+}
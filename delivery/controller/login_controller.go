@@ -2,6 +2,7 @@ package controller
 
 import (
 	"net/http"
+	"strconv"
 	"trackprosto/delivery/middleware"
 	"trackprosto/delivery/utils"
 	model "trackprosto/models"
@@ -20,7 +21,12 @@ func NewLoginController(r *gin.Engine, loginUC usecase.LoginUseCase) {
 		loginUseCase: loginUC,
 	}
 	r.POST("/login", loginController.Login)
-	r.POST("/send-log", middleware.JWTAuthMiddleware("employee", "admin", "owner", "developer"), loginController.SendLog)
+	r.POST("/refresh", loginController.Refresh)
+	r.POST("/logout", loginController.Logout)
+	r.POST("/me/password", middleware.RequireAuth(), loginController.ChangePassword)
+	r.GET("/password-policy", loginController.GetPasswordPolicy)
+	r.GET("/login-attempts", middleware.RequirePermission(model.PermissionUserView), loginController.GetLoginAttempts)
+	r.POST("/send-log", middleware.RequirePermission(model.PermissionLogSend), loginController.SendLog)
 }
 
 func (uc *LoginController) Login(c *gin.Context) {
@@ -37,7 +43,7 @@ func (uc *LoginController) Login(c *gin.Context) {
 		return
 	}
 	logrus.Infof("[%s] is logging in", loginData.Username)
-	token, err := uc.loginUseCase.Login(loginData.Username, loginData.Password)
+	token, err := uc.loginUseCase.Login(loginData.Username, loginData.Password, c.ClientIP(), c.Request.UserAgent())
 	if err != nil {
 		logrus.Error(err)
 		utils.HandleError(c, err)
@@ -47,6 +53,114 @@ func (uc *LoginController) Login(c *gin.Context) {
 	utils.SendResponse(c, http.StatusOK, "Login success", token)
 }
 
+// Refresh issues a new access token for a refresh token. The refresh token is
+// replaced too; the client must keep the new one.
+func (uc *LoginController) Refresh(c *gin.Context) {
+	var request model.RefreshTokenRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		logrus.Error(err)
+		utils.SendResponse(c, http.StatusBadRequest, "Invalid request payload", nil)
+		return
+	}
+	tokens, err := uc.loginUseCase.Refresh(request.RefreshToken)
+	if err != nil {
+		logrus.Error(err)
+		utils.HandleError(c, err)
+		return
+	}
+	utils.SendResponse(c, http.StatusOK, "Token refreshed", tokens)
+}
+
+func (uc *LoginController) Logout(c *gin.Context) {
+	var request model.RefreshTokenRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		logrus.Error(err)
+		utils.SendResponse(c, http.StatusBadRequest, "Invalid request payload", nil)
+		return
+	}
+	if err := uc.loginUseCase.Logout(request.RefreshToken); err != nil {
+		logrus.Error(err)
+		utils.HandleError(c, err)
+		return
+	}
+	utils.SendResponse(c, http.StatusOK, "Logout success", nil)
+}
+
+// ChangePassword sets a new password for the logged in user and answers with
+// new tokens; the user's other sessions are logged out.
+func (uc *LoginController) ChangePassword(c *gin.Context) {
+	username, err := utils.GetUsernameFromContext(c)
+	if err != nil {
+		logrus.Errorf("[%v]%v", username, err)
+		utils.SendResponse(c, http.StatusUnauthorized, "Invalid token", nil)
+		return
+	}
+	userID, err := utils.GetUserIDFromContext(c)
+	if err != nil {
+		logrus.Errorf("[%v]%v", username, err)
+		utils.SendResponse(c, http.StatusUnauthorized, "Invalid token", nil)
+		return
+	}
+	var request model.ChangePasswordRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		logrus.Errorf("[%v]%v", username, err)
+		utils.SendResponse(c, http.StatusBadRequest, "Invalid request payload", nil)
+		return
+	}
+	logrus.Infof("[%s] is changing password", username)
+
+	tokens, err := uc.loginUseCase.ChangePassword(userID, &request)
+	if err != nil {
+		logrus.Errorf("[%v]%v", username, err)
+		utils.HandleError(c, err)
+		return
+	}
+	logrus.Infof("[%s] changed password", username)
+	utils.SendResponse(c, http.StatusOK, "Password changed", tokens)
+}
+
+// GetPasswordPolicy tells what a new password has to look like. It needs no
+// login so the password form can show it up front.
+func (uc *LoginController) GetPasswordPolicy(c *gin.Context) {
+	utils.SendResponse(c, http.StatusOK, "Success", uc.loginUseCase.GetPasswordPolicy())
+}
+
+// GetLoginAttempts lists the login audit, newest first, optionally of one
+// ?username=.
+func (uc *LoginController) GetLoginAttempts(c *gin.Context) {
+	username, err := utils.GetUsernameFromContext(c)
+	if err != nil {
+		logrus.Errorf("[%v]%v", username, err)
+		utils.SendResponse(c, http.StatusUnauthorized, "Invalid token", nil)
+		return
+	}
+	logrus.Infof("[%s] get login attempts", username)
+
+	page, err := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if err != nil || page <= 0 {
+		utils.SendResponse(c, http.StatusBadRequest, "Invalid page number", nil)
+		return
+	}
+	itemsPerPage, err := strconv.Atoi(c.DefaultQuery("itemsPerPage", "10"))
+	if err != nil || itemsPerPage <= 0 {
+		utils.SendResponse(c, http.StatusBadRequest, "Invalid itemsPerPage", nil)
+		return
+	}
+
+	attempts, totalPages, err := uc.loginUseCase.GetLoginAttempts(c.Query("username"), page, itemsPerPage)
+	if err != nil {
+		logrus.Errorf("[%v]%v", username, err)
+		utils.HandleError(c, err)
+		return
+	}
+	paginationData := map[string]interface{}{
+		"page":         page,
+		"itemsPerPage": itemsPerPage,
+		"totalPages":   totalPages,
+	}
+	utils.SendResponse(c, http.StatusOK, "Success", map[string]interface{}{"login_attempts": attempts, "pagination": paginationData})
+}
+
 func (uc *LoginController) SendLog(c *gin.Context) {
 	// Mendapatkan log dari body request
 	var logRequest struct {
@@ -22,11 +22,17 @@ func NewMeatController(r *gin.Engine, meatUC usecase.MeatUseCase) {
 		meatUseCase: meatUC,
 	}
 
-	r.POST("/meats", middleware.JWTAuthMiddleware("admin", "owner", "developer"), meatController.CreateMeat)
-	r.GET("/meats", middleware.JWTAuthMiddleware("employee", "admin", "owner", "developer"), meatController.GetAllMeats)
-	r.GET("/meats/:name", middleware.JWTAuthMiddleware("employee", "admin", "owner", "developer"), meatController.GetMeatByName)
-	r.DELETE("/meats/:id", middleware.JWTAuthMiddleware("admin", "owner", "developer"), meatController.DeleteMeat)
-	r.PUT("/meats/:id", middleware.JWTAuthMiddleware("admin", "owner", "developer"), meatController.UpdateMeat)
+	r.POST("/meats", middleware.RequirePermission(model.PermissionMeatManage), meatController.CreateMeat)
+	r.POST("/meats/import", middleware.RequirePermission(model.PermissionMeatImport), meatController.ImportMeats)
+	r.GET("/meats", middleware.RequirePermission(model.PermissionMeatView), meatController.GetAllMeats)
+	r.GET("/meats/:name", middleware.RequirePermission(model.PermissionMeatView), meatController.GetMeatByName)
+	r.DELETE("/meats/:id", middleware.RequirePermission(model.PermissionMeatManage), meatController.DeleteMeat)
+	r.PUT("/meats/:id", middleware.RequirePermission(model.PermissionMeatManage), meatController.UpdateMeat)
+	r.POST("/meats/:id/adjustments", middleware.RequirePermission(model.PermissionStockAdjust), meatController.AdjustStock)
+	// gin needs the same wildcard name as GET /meats/:name, the value is the meat id.
+	r.GET("/meats/:name/movements", middleware.RequirePermission(model.PermissionStockView), meatController.GetStockMovements)
+	r.GET("/meats/:name/lots", middleware.RequirePermission(model.PermissionMeatView), meatController.GetMeatLots)
+	r.GET("/lots/expiring", middleware.RequirePermission(model.PermissionMeatView), meatController.GetExpiringLots)
 }
 
 func (mc *MeatController) CreateMeat(ctx *gin.Context) {
@@ -180,3 +186,125 @@ func (uc *MeatController) UpdateMeat(ctx *gin.Context) {
 	logrus.Infof("[%s] Meat updated successfully %v", userName, meat)
 	utils.SendResponse(ctx, http.StatusOK, "Success", meat)
 }
+
+func (mc *MeatController) GetStockMovements(c *gin.Context) {
+	meatID := c.Param("name")
+	username, err := utils.GetUsernameFromContext(c)
+	if err != nil {
+		logrus.Errorf("[%v]%v", username, err)
+		utils.SendResponse(c, http.StatusUnauthorized, "Invalid token", nil)
+		return
+	}
+	startDate, endDate, err := utils.GetDateRangeFromQuery(c)
+	if err != nil {
+		utils.SendResponse(c, http.StatusBadRequest, err.Error(), nil)
+		return
+	}
+	logrus.Infof("[%s] get stock movements of meat %s from %s to %s", username, meatID, startDate, endDate)
+
+	movements, err := mc.meatUseCase.GetStockMovements(meatID, startDate, endDate)
+	if err != nil {
+		logrus.Errorf("[%v]%v", username, err)
+		utils.HandleError(c, err)
+		return
+	}
+	utils.SendResponse(c, http.StatusOK, "Success", movements)
+}
+
+func (mc *MeatController) AdjustStock(c *gin.Context) {
+	meatID := c.Param("id")
+	username, err := utils.GetUsernameFromContext(c)
+	if err != nil {
+		logrus.Errorf("[%v]%v", username, err)
+		utils.SendResponse(c, http.StatusUnauthorized, "Invalid token", nil)
+		return
+	}
+	var request model.StockAdjustmentRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		logrus.Errorf("[%v]%v", username, err)
+		utils.SendResponse(c, http.StatusBadRequest, "Invalid request payload", nil)
+		return
+	}
+	logrus.Infof("[%s] is adjusting stock of meat %s by %v (%s)", username, meatID, request.Qty, request.ReasonCode)
+
+	movement, err := mc.meatUseCase.AdjustStock(meatID, &request, username)
+	if err != nil {
+		logrus.Errorf("[%v]%v", username, err)
+		utils.HandleError(c, err)
+		return
+	}
+	logrus.Infof("[%s] Stock of meat %s adjusted, stock after = %v", username, meatID, movement.StockAfter)
+	utils.SendResponse(c, http.StatusOK, "Success", movement)
+}
+
+func (mc *MeatController) GetMeatLots(c *gin.Context) {
+	meatID := c.Param("name")
+	username, err := utils.GetUsernameFromContext(c)
+	if err != nil {
+		logrus.Errorf("[%v]%v", username, err)
+		utils.SendResponse(c, http.StatusUnauthorized, "Invalid token", nil)
+		return
+	}
+	includeEmpty := c.Query("include_empty") == "true"
+	logrus.Infof("[%s] get lots of meat %s", username, meatID)
+
+	lots, err := mc.meatUseCase.GetMeatLots(meatID, includeEmpty)
+	if err != nil {
+		logrus.Errorf("[%v]%v", username, err)
+		utils.HandleError(c, err)
+		return
+	}
+	utils.SendResponse(c, http.StatusOK, "Success", lots)
+}
+
+func (mc *MeatController) GetExpiringLots(c *gin.Context) {
+	username, err := utils.GetUsernameFromContext(c)
+	if err != nil {
+		logrus.Errorf("[%v]%v", username, err)
+		utils.SendResponse(c, http.StatusUnauthorized, "Invalid token", nil)
+		return
+	}
+	days, err := strconv.Atoi(c.DefaultQuery("days", "3"))
+	if err != nil || days < 0 {
+		utils.SendResponse(c, http.StatusBadRequest, "Invalid days parameter", nil)
+		return
+	}
+	logrus.Infof("[%s] get lots expiring within %d days", username, days)
+
+	lots, err := mc.meatUseCase.GetExpiringLots(days)
+	if err != nil {
+		logrus.Errorf("[%v]%v", username, err)
+		utils.HandleError(c, err)
+		return
+	}
+	utils.SendResponse(c, http.StatusOK, "Success", lots)
+}
+
+// ImportMeats takes a CSV file, either as the multipart field "file" or as the
+// request body. With dry_run=true the rows are only validated.
+func (mc *MeatController) ImportMeats(c *gin.Context) {
+	username, err := utils.GetUsernameFromContext(c)
+	if err != nil {
+		logrus.Errorf("[%v]%v", username, err)
+		utils.SendResponse(c, http.StatusUnauthorized, "Invalid token", nil)
+		return
+	}
+	file, err := utils.GetUploadedFile(c)
+	if err != nil {
+		logrus.Errorf("[%v]%v", username, err)
+		utils.SendResponse(c, http.StatusBadRequest, "Invalid request payload", nil)
+		return
+	}
+	defer file.Close()
+	dryRun := c.Query("dry_run") == "true"
+	logrus.Infof("[%s] is importing meats, dry run %v", username, dryRun)
+
+	result, err := mc.meatUseCase.ImportMeats(file, dryRun, username)
+	if err != nil {
+		logrus.Errorf("[%v]%v", username, err)
+		utils.HandleError(c, err)
+		return
+	}
+	logrus.Infof("[%s] meats import: %d rows, %d imported, %d errors", username, result.Rows, result.Imported, len(result.Errors))
+	utils.SendImportResult(c, result)
+}
@@ -0,0 +1,70 @@
+package controller
+
+import (
+	"net/http"
+	"trackprosto/delivery/middleware"
+	"trackprosto/delivery/utils"
+	model "trackprosto/models"
+	"trackprosto/usecase"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+type NumberingSchemeController struct {
+	numberingSchemeUseCase usecase.NumberingSchemeUseCase
+}
+
+func NewNumberingSchemeController(r *gin.Engine, numberingSchemeUseCase usecase.NumberingSchemeUseCase) *NumberingSchemeController {
+	controller := &NumberingSchemeController{
+		numberingSchemeUseCase: numberingSchemeUseCase,
+	}
+
+	r.GET("/numbering-schemes", middleware.RequirePermission(model.PermissionNumberingSchemeManage), controller.GetAllNumberingSchemes)
+	r.PUT("/numbering-schemes/:document_type", middleware.RequirePermission(model.PermissionNumberingSchemeManage), controller.UpdateNumberingScheme)
+
+	return controller
+}
+
+func (nc *NumberingSchemeController) GetAllNumberingSchemes(c *gin.Context) {
+	username, err := utils.GetUsernameFromContext(c)
+	if err != nil {
+		logrus.Errorf("[%v]%v", username, err)
+		utils.SendResponse(c, http.StatusUnauthorized, "Invalid token", nil)
+		return
+	}
+	logrus.Infof("[%s] get numbering schemes", username)
+
+	schemes, err := nc.numberingSchemeUseCase.GetAllNumberingSchemes()
+	if err != nil {
+		logrus.Errorf("[%v]%v", username, err)
+		utils.HandleError(c, err)
+		return
+	}
+	utils.SendResponse(c, http.StatusOK, "Success", schemes)
+}
+
+func (nc *NumberingSchemeController) UpdateNumberingScheme(c *gin.Context) {
+	username, err := utils.GetUsernameFromContext(c)
+	if err != nil {
+		logrus.Errorf("[%v]%v", username, err)
+		utils.SendResponse(c, http.StatusUnauthorized, "Invalid token", nil)
+		return
+	}
+	documentType := c.Param("document_type")
+	var request model.NumberingSchemeRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		logrus.Errorf("[%v]%v", username, err)
+		utils.SendResponse(c, http.StatusBadRequest, "Invalid request payload", nil)
+		return
+	}
+	logrus.Infof("[%s] is updating numbering scheme %s to %s", username, documentType, request.Template)
+
+	scheme, err := nc.numberingSchemeUseCase.UpdateNumberingScheme(documentType, &request, username)
+	if err != nil {
+		logrus.Errorf("[%v]%v", username, err)
+		utils.HandleError(c, err)
+		return
+	}
+	utils.SendResponse(c, http.StatusOK, "Numbering scheme updated", scheme)
+}
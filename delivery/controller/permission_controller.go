@@ -0,0 +1,112 @@
+package controller
+
+import (
+	"net/http"
+	"trackprosto/delivery/middleware"
+	"trackprosto/delivery/utils"
+	model "trackprosto/models"
+	"trackprosto/usecase"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+type PermissionController struct {
+	permissionUseCase usecase.PermissionUseCase
+}
+
+func NewPermissionController(r *gin.Engine, permissionUseCase usecase.PermissionUseCase) *PermissionController {
+	controller := &PermissionController{
+		permissionUseCase: permissionUseCase,
+	}
+
+	r.GET("/permissions", middleware.RequirePermission(model.PermissionPermissionManage), controller.GetAllPermissions)
+	r.GET("/roles", middleware.RequirePermission(model.PermissionPermissionManage), controller.GetAllRolePermissions)
+	r.GET("/roles/:role/permissions", middleware.RequirePermission(model.PermissionPermissionManage), controller.GetRolePermissions)
+	r.PUT("/roles/:role/permissions", middleware.RequirePermission(model.PermissionPermissionManage), controller.UpdateRolePermissions)
+
+	return controller
+}
+
+func (pc *PermissionController) GetAllPermissions(c *gin.Context) {
+	username, err := utils.GetUsernameFromContext(c)
+	if err != nil {
+		logrus.Errorf("[%v]%v", username, err)
+		utils.SendResponse(c, http.StatusUnauthorized, "Invalid token", nil)
+		return
+	}
+	logrus.Infof("[%s] get permissions", username)
+
+	permissions, err := pc.permissionUseCase.GetAllPermissions()
+	if err != nil {
+		logrus.Errorf("[%v]%v", username, err)
+		utils.HandleError(c, err)
+		return
+	}
+	utils.SendResponse(c, http.StatusOK, "Success", permissions)
+}
+
+func (pc *PermissionController) GetAllRolePermissions(c *gin.Context) {
+	username, err := utils.GetUsernameFromContext(c)
+	if err != nil {
+		logrus.Errorf("[%v]%v", username, err)
+		utils.SendResponse(c, http.StatusUnauthorized, "Invalid token", nil)
+		return
+	}
+	logrus.Infof("[%s] get role permissions", username)
+
+	roles, err := pc.permissionUseCase.GetAllRolePermissions()
+	if err != nil {
+		logrus.Errorf("[%v]%v", username, err)
+		utils.HandleError(c, err)
+		return
+	}
+	utils.SendResponse(c, http.StatusOK, "Success", roles)
+}
+
+func (pc *PermissionController) GetRolePermissions(c *gin.Context) {
+	username, err := utils.GetUsernameFromContext(c)
+	if err != nil {
+		logrus.Errorf("[%v]%v", username, err)
+		utils.SendResponse(c, http.StatusUnauthorized, "Invalid token", nil)
+		return
+	}
+	role := c.Param("role")
+	logrus.Infof("[%s] get permissions of role %s", username, role)
+
+	permissions, err := pc.permissionUseCase.GetRolePermissions(role)
+	if err != nil {
+		logrus.Errorf("[%v]%v", username, err)
+		utils.HandleError(c, err)
+		return
+	}
+	utils.SendResponse(c, http.StatusOK, "Success", permissions)
+}
+
+// UpdateRolePermissions replaces the permissions of a role with the ones in
+// the request.
+func (pc *PermissionController) UpdateRolePermissions(c *gin.Context) {
+	username, callerRole, err := utils.GetUserDetailsFromContext(c)
+	if err != nil {
+		logrus.Errorf("[%v]%v", username, err)
+		utils.SendResponse(c, http.StatusUnauthorized, "Invalid token", nil)
+		return
+	}
+	role := c.Param("role")
+	var request model.RolePermissionsRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		logrus.Errorf("[%v]%v", username, err)
+		utils.SendResponse(c, http.StatusBadRequest, "Invalid request payload", nil)
+		return
+	}
+	logrus.Infof("[%s] is updating permissions of role %s", username, role)
+
+	permissions, err := pc.permissionUseCase.UpdateRolePermissions(role, &request, callerRole)
+	if err != nil {
+		logrus.Errorf("[%v]%v", username, err)
+		utils.HandleError(c, err)
+		return
+	}
+	logrus.Infof("[%s] set permissions of role %s to %v", username, role, permissions.Permissions)
+	utils.SendResponse(c, http.StatusOK, "Role permissions updated", permissions)
+}
@@ -0,0 +1,158 @@
+package controller
+
+import (
+	"net/http"
+	"trackprosto/delivery/middleware"
+	"trackprosto/delivery/utils"
+	model "trackprosto/models"
+	"trackprosto/usecase"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+type PriceListController struct {
+	priceListUseCase usecase.PriceListUseCase
+}
+
+func NewPriceListController(r *gin.Engine, priceListUseCase usecase.PriceListUseCase) *PriceListController {
+	controller := &PriceListController{
+		priceListUseCase: priceListUseCase,
+	}
+
+	r.POST("/price-lists", middleware.RequirePermission(model.PermissionPriceListManage), controller.CreatePriceList)
+	r.GET("/price-lists", middleware.RequirePermission(model.PermissionPriceListView), controller.GetAllPriceLists)
+	r.GET("/price-lists/:id", middleware.RequirePermission(model.PermissionPriceListView), controller.GetPriceListByID)
+	r.PUT("/price-lists/:id", middleware.RequirePermission(model.PermissionPriceListManage), controller.UpdatePriceList)
+	r.DELETE("/price-lists/:id", middleware.RequirePermission(model.PermissionPriceListManage), controller.DeletePriceList)
+	r.GET("/prices/suggest", middleware.RequirePermission(model.PermissionPriceSuggest), controller.SuggestPrice)
+
+	return controller
+}
+
+func (pc *PriceListController) CreatePriceList(c *gin.Context) {
+	username, err := utils.GetUsernameFromContext(c)
+	if err != nil {
+		logrus.Errorf("[%v]%v", username, err)
+		utils.SendResponse(c, http.StatusUnauthorized, "Invalid token", nil)
+		return
+	}
+	var request model.PriceListRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		logrus.Errorf("[%v]%v", username, err)
+		utils.SendResponse(c, http.StatusBadRequest, "Invalid request payload", nil)
+		return
+	}
+	logrus.Infof("[%s] is creating price list %s", username, request.Name)
+
+	priceList, err := pc.priceListUseCase.CreatePriceList(&request, username)
+	if err != nil {
+		logrus.Errorf("[%v]%v", username, err)
+		utils.HandleError(c, err)
+		return
+	}
+	utils.SendResponse(c, http.StatusCreated, "Price list created", priceList)
+}
+
+// GetAllPriceLists lists the active price lists; with active_on=YYYY-MM-DD
+// only those valid on that day.
+func (pc *PriceListController) GetAllPriceLists(c *gin.Context) {
+	username, err := utils.GetUsernameFromContext(c)
+	if err != nil {
+		logrus.Errorf("[%v]%v", username, err)
+		utils.SendResponse(c, http.StatusUnauthorized, "Invalid token", nil)
+		return
+	}
+	logrus.Infof("[%s] get price lists", username)
+
+	priceLists, err := pc.priceListUseCase.GetAllPriceLists(c.Query("active_on"))
+	if err != nil {
+		logrus.Errorf("[%v]%v", username, err)
+		utils.HandleError(c, err)
+		return
+	}
+	utils.SendResponse(c, http.StatusOK, "Success", priceLists)
+}
+
+func (pc *PriceListController) GetPriceListByID(c *gin.Context) {
+	username, err := utils.GetUsernameFromContext(c)
+	if err != nil {
+		logrus.Errorf("[%v]%v", username, err)
+		utils.SendResponse(c, http.StatusUnauthorized, "Invalid token", nil)
+		return
+	}
+	id := c.Param("id")
+	logrus.Infof("[%s] get price list %s", username, id)
+
+	priceList, err := pc.priceListUseCase.GetPriceListByID(id)
+	if err != nil {
+		logrus.Errorf("[%v]%v", username, err)
+		utils.HandleError(c, err)
+		return
+	}
+	utils.SendResponse(c, http.StatusOK, "Success", priceList)
+}
+
+func (pc *PriceListController) UpdatePriceList(c *gin.Context) {
+	username, err := utils.GetUsernameFromContext(c)
+	if err != nil {
+		logrus.Errorf("[%v]%v", username, err)
+		utils.SendResponse(c, http.StatusUnauthorized, "Invalid token", nil)
+		return
+	}
+	id := c.Param("id")
+	var request model.PriceListRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		logrus.Errorf("[%v]%v", username, err)
+		utils.SendResponse(c, http.StatusBadRequest, "Invalid request payload", nil)
+		return
+	}
+	logrus.Infof("[%s] is updating price list %s", username, id)
+
+	priceList, err := pc.priceListUseCase.UpdatePriceList(id, &request, username)
+	if err != nil {
+		logrus.Errorf("[%v]%v", username, err)
+		utils.HandleError(c, err)
+		return
+	}
+	utils.SendResponse(c, http.StatusOK, "Price list updated", priceList)
+}
+
+func (pc *PriceListController) DeletePriceList(c *gin.Context) {
+	username, err := utils.GetUsernameFromContext(c)
+	if err != nil {
+		logrus.Errorf("[%v]%v", username, err)
+		utils.SendResponse(c, http.StatusUnauthorized, "Invalid token", nil)
+		return
+	}
+	id := c.Param("id")
+	logrus.Infof("[%s] is deleting price list %s", username, id)
+
+	if err := pc.priceListUseCase.DeletePriceList(id, username); err != nil {
+		logrus.Errorf("[%v]%v", username, err)
+		utils.HandleError(c, err)
+		return
+	}
+	utils.SendResponse(c, http.StatusOK, "Price list deleted", nil)
+}
+
+// SuggestPrice returns the price a sale line would get for
+// ?customer_id=&meat_id= when it is sent without a price.
+func (pc *PriceListController) SuggestPrice(c *gin.Context) {
+	username, err := utils.GetUsernameFromContext(c)
+	if err != nil {
+		logrus.Errorf("[%v]%v", username, err)
+		utils.SendResponse(c, http.StatusUnauthorized, "Invalid token", nil)
+		return
+	}
+	customerID, meatID := c.Query("customer_id"), c.Query("meat_id")
+	logrus.Infof("[%s] get suggested price of meat %s for customer %s", username, meatID, customerID)
+
+	suggestion, err := pc.priceListUseCase.SuggestPrice(customerID, meatID)
+	if err != nil {
+		logrus.Errorf("[%v]%v", username, err)
+		utils.HandleError(c, err)
+		return
+	}
+	utils.SendResponse(c, http.StatusOK, "Success", suggestion)
+}
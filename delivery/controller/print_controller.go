@@ -0,0 +1,122 @@
+package controller
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"trackprosto/delivery/middleware"
+	"trackprosto/delivery/utils"
+	model "trackprosto/models"
+	"trackprosto/usecase"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+type PrintController struct {
+	printUseCase usecase.PrintUseCase
+}
+
+func NewPrintController(r *gin.Engine, printUseCase usecase.PrintUseCase) *PrintController {
+	controller := &PrintController{
+		printUseCase: printUseCase,
+	}
+
+	r.GET("/transactions/:invoice_number/pdf", middleware.RequirePermission(model.PermissionTransactionView), controller.GetInvoicePDF)
+	r.GET("/transactions/:invoice_number/escpos", middleware.RequirePermission(model.PermissionTransactionView), controller.GetInvoiceESCPOS)
+	// gin needs the wildcard to keep the name of /credit_payments/:invoice_number; it holds the payment id.
+	r.GET("/credit_payments/:invoice_number/receipt.pdf", middleware.RequirePermission(model.PermissionCreditPaymentView), controller.GetCreditPaymentReceiptPDF)
+	r.GET("/credit_payments/:invoice_number/escpos", middleware.RequirePermission(model.PermissionCreditPaymentView), controller.GetCreditPaymentReceiptESCPOS)
+
+	return controller
+}
+
+func (pc *PrintController) GetInvoicePDF(c *gin.Context) {
+	username, err := utils.GetUsernameFromContext(c)
+	if err != nil {
+		logrus.Errorf("[%v]%v", username, err)
+		utils.SendResponse(c, http.StatusUnauthorized, "Invalid token", nil)
+		return
+	}
+	invoiceNumber := c.Param("invoice_number")
+	logrus.Infof("[%s] is printing invoice %s", username, invoiceNumber)
+
+	document, err := pc.printUseCase.GetInvoicePDF(invoiceNumber)
+	if err != nil {
+		logrus.Errorf("[%v]%v", username, err)
+		utils.HandleError(c, err)
+		return
+	}
+	sendPDF(c, invoiceNumber+".pdf", document)
+}
+
+func (pc *PrintController) GetCreditPaymentReceiptPDF(c *gin.Context) {
+	username, err := utils.GetUsernameFromContext(c)
+	if err != nil {
+		logrus.Errorf("[%v]%v", username, err)
+		utils.SendResponse(c, http.StatusUnauthorized, "Invalid token", nil)
+		return
+	}
+	id := c.Param("invoice_number")
+	logrus.Infof("[%s] is printing the receipt of credit payment %s", username, id)
+
+	document, err := pc.printUseCase.GetCreditPaymentReceiptPDF(id)
+	if err != nil {
+		logrus.Errorf("[%v]%v", username, err)
+		utils.HandleError(c, err)
+		return
+	}
+	sendPDF(c, "receipt-"+id+".pdf", document)
+}
+
+// GetInvoiceESCPOS returns the raw bytes to send to a thermal printer. The
+// width query parameter is the paper roll in millimetres, 58 or 80.
+func (pc *PrintController) GetInvoiceESCPOS(c *gin.Context) {
+	username, err := utils.GetUsernameFromContext(c)
+	if err != nil {
+		logrus.Errorf("[%v]%v", username, err)
+		utils.SendResponse(c, http.StatusUnauthorized, "Invalid token", nil)
+		return
+	}
+	invoiceNumber := c.Param("invoice_number")
+	logrus.Infof("[%s] is printing invoice %s on a thermal printer", username, invoiceNumber)
+
+	paperWidth, _ := strconv.Atoi(c.DefaultQuery("width", "58"))
+	receipt, err := pc.printUseCase.GetInvoiceESCPOS(invoiceNumber, paperWidth)
+	if err != nil {
+		logrus.Errorf("[%v]%v", username, err)
+		utils.HandleError(c, err)
+		return
+	}
+	sendESCPOS(c, invoiceNumber+".bin", receipt)
+}
+
+func (pc *PrintController) GetCreditPaymentReceiptESCPOS(c *gin.Context) {
+	username, err := utils.GetUsernameFromContext(c)
+	if err != nil {
+		logrus.Errorf("[%v]%v", username, err)
+		utils.SendResponse(c, http.StatusUnauthorized, "Invalid token", nil)
+		return
+	}
+	id := c.Param("invoice_number")
+	logrus.Infof("[%s] is printing the receipt of credit payment %s on a thermal printer", username, id)
+
+	paperWidth, _ := strconv.Atoi(c.DefaultQuery("width", "58"))
+	receipt, err := pc.printUseCase.GetCreditPaymentReceiptESCPOS(id, paperWidth)
+	if err != nil {
+		logrus.Errorf("[%v]%v", username, err)
+		utils.HandleError(c, err)
+		return
+	}
+	sendESCPOS(c, "receipt-"+id+".bin", receipt)
+}
+
+func sendPDF(c *gin.Context, filename string, document []byte) {
+	c.Header("Content-Disposition", fmt.Sprintf("inline; filename=%q", filename))
+	c.Data(http.StatusOK, "application/pdf", document)
+}
+
+func sendESCPOS(c *gin.Context, filename string, receipt []byte) {
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	c.Data(http.StatusOK, "application/octet-stream", receipt)
+}
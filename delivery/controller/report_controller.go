@@ -0,0 +1,202 @@
+package controller
+
+import (
+	"net/http"
+	"time"
+	"trackprosto/delivery/middleware"
+	"trackprosto/delivery/utils"
+	model "trackprosto/models"
+	"trackprosto/usecase"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+type ReportController struct {
+	reportUseCase usecase.ReportUseCase
+}
+
+func NewReportController(r *gin.Engine, reportUseCase usecase.ReportUseCase) *ReportController {
+	controller := &ReportController{
+		reportUseCase: reportUseCase,
+	}
+
+	r.GET("/reports/margin", middleware.RequirePermission(model.PermissionReportFinancial), controller.GetMarginReport)
+	r.GET("/reports/profit-loss", middleware.RequirePermission(model.PermissionReportFinancial), controller.GetProfitLossReport)
+	r.GET("/reports/daily-closing", middleware.RequirePermission(model.PermissionReportView), controller.GetDailyClosing)
+	r.POST("/reports/daily-closing", middleware.RequirePermission(model.PermissionDayClose), controller.CloseDay)
+	r.DELETE("/reports/daily-closing/:date", middleware.RequirePermission(model.PermissionDayClose), controller.ReopenDay)
+	r.GET("/reports/receivables-aging", middleware.RequirePermission(model.PermissionReportView), controller.GetReceivablesAging)
+	r.GET("/reports/payables-aging", middleware.RequirePermission(model.PermissionReportView), controller.GetPayablesAging)
+	r.GET("/payables", middleware.RequirePermission(model.PermissionReportView), controller.GetPayables)
+
+	return controller
+}
+
+func (rc *ReportController) GetMarginReport(c *gin.Context) {
+	username, err := utils.GetUsernameFromContext(c)
+	if err != nil {
+		logrus.Errorf("[%v]%v", username, err)
+		utils.SendResponse(c, http.StatusUnauthorized, "Invalid token", nil)
+		return
+	}
+	startDate, endDate, err := utils.GetDateRangeFromQuery(c)
+	if err != nil {
+		utils.SendResponse(c, http.StatusBadRequest, err.Error(), nil)
+		return
+	}
+	groupBy := c.DefaultQuery("group_by", model.MarginByInvoice)
+	logrus.Infof("[%s] get margin report per %s from %s to %s", username, groupBy, startDate, endDate)
+
+	report, err := rc.reportUseCase.GetMarginReport(groupBy, startDate, endDate)
+	if err != nil {
+		logrus.Errorf("[%v]%v", username, err)
+		utils.HandleError(c, err)
+		return
+	}
+	utils.SendResponse(c, http.StatusOK, "Success", report)
+}
+
+func (rc *ReportController) GetProfitLossReport(c *gin.Context) {
+	username, err := utils.GetUsernameFromContext(c)
+	if err != nil {
+		logrus.Errorf("[%v]%v", username, err)
+		utils.SendResponse(c, http.StatusUnauthorized, "Invalid token", nil)
+		return
+	}
+	startDate, endDate, err := utils.GetDateRangeFromQueryKeys(c, "start", "end")
+	if err != nil {
+		utils.SendResponse(c, http.StatusBadRequest, err.Error(), nil)
+		return
+	}
+	granularity := c.DefaultQuery("granularity", model.GranularityDay)
+	logrus.Infof("[%s] get profit and loss per %s from %s to %s", username, granularity, startDate, endDate)
+
+	report, err := rc.reportUseCase.GetProfitLossReport(granularity, startDate, endDate)
+	if err != nil {
+		logrus.Errorf("[%v]%v", username, err)
+		utils.HandleError(c, err)
+		return
+	}
+	utils.SendResponse(c, http.StatusOK, "Success", report)
+}
+
+func (rc *ReportController) GetDailyClosing(c *gin.Context) {
+	username, err := utils.GetUsernameFromContext(c)
+	if err != nil {
+		logrus.Errorf("[%v]%v", username, err)
+		utils.SendResponse(c, http.StatusUnauthorized, "Invalid token", nil)
+		return
+	}
+	date := c.DefaultQuery("date", time.Now().Format("2006-01-02"))
+	logrus.Infof("[%s] get daily closing of %s", username, date)
+
+	report, err := rc.reportUseCase.GetDailyClosing(date)
+	if err != nil {
+		logrus.Errorf("[%v]%v", username, err)
+		utils.HandleError(c, err)
+		return
+	}
+	utils.SendResponse(c, http.StatusOK, "Success", report)
+}
+
+func (rc *ReportController) CloseDay(c *gin.Context) {
+	username, err := utils.GetUsernameFromContext(c)
+	if err != nil {
+		logrus.Errorf("[%v]%v", username, err)
+		utils.SendResponse(c, http.StatusUnauthorized, "Invalid token", nil)
+		return
+	}
+	var request model.DayClosingRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		logrus.Errorf("[%v]%v", username, err)
+		utils.SendResponse(c, http.StatusBadRequest, "Invalid request payload", nil)
+		return
+	}
+	logrus.Infof("[%s] is closing day %s", username, request.Date)
+
+	report, err := rc.reportUseCase.CloseDay(&request, username)
+	if err != nil {
+		logrus.Errorf("[%v]%v", username, err)
+		utils.HandleError(c, err)
+		return
+	}
+	logrus.Infof("[%s] Day %s closed, closing balance = %v", username, request.Date, report.ClosingBalance)
+	utils.SendResponse(c, http.StatusOK, "Day closed", report)
+}
+
+func (rc *ReportController) ReopenDay(c *gin.Context) {
+	username, err := utils.GetUsernameFromContext(c)
+	if err != nil {
+		logrus.Errorf("[%v]%v", username, err)
+		utils.SendResponse(c, http.StatusUnauthorized, "Invalid token", nil)
+		return
+	}
+	date := c.Param("date")
+	logrus.Infof("[%s] is reopening day %s", username, date)
+
+	if err := rc.reportUseCase.ReopenDay(date); err != nil {
+		logrus.Errorf("[%v]%v", username, err)
+		utils.HandleError(c, err)
+		return
+	}
+	utils.SendResponse(c, http.StatusOK, "Day reopened", nil)
+}
+
+func (rc *ReportController) GetReceivablesAging(c *gin.Context) {
+	username, err := utils.GetUsernameFromContext(c)
+	if err != nil {
+		logrus.Errorf("[%v]%v", username, err)
+		utils.SendResponse(c, http.StatusUnauthorized, "Invalid token", nil)
+		return
+	}
+	asOf := c.DefaultQuery("as_of", time.Now().Format("2006-01-02"))
+	logrus.Infof("[%s] get receivables aging as of %s", username, asOf)
+
+	report, err := rc.reportUseCase.GetReceivablesAging(asOf)
+	if err != nil {
+		logrus.Errorf("[%v]%v", username, err)
+		utils.HandleError(c, err)
+		return
+	}
+	utils.SendResponse(c, http.StatusOK, "Success", report)
+}
+
+func (rc *ReportController) GetPayablesAging(c *gin.Context) {
+	username, err := utils.GetUsernameFromContext(c)
+	if err != nil {
+		logrus.Errorf("[%v]%v", username, err)
+		utils.SendResponse(c, http.StatusUnauthorized, "Invalid token", nil)
+		return
+	}
+	asOf := c.DefaultQuery("as_of", time.Now().Format("2006-01-02"))
+	logrus.Infof("[%s] get payables aging as of %s", username, asOf)
+
+	report, err := rc.reportUseCase.GetPayablesAging(asOf)
+	if err != nil {
+		logrus.Errorf("[%v]%v", username, err)
+		utils.HandleError(c, err)
+		return
+	}
+	utils.SendResponse(c, http.StatusOK, "Success", report)
+}
+
+func (rc *ReportController) GetPayables(c *gin.Context) {
+	username, err := utils.GetUsernameFromContext(c)
+	if err != nil {
+		logrus.Errorf("[%v]%v", username, err)
+		utils.SendResponse(c, http.StatusUnauthorized, "Invalid token", nil)
+		return
+	}
+	supplierID := c.Query("supplier_id")
+	asOf := c.DefaultQuery("as_of", time.Now().Format("2006-01-02"))
+	logrus.Infof("[%s] get payables as of %s", username, asOf)
+
+	payables, err := rc.reportUseCase.GetPayables(supplierID, asOf)
+	if err != nil {
+		logrus.Errorf("[%v]%v", username, err)
+		utils.HandleError(c, err)
+		return
+	}
+	utils.SendResponse(c, http.StatusOK, "Success", payables)
+}
@@ -0,0 +1,183 @@
+package controller
+
+import (
+	"net/http"
+	"trackprosto/delivery/middleware"
+	"trackprosto/delivery/utils"
+	model "trackprosto/models"
+	"trackprosto/usecase"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+type StockOpnameController struct {
+	stockOpnameUseCase usecase.StockOpnameUseCase
+}
+
+func NewStockOpnameController(r *gin.Engine, stockOpnameUseCase usecase.StockOpnameUseCase) *StockOpnameController {
+	controller := &StockOpnameController{
+		stockOpnameUseCase: stockOpnameUseCase,
+	}
+
+	r.POST("/stock-opnames", middleware.RequirePermission(model.PermissionStockOpnameStart), controller.StartStockOpname)
+	r.GET("/stock-opnames", middleware.RequirePermission(model.PermissionStockOpnameView), controller.GetAllStockOpnames)
+	r.GET("/stock-opnames/:id", middleware.RequirePermission(model.PermissionStockOpnameView), controller.GetStockOpnameByID)
+	r.PUT("/stock-opnames/:id/items", middleware.RequirePermission(model.PermissionStockOpnameCount), controller.SaveCounts)
+	r.GET("/stock-opnames/:id/variance", middleware.RequirePermission(model.PermissionStockOpnameView), controller.GetVarianceReport)
+	r.POST("/stock-opnames/:id/post", middleware.RequirePermission(model.PermissionStockOpnamePost), controller.PostStockOpname)
+	r.POST("/stock-opnames/:id/cancel", middleware.RequirePermission(model.PermissionStockOpnamePost), controller.CancelStockOpname)
+
+	return controller
+}
+
+func (sc *StockOpnameController) StartStockOpname(c *gin.Context) {
+	username, err := utils.GetUsernameFromContext(c)
+	if err != nil {
+		logrus.Errorf("[%v]%v", username, err)
+		utils.SendResponse(c, http.StatusUnauthorized, "Invalid token", nil)
+		return
+	}
+	logrus.Infof("[%s] is starting a stock opname", username)
+
+	var request struct {
+		Notes string `json:"notes"`
+	}
+	if err := c.ShouldBindJSON(&request); err != nil {
+		logrus.Errorf("[%v]%v", username, err)
+		utils.SendResponse(c, http.StatusBadRequest, "Invalid request payload", nil)
+		return
+	}
+
+	opname, err := sc.stockOpnameUseCase.StartStockOpname(request.Notes, username)
+	if err != nil {
+		logrus.Errorf("[%v]%v", username, err)
+		utils.HandleError(c, err)
+		return
+	}
+	logrus.Infof("[%s] Stock opname started, id = %s", username, opname.ID)
+	utils.SendResponse(c, http.StatusCreated, "Stock opname started", opname)
+}
+
+func (sc *StockOpnameController) GetAllStockOpnames(c *gin.Context) {
+	username, err := utils.GetUsernameFromContext(c)
+	if err != nil {
+		logrus.Errorf("[%v]%v", username, err)
+		utils.SendResponse(c, http.StatusUnauthorized, "Invalid token", nil)
+		return
+	}
+	logrus.Infof("[%s] is getting all stock opnames", username)
+
+	opnames, err := sc.stockOpnameUseCase.GetAllStockOpnames()
+	if err != nil {
+		logrus.Errorf("[%v]%v", username, err)
+		utils.HandleError(c, err)
+		return
+	}
+	utils.SendResponse(c, http.StatusOK, "Success", opnames)
+}
+
+func (sc *StockOpnameController) GetStockOpnameByID(c *gin.Context) {
+	username, err := utils.GetUsernameFromContext(c)
+	if err != nil {
+		logrus.Errorf("[%v]%v", username, err)
+		utils.SendResponse(c, http.StatusUnauthorized, "Invalid token", nil)
+		return
+	}
+	id := c.Param("id")
+	logrus.Infof("[%s] is getting stock opname %s", username, id)
+
+	opname, err := sc.stockOpnameUseCase.GetStockOpnameByID(id)
+	if err != nil {
+		logrus.Errorf("[%v]%v", username, err)
+		utils.HandleError(c, err)
+		return
+	}
+	utils.SendResponse(c, http.StatusOK, "Success", opname)
+}
+
+func (sc *StockOpnameController) SaveCounts(c *gin.Context) {
+	username, err := utils.GetUsernameFromContext(c)
+	if err != nil {
+		logrus.Errorf("[%v]%v", username, err)
+		utils.SendResponse(c, http.StatusUnauthorized, "Invalid token", nil)
+		return
+	}
+	id := c.Param("id")
+	logrus.Infof("[%s] is entering counts for stock opname %s", username, id)
+
+	var request struct {
+		Items []*model.StockOpnameItemRequest `json:"items" binding:"required,dive"`
+	}
+	if err := c.ShouldBindJSON(&request); err != nil {
+		logrus.Errorf("[%v]%v", username, err)
+		utils.SendResponse(c, http.StatusBadRequest, err.Error(), nil)
+		return
+	}
+
+	opname, err := sc.stockOpnameUseCase.SaveCounts(id, request.Items, username)
+	if err != nil {
+		logrus.Errorf("[%v]%v", username, err)
+		utils.HandleError(c, err)
+		return
+	}
+	logrus.Infof("[%s] Counts saved for stock opname %s", username, id)
+	utils.SendResponse(c, http.StatusOK, "Counts saved", opname)
+}
+
+func (sc *StockOpnameController) GetVarianceReport(c *gin.Context) {
+	username, err := utils.GetUsernameFromContext(c)
+	if err != nil {
+		logrus.Errorf("[%v]%v", username, err)
+		utils.SendResponse(c, http.StatusUnauthorized, "Invalid token", nil)
+		return
+	}
+	id := c.Param("id")
+	logrus.Infof("[%s] is reviewing variance of stock opname %s", username, id)
+
+	report, err := sc.stockOpnameUseCase.GetVarianceReport(id)
+	if err != nil {
+		logrus.Errorf("[%v]%v", username, err)
+		utils.HandleError(c, err)
+		return
+	}
+	utils.SendResponse(c, http.StatusOK, "Success", report)
+}
+
+func (sc *StockOpnameController) PostStockOpname(c *gin.Context) {
+	username, err := utils.GetUsernameFromContext(c)
+	if err != nil {
+		logrus.Errorf("[%v]%v", username, err)
+		utils.SendResponse(c, http.StatusUnauthorized, "Invalid token", nil)
+		return
+	}
+	id := c.Param("id")
+	logrus.Infof("[%s] is posting stock opname %s", username, id)
+
+	report, err := sc.stockOpnameUseCase.PostStockOpname(id, username)
+	if err != nil {
+		logrus.Errorf("[%v]%v", username, err)
+		utils.HandleError(c, err)
+		return
+	}
+	logrus.Infof("[%s] Stock opname %s posted, total variance = %v", username, id, report.TotalVarianceQty)
+	utils.SendResponse(c, http.StatusOK, "Stock opname posted", report)
+}
+
+func (sc *StockOpnameController) CancelStockOpname(c *gin.Context) {
+	username, err := utils.GetUsernameFromContext(c)
+	if err != nil {
+		logrus.Errorf("[%v]%v", username, err)
+		utils.SendResponse(c, http.StatusUnauthorized, "Invalid token", nil)
+		return
+	}
+	id := c.Param("id")
+	logrus.Infof("[%s] is cancelling stock opname %s", username, id)
+
+	if err := sc.stockOpnameUseCase.CancelStockOpname(id, username); err != nil {
+		logrus.Errorf("[%v]%v", username, err)
+		utils.HandleError(c, err)
+		return
+	}
+	utils.SendResponse(c, http.StatusOK, "Stock opname cancelled", nil)
+}
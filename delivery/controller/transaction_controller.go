@@ -1,12 +1,14 @@
 package controller
 
 import (
+	"io"
 	"net/http"
 	"strconv"
 	"trackprosto/delivery/middleware"
 	"trackprosto/delivery/utils"
 	model "trackprosto/models"
 	"trackprosto/usecase"
+	"trackprosto/utils/export"
 
 	"github.com/sirupsen/logrus"
 
@@ -22,10 +24,12 @@ func NewTransactionController(r *gin.Engine, transactionUseCase usecase.Transact
 		transactionUseCase: transactionUseCase,
 	}
 
-	r.POST("/transactions", middleware.JWTAuthMiddleware("admin", "owner", "developer"), middleware.JSONMiddleware(), controller.CreateTransaction)
-	r.GET("/transactions/:invoice_number", middleware.JWTAuthMiddleware("admin", "owner", "developer"), controller.GetTransactionByInvoiceNumber)
-	r.GET("/transactions", middleware.JWTAuthMiddleware("admin", "owner", "developer"), controller.GetAllTransactions)
-	r.DELETE("/transactions/:id", middleware.JWTAuthMiddleware("admin", "owner", "developer"), controller.DeleteTransaction)
+	r.POST("/transactions", middleware.RequirePermission(model.PermissionTransactionCreate), middleware.JSONMiddleware(), controller.CreateTransaction)
+	r.GET("/transactions/export", middleware.RequirePermission(model.PermissionTransactionExport), controller.ExportTransactions)
+	r.GET("/transactions/:invoice_number", middleware.RequirePermission(model.PermissionTransactionView), controller.GetTransactionByInvoiceNumber)
+	r.GET("/transactions", middleware.RequirePermission(model.PermissionTransactionView), controller.GetAllTransactions)
+	r.DELETE("/transactions/:id", middleware.RequirePermission(model.PermissionTransactionVoid), controller.DeleteTransaction)
+	r.POST("/transactions/:id/void", middleware.RequirePermission(model.PermissionTransactionVoid), controller.VoidTransaction)
 
 	return controller
 }
@@ -56,6 +60,14 @@ func (tc *TransactionController) CreateTransaction(c *gin.Context) {
 		utils.SendResponse(c, http.StatusBadRequest, "PaymentAmount must be greater than 0", nil)
 		return
 	}
+	if request.ApproveDeviation {
+		_, role, err := utils.GetUserDetailsFromContext(c)
+		if err != nil || !middleware.HasPermission(role, model.PermissionPriceApproveDeviation) {
+			logrus.Errorf("[%v]%v", username, utils.ErrPriceApprovalNotAllowed)
+			utils.HandleError(c, utils.ErrPriceApprovalNotAllowed)
+			return
+		}
+	}
 	request.CreatedBy = username
 	transaction, err := tc.transactionUseCase.CreateTransaction(&request)
 	if err != nil {
@@ -123,25 +135,57 @@ func (tc *TransactionController) GetAllTransactions(c *gin.Context) {
 	utils.SendResponse(c, http.StatusOK, "Transactions found", map[string]interface{}{"transactions": transactions, "pagination": paginationData})
 }
 
+// DeleteTransaction voids the transaction, optionally with a ?reason= query.
 func (tc *TransactionController) DeleteTransaction(c *gin.Context) {
 
 	username, err := utils.GetUsernameFromContext(c)
 	if condition := err != nil; condition {
 		logrus.Errorf("[%v]%v", username, err)
 		utils.SendResponse(c, http.StatusUnauthorized, "Invalid token", nil)
+		return
 	}
 	logrus.Infof("[%s] is deleting a transaction", username)
 	id := c.Param("id")
-	err = tc.transactionUseCase.DeleteTransaction(id)
+	_, err = tc.transactionUseCase.VoidTransaction(id, c.DefaultQuery("reason", "Deleted"), username)
 	if err != nil {
 		logrus.Errorf("[%v]%v", username, err)
-		utils.SendResponse(c, http.StatusInternalServerError, err.Error(), nil)
+		utils.HandleError(c, err)
 		return
 	}
 	logrus.Infof("[%v] Transaction deleted successfully, id = %v", username, id)
 	utils.SendResponse(c, http.StatusOK, "Transaction deleted successfully", nil)
 }
 
+func (tc *TransactionController) VoidTransaction(c *gin.Context) {
+
+	username, err := utils.GetUsernameFromContext(c)
+	if condition := err != nil; condition {
+		logrus.Errorf("[%v]%v", username, err)
+		utils.SendResponse(c, http.StatusUnauthorized, "Invalid token", nil)
+		return
+	}
+	id := c.Param("id")
+	logrus.Infof("[%s] is voiding transaction %s", username, id)
+
+	var request struct {
+		Reason string `json:"reason" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&request); err != nil {
+		logrus.Errorf("[%v]%v", username, err)
+		utils.SendResponse(c, http.StatusBadRequest, "Reason is required", nil)
+		return
+	}
+
+	transaction, err := tc.transactionUseCase.VoidTransaction(id, request.Reason, username)
+	if err != nil {
+		logrus.Errorf("[%v]%v", username, err)
+		utils.HandleError(c, err)
+		return
+	}
+	logrus.Infof("[%v] Transaction voided, invoice number = %v, reason = %v", username, transaction.InvoiceNumber, request.Reason)
+	utils.SendResponse(c, http.StatusOK, "Transaction voided successfully", transaction)
+}
+
 func (tc *TransactionController) GetTransactionByInvoiceNumber(c *gin.Context) {
 
 	username, err := utils.GetUsernameFromContext(c)
@@ -165,3 +209,32 @@ func (tc *TransactionController) GetTransactionByInvoiceNumber(c *gin.Context) {
 	logrus.Infof("[%v] Transaction found, invoice number = %v", username, invoice_number)
 	utils.SendResponse(c, http.StatusOK, "Transaction found", transaction)
 }
+
+func (tc *TransactionController) ExportTransactions(c *gin.Context) {
+	username, err := utils.GetUsernameFromContext(c)
+	if err != nil {
+		logrus.Errorf("[%v]%v", username, err)
+		utils.SendResponse(c, http.StatusUnauthorized, "Invalid token", nil)
+		return
+	}
+	startDate, endDate, err := utils.GetDateRangeFromQuery(c)
+	if err != nil {
+		utils.SendResponse(c, http.StatusBadRequest, err.Error(), nil)
+		return
+	}
+	filter := &model.ExportFilter{
+		StartDate:     startDate,
+		EndDate:       endDate,
+		TxType:        c.Query("tx_type"),
+		PaymentStatus: c.Query("payment_status"),
+	}
+	format := c.DefaultQuery("format", export.FormatCSV)
+	logrus.Infof("[%s] is exporting transactions from %s to %s as %s", username, startDate, endDate, format)
+
+	err = utils.SendExport(c, "transactions_"+startDate+"_"+endDate, format, func(w io.Writer) error {
+		return tc.transactionUseCase.ExportTransactions(filter, format, w)
+	})
+	if err != nil {
+		logrus.Errorf("[%v]%v", username, err)
+	}
+}
@@ -10,7 +10,6 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	"github.com/sirupsen/logrus"
-	"golang.org/x/crypto/bcrypt"
 )
 
 type UserController struct {
@@ -22,16 +21,16 @@ func NewUserController(r *gin.Engine, userUC usecase.UserUseCase) {
 		userUseCase: userUC,
 	}
 
-	r.POST("/users", middleware.JWTAuthMiddleware("owner", "developer"), userController.CreateUser)
-	r.PUT("/users/:id", middleware.JWTAuthMiddleware("owner", "developer"), userController.UpdateUser)
-	r.GET("/users/:username", middleware.JWTAuthMiddleware("owner", "developer"), userController.GetUserByUsername)
-	r.GET("/users", middleware.JWTAuthMiddleware("owner", "developer"), userController.GetAllUsers)
-	r.DELETE("/users/:username", middleware.JWTAuthMiddleware("owner", "developer"), userController.DeleteUser)
+	r.POST("/users", middleware.RequirePermission(model.PermissionUserManage), userController.CreateUser)
+	r.PUT("/users/:id", middleware.RequirePermission(model.PermissionUserManage), userController.UpdateUser)
+	r.GET("/users/:username", middleware.RequirePermission(model.PermissionUserView), userController.GetUserByUsername)
+	r.GET("/users", middleware.RequirePermission(model.PermissionUserView), userController.GetAllUsers)
+	r.DELETE("/users/:username", middleware.RequirePermission(model.PermissionUserManage), userController.DeleteUser)
 }
 func (uc *UserController) CreateUser(c *gin.Context) {
-	var user model.User
+	var request model.UserRequest
 	username, err := utils.GetUsernameFromContext(c)
-	if err := c.ShouldBindJSON(&user); err != nil {
+	if err := c.ShouldBindJSON(&request); err != nil {
 		logrus.Errorf("[%v]%v", username, err)
 		utils.SendResponse(c, http.StatusBadRequest, "Bad request", nil)
 		return
@@ -39,6 +38,11 @@ func (uc *UserController) CreateUser(c *gin.Context) {
 
 	logrus.Infof("[%v] Created user", username)
 
+	user := model.User{
+		Username: request.Username,
+		Password: request.Password,
+		Role:     request.Role,
+	}
 	if user.Role != "owner" && user.Role != "developer" && user.Role != "admin" && user.Role != "employee" {
 		utils.SendResponse(c, http.StatusBadRequest, "Invalid role, must be owner, developer, admin or employee", nil)
 		return
@@ -46,14 +50,6 @@ func (uc *UserController) CreateUser(c *gin.Context) {
 	}
 
 	user.ID = uuid.New().String()
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(user.Password), bcrypt.DefaultCost)
-	if err != nil {
-		logrus.Errorf("[%v]%v", username, err)
-		utils.SendResponse(c, http.StatusInternalServerError, "Failed to encrypt password", nil)
-		return
-	}
-
-	user.Password = string(hashedPassword)
 
 	err = uc.userUseCase.CreateUser(&user)
 	if err != nil {
@@ -63,7 +59,7 @@ func (uc *UserController) CreateUser(c *gin.Context) {
 			return
 		}
 
-		utils.SendResponse(c, http.StatusInternalServerError, "internal error", nil)
+		utils.HandleError(c, err)
 		return
 	}
 	logrus.Infof("[%v] Created user %v", username, user.Username)
@@ -79,22 +75,16 @@ func (uc *UserController) UpdateUser(c *gin.Context) {
 		utils.SendResponse(c, http.StatusBadRequest, "Bad request", nil)
 		return
 	}
-	
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(user.Password), bcrypt.DefaultCost)
-	if err != nil {
-		logrus.Errorf("[%v]%v", username, err)
-		utils.SendResponse(c, http.StatusInternalServerError, "Failed to encrypt password", nil)
-		return
-	}
+
 	user.ID = userID
-	user.Password = string(hashedPassword)
-	if err := uc.userUseCase.UpdateUser(&user); err != nil {
+	updatedUser, err := uc.userUseCase.UpdateUser(&user)
+	if err != nil {
 		logrus.Errorf("[%v]%v", username, err)
 		utils.HandleError(c, err)
 		return
 	}
-	logrus.Infof("[%v] Updated user %v", username, user.Username)
-	utils.SendResponse(c, http.StatusOK, "Success", user)
+	logrus.Infof("[%v] Updated user %v", username, updatedUser.Username)
+	utils.SendResponse(c, http.StatusOK, "Success", updatedUser)
 }
 
 func (uc *UserController) GetUserByID(c *gin.Context) {
@@ -3,60 +3,135 @@ package middleware
 import (
 	"net/http"
 	"trackprosto/delivery/utils"
+	"trackprosto/utils/rolepermission"
+	"trackprosto/utils/tokenversion"
 
 	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt"
 	"github.com/sirupsen/logrus"
 )
 
-func JWTAuthMiddleware(allowedRoles ...string) gin.HandlerFunc {
+var tokenVersions *tokenversion.Cache
+var rolePermissions *rolepermission.Cache
+
+// SetTokenVersionCache sets the cache RequirePermission checks the token
+// version of each request against.
+func SetTokenVersionCache(cache *tokenversion.Cache) {
+	tokenVersions = cache
+}
+
+// SetRolePermissionCache sets the cache RequirePermission looks up the
+// permissions of roles in.
+func SetRolePermissionCache(cache *rolepermission.Cache) {
+	rolePermissions = cache
+}
+
+// HasPermission reports whether role has been granted permission. Lookup
+// failures deny.
+func HasPermission(role string, permission string) bool {
+	if rolePermissions == nil {
+		return false
+	}
+	allowed, err := rolePermissions.Allowed(role, permission)
+	if err != nil {
+		logrus.Errorf("Failed to check permission %s of role %s: %v", permission, role, err)
+		return false
+	}
+	return allowed
+}
+
+// RequirePermission lets a request through when it carries a valid token
+// whose role has been granted permission. Tokens of users who still have to
+// change their password are refused.
+func RequirePermission(permission string) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		// Mendapatkan token dari header Authorization
-		authHeader := c.GetHeader("Authorization")
-		if authHeader == "" {
-			logrus.Error("Authorization header is required")
-			utils.SendResponse(c, http.StatusUnauthorized, "Authorization header is required", nil)
-			c.Abort()
+		claims, ok := authenticate(c)
+		if !ok {
 			return
 		}
-
-		token, err := utils.ExtractTokenFromAuthHeader(authHeader)
-		if err != nil {
-			logrus.Error("Invalid authorization token")
-			utils.SendResponse(c, http.StatusBadRequest, "Invalid authorization token", nil)
+		if mustChange, _ := claims["pwd_change"].(bool); mustChange {
+			logrus.Error("Password change required")
+			utils.SendResponse(c, http.StatusForbidden, "Password change required", nil)
 			c.Abort()
 			return
 		}
 
-		claims, err := utils.VerifyJWTToken(token)
-		if err != nil {
-			logrus.Error("Invalid token or expired")
-			utils.SendResponse(c, http.StatusUnauthorized, "Invalid token or expired", nil)
+		// Memeriksa izin peran pengguna
+		role, ok := claims["role"].(string)
+		if !ok || !HasPermission(role, permission) {
+			logrus.Errorf("Access denied. Role %s lacks permission %s", role, permission)
+			utils.SendResponse(c, http.StatusForbidden, "Access denied. Missing permission "+permission, nil)
 			c.Abort()
 			return
 		}
+		c.Set("claims", claims)
 
-		// Memeriksa peran pengguna
-		role, ok := claims["role"].(string)
-		if !ok || !contains(allowedRoles, role) {
-			logrus.Error("Access denied. Invalid role")
-			utils.SendResponse(c, http.StatusForbidden, "Access denied. Invalid role", nil)
-			c.Abort()
+		c.Next()
+	}
+}
+
+// RequireAuth lets any logged in user through, including one who still has
+// to change their password.
+func RequireAuth() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		claims, ok := authenticate(c)
+		if !ok {
 			return
 		}
 		c.Set("claims", claims)
-		c.Set("claims", claims)
 
 		c.Next()
 	}
 }
 
-func contains(slice []string, value string) bool {
-	for _, v := range slice {
-		if v == value {
-			return true
+// authenticate verifies the token of the request and its token version. On
+// failure it responds and aborts the request.
+func authenticate(c *gin.Context) (jwt.MapClaims, bool) {
+	// Mendapatkan token dari header Authorization
+	authHeader := c.GetHeader("Authorization")
+	if authHeader == "" {
+		logrus.Error("Authorization header is required")
+		utils.SendResponse(c, http.StatusUnauthorized, "Authorization header is required", nil)
+		c.Abort()
+		return nil, false
+	}
+
+	token, err := utils.ExtractTokenFromAuthHeader(authHeader)
+	if err != nil {
+		logrus.Error("Invalid authorization token")
+		utils.SendResponse(c, http.StatusBadRequest, "Invalid authorization token", nil)
+		c.Abort()
+		return nil, false
+	}
+
+	claims, err := utils.VerifyJWTToken(token)
+	if err != nil {
+		logrus.Error("Invalid token or expired")
+		utils.SendResponse(c, http.StatusUnauthorized, "Invalid token or expired", nil)
+		c.Abort()
+		return nil, false
+	}
+
+	// Token yang dibuat sebelum user dihapus, dinonaktifkan atau diganti
+	// perannya memiliki token version lama
+	if tokenVersions != nil {
+		userID, _ := claims["user_id"].(string)
+		version, _ := claims["ver"].(float64)
+		valid, err := tokenVersions.Valid(userID, int(version))
+		if err != nil {
+			logrus.Errorf("Failed to check token version: %v", err)
+			utils.SendResponse(c, http.StatusInternalServerError, "Failed to verify token", nil)
+			c.Abort()
+			return nil, false
+		}
+		if !valid {
+			logrus.Error("Token has been revoked")
+			utils.SendResponse(c, http.StatusUnauthorized, "Token has been revoked", nil)
+			c.Abort()
+			return nil, false
 		}
 	}
-	return false
+	return claims, true
 }
 
 func JSONMiddleware() gin.HandlerFunc {
@@ -9,6 +9,8 @@ import (
 	// "time"
 	"trackprosto/config"
 	"trackprosto/delivery/controller"
+	"trackprosto/delivery/middleware"
+	"trackprosto/delivery/utils"
 	"trackprosto/manager"
 
 	"github.com/gin-contrib/cors"
@@ -42,6 +44,12 @@ func (s *Server) initController() {
 	controller.NewCustomerController(s.engine, s.useCaseManager.GetCustomerUsecase())
 	controller.NewCompanyController(s.engine, s.useCaseManager.GetCompanyUsecase())
 	controller.NewDailyExpenditureController(s.engine, s.useCaseManager.GetDailyExpenditureUseCase())
+	controller.NewStockOpnameController(s.engine, s.useCaseManager.GetStockOpnameUseCase())
+	controller.NewReportController(s.engine, s.useCaseManager.GetReportUseCase())
+	controller.NewNumberingSchemeController(s.engine, s.useCaseManager.GetNumberingSchemeUseCase())
+	controller.NewPrintController(s.engine, s.useCaseManager.GetPrintUseCase())
+	controller.NewPriceListController(s.engine, s.useCaseManager.GetPriceListUseCase())
+	controller.NewPermissionController(s.engine, s.useCaseManager.GetPermissionUseCase())
 }
 
 func NewServer() *Server {
@@ -49,18 +57,24 @@ func NewServer() *Server {
 	if err != nil {
 		panic(err)
 	}
+	utils.SetJWTKeys(c.JWT.Keys)
 
 	r := gin.Default()
+	if err := r.SetTrustedProxies(c.TrustedProxies); err != nil {
+		panic(err)
+	}
 	configCors := cors.DefaultConfig()
 	configCors.AllowAllOrigins = true
 	configCors.AllowMethods = []string{"GET", "POST", "PUT", "DELETE"}
-	configCors.AllowHeaders = []string{"Origin", "v-Length", "Content-Type", "Authorization"}
+	configCors.AllowHeaders = []string{"Origin", "v-Length", "Content-Type", "Authorization", "Idempotency-Key"}
 
 	r.Use(cors.New(configCors))
 
 	infra := manager.NewInfraManager(c)
 	repo := manager.NewRepoManager(infra)
-	usecase := manager.NewUsecaseManager(repo)
+	usecase := manager.NewUsecaseManager(repo, c)
+	middleware.SetTokenVersionCache(usecase.GetTokenVersionCache())
+	middleware.SetRolePermissionCache(usecase.GetRolePermissionCache())
 
 	// Inisialisasi logger
 	logger := logrus.New()
@@ -9,30 +9,75 @@ import (
 )
 
 var (
-	ErrInvoiceNumberNotExist   = errors.New("Invoice number does not exist")
-	ErrInvoiceAlreadyPaid      = errors.New("Invoice is already paid")
-	ErrAmountGreaterThanTotal  = errors.New("Amount is greater than total transaction")
-	ErrMeatNameAlreadyExist    = errors.New("Meatname already exists")
-	ErrMeatNotFound            = errors.New("Meat not found")
-	ErrCustomerNotFound        = errors.New("Customer not found")
-	ErrCompanyNotFound         = errors.New("Company not found")
-	ErrUserNotFound            = errors.New("User not found")
-	ErrTransactionNotFound     = errors.New("Transaction not found")
-	ErrTransactionAlreadyPaid  = errors.New("Transaction is already paid")
-	ErrInvalidToken            = errors.New("Invalid token")
-	ErrInvalidUsername         = errors.New("Invalid username")
-	ErrInvalidPassword         = errors.New("Invalid password")
-	ErrInvalidUsernamePassword = errors.New("Invalid username or password")
-	ErrCompanyNameAlreadyExist = errors.New("Company name already exists")
-	ErrInvalidMeatName         = errors.New("Invalid meat name")
-	ErrInvalidAmount           = errors.New("Invalid amount")
-	ErrInvalidInvoiceNumber    = errors.New("Invalid invoice number")
-	ErrCreditPaymentNotFound   = errors.New("Credit payment not found")
-	ErrInsufficientMeatStock   = errors.New("Insufficient meat stock")
-	ErrMeatStockNotEnough      = errors.New("Meat stock not enough")
-	ErrInvalidPrice            = errors.New("Invalid Meat price")
-	ErrInvalidQty              = errors.New("Invalid quantity")
-	ErrUsernameAlreadyExist    = errors.New("Username already exists")
+	ErrInvoiceNumberNotExist           = errors.New("Invoice number does not exist")
+	ErrInvoiceAlreadyPaid              = errors.New("Invoice is already paid")
+	ErrAmountGreaterThanTotal          = errors.New("Amount is greater than total transaction")
+	ErrMeatNameAlreadyExist            = errors.New("Meatname already exists")
+	ErrMeatNotFound                    = errors.New("Meat not found")
+	ErrCustomerNotFound                = errors.New("Customer not found")
+	ErrCompanyNotFound                 = errors.New("Company not found")
+	ErrUserNotFound                    = errors.New("User not found")
+	ErrTransactionNotFound             = errors.New("Transaction not found")
+	ErrTransactionAlreadyPaid          = errors.New("Transaction is already paid")
+	ErrInvalidToken                    = errors.New("Invalid token")
+	ErrInvalidUsername                 = errors.New("Invalid username")
+	ErrInvalidPassword                 = errors.New("Invalid password")
+	ErrInvalidUsernamePassword         = errors.New("Invalid username or password")
+	ErrCompanyNameAlreadyExist         = errors.New("Company name already exists")
+	ErrInvalidMeatName                 = errors.New("Invalid meat name")
+	ErrInvalidAmount                   = errors.New("Invalid amount")
+	ErrInvalidInvoiceNumber            = errors.New("Invalid invoice number")
+	ErrCreditPaymentNotFound           = errors.New("Credit payment not found")
+	ErrInsufficientMeatStock           = errors.New("Insufficient meat stock")
+	ErrMeatStockNotEnough              = errors.New("Meat stock not enough")
+	ErrInvalidPrice                    = errors.New("Invalid Meat price")
+	ErrInvalidQty                      = errors.New("Invalid quantity")
+	ErrUsernameAlreadyExist            = errors.New("Username already exists")
+	ErrIdempotencyKeyReused            = errors.New("Idempotency key already used for another invoice")
+	ErrStockOpnameNotFound             = errors.New("Stock opname not found")
+	ErrStockOpnameNotOpen              = errors.New("Stock opname is not open")
+	ErrStockOpnameInProgress           = errors.New("Another stock opname is still open")
+	ErrInvalidReasonCode               = errors.New("Invalid reason code, use shrinkage, spoilage, trimming_loss or count_correction")
+	ErrReasonCodeRequired              = errors.New("Reason code is required for every variance")
+	ErrInvalidPickingMethod            = errors.New("Picking method must be fifo or fefo")
+	ErrInvalidExpiryDate               = errors.New("Invalid expiry date, use YYYY-MM-DD")
+	ErrLotAlreadyConsumed              = errors.New("Stock from this purchase has already been used")
+	ErrInvalidGroupBy                  = errors.New("group_by must be invoice, meat or customer")
+	ErrInvalidGranularity              = errors.New("granularity must be day, week or month")
+	ErrDayClosed                       = errors.New("This day is closed, its payments and expenditures can no longer be changed")
+	ErrDayAlreadyClosed                = errors.New("This day is already closed")
+	ErrDayNotClosed                    = errors.New("This day is not closed")
+	ErrInvalidDate                     = errors.New("Invalid date, use YYYY-MM-DD")
+	ErrInvalidPaymentTerm              = errors.New("Payment term must not be negative")
+	ErrNumberingSchemeNotFound         = errors.New("Numbering scheme not found")
+	ErrInvalidResetPeriod              = errors.New("reset_period must be daily, monthly, yearly or never")
+	ErrInvalidNumberingTemplate        = errors.New("Template must contain {PREFIX}, {SEQ} and the date tokens of its reset period")
+	ErrInvalidPaperWidth               = errors.New("Paper width must be 58 or 80")
+	ErrInvalidExportFormat             = errors.New("Format must be csv or xlsx")
+	ErrInvalidTxType                   = errors.New("Transaction type must be in or out")
+	ErrInvalidPaymentStatus            = errors.New("Payment status must be paid or unpaid")
+	ErrInvalidImportFile               = errors.New("File must be CSV with a header row naming the required columns")
+	ErrPriceListNotFound               = errors.New("Price list not found")
+	ErrInvalidPriceListType            = errors.New("List type must be retail, wholesale or contract")
+	ErrInvalidMaxDeviation             = errors.New("Max deviation must not be below 0")
+	ErrDuplicatePriceListItem          = errors.New("A meat can only be listed once per price list")
+	ErrInvalidPriceTier                = errors.New("Price tier must be retail or wholesale")
+	ErrPriceNeedsApproval              = errors.New("Price deviates from the price list beyond the allowed threshold and needs an owner's approval")
+	ErrPriceApprovalNotAllowed         = errors.New("Only an owner can approve prices off the price list")
+	ErrInvalidDiscount                 = errors.New("Discount must be a percent between 0 and 100 or a fixed amount not above the subtotal")
+	ErrInvalidTaxRate                  = errors.New("Tax rate must be between 0 and 100 percent")
+	ErrInvalidRefreshToken             = errors.New("Invalid or expired refresh token")
+	ErrInvalidRole                     = errors.New("Invalid role")
+	ErrUnknownPermission               = errors.New("Unknown permission")
+	ErrCannotRevokeOwnPermissionManage = errors.New("A role cannot revoke its own permission to manage permissions")
+	ErrTooManyLoginAttempts            = errors.New("Too many failed login attempts, try again later")
+	ErrInvalidCurrentPassword          = errors.New("Current password is incorrect")
+	ErrPasswordUnchanged               = errors.New("New password must differ from the current password")
+	ErrPasswordTooShort                = errors.New("Password is shorter than the password policy allows")
+	ErrPasswordTooLong                 = errors.New("Password must not be longer than 72 bytes")
+	ErrPasswordTooWeak                 = errors.New("Password lacks a kind of character the password policy requires")
+	ErrPasswordContainsUsername        = errors.New("Password must not contain the username")
+	ErrUsernameRequired                = errors.New("Username is required")
 )
 
 func HandleError(c *gin.Context, err error) {
@@ -81,8 +126,100 @@ func HandleError(c *gin.Context, err error) {
 		SendResponse(c, http.StatusBadRequest, err.Error(), nil)
 	case ErrInvalidPrice:
 		SendResponse(c, http.StatusBadRequest, err.Error(), nil)
+	case ErrInvalidQty:
+		SendResponse(c, http.StatusBadRequest, err.Error(), nil)
 	case ErrUsernameAlreadyExist:
 		SendResponse(c, http.StatusBadRequest, err.Error(), nil)
+	case ErrIdempotencyKeyReused:
+		SendResponse(c, http.StatusConflict, err.Error(), nil)
+	case ErrStockOpnameNotFound:
+		SendResponse(c, http.StatusNotFound, err.Error(), nil)
+	case ErrStockOpnameNotOpen:
+		SendResponse(c, http.StatusBadRequest, err.Error(), nil)
+	case ErrStockOpnameInProgress:
+		SendResponse(c, http.StatusBadRequest, err.Error(), nil)
+	case ErrInvalidReasonCode:
+		SendResponse(c, http.StatusBadRequest, err.Error(), nil)
+	case ErrReasonCodeRequired:
+		SendResponse(c, http.StatusBadRequest, err.Error(), nil)
+	case ErrInvalidPickingMethod:
+		SendResponse(c, http.StatusBadRequest, err.Error(), nil)
+	case ErrInvalidExpiryDate:
+		SendResponse(c, http.StatusBadRequest, err.Error(), nil)
+	case ErrLotAlreadyConsumed:
+		SendResponse(c, http.StatusBadRequest, err.Error(), nil)
+	case ErrInvalidGroupBy:
+		SendResponse(c, http.StatusBadRequest, err.Error(), nil)
+	case ErrInvalidGranularity:
+		SendResponse(c, http.StatusBadRequest, err.Error(), nil)
+	case ErrDayClosed:
+		SendResponse(c, http.StatusConflict, err.Error(), nil)
+	case ErrDayAlreadyClosed:
+		SendResponse(c, http.StatusConflict, err.Error(), nil)
+	case ErrDayNotClosed:
+		SendResponse(c, http.StatusBadRequest, err.Error(), nil)
+	case ErrInvalidDate:
+		SendResponse(c, http.StatusBadRequest, err.Error(), nil)
+	case ErrInvalidPaymentTerm:
+		SendResponse(c, http.StatusBadRequest, err.Error(), nil)
+	case ErrNumberingSchemeNotFound:
+		SendResponse(c, http.StatusNotFound, err.Error(), nil)
+	case ErrInvalidResetPeriod:
+		SendResponse(c, http.StatusBadRequest, err.Error(), nil)
+	case ErrInvalidNumberingTemplate:
+		SendResponse(c, http.StatusBadRequest, err.Error(), nil)
+	case ErrInvalidPaperWidth:
+		SendResponse(c, http.StatusBadRequest, err.Error(), nil)
+	case ErrInvalidExportFormat:
+		SendResponse(c, http.StatusBadRequest, err.Error(), nil)
+	case ErrInvalidTxType:
+		SendResponse(c, http.StatusBadRequest, err.Error(), nil)
+	case ErrInvalidPaymentStatus:
+		SendResponse(c, http.StatusBadRequest, err.Error(), nil)
+	case ErrInvalidImportFile:
+		SendResponse(c, http.StatusBadRequest, err.Error(), nil)
+	case ErrPriceListNotFound:
+		SendResponse(c, http.StatusNotFound, err.Error(), nil)
+	case ErrInvalidPriceListType:
+		SendResponse(c, http.StatusBadRequest, err.Error(), nil)
+	case ErrInvalidMaxDeviation:
+		SendResponse(c, http.StatusBadRequest, err.Error(), nil)
+	case ErrDuplicatePriceListItem:
+		SendResponse(c, http.StatusBadRequest, err.Error(), nil)
+	case ErrInvalidPriceTier:
+		SendResponse(c, http.StatusBadRequest, err.Error(), nil)
+	case ErrPriceNeedsApproval:
+		SendResponse(c, http.StatusConflict, err.Error(), nil)
+	case ErrPriceApprovalNotAllowed:
+		SendResponse(c, http.StatusForbidden, err.Error(), nil)
+	case ErrInvalidDiscount:
+		SendResponse(c, http.StatusBadRequest, err.Error(), nil)
+	case ErrInvalidTaxRate:
+		SendResponse(c, http.StatusBadRequest, err.Error(), nil)
+	case ErrInvalidRefreshToken:
+		SendResponse(c, http.StatusUnauthorized, err.Error(), nil)
+	case ErrInvalidRole:
+		SendResponse(c, http.StatusBadRequest, err.Error(), nil)
+	case ErrUnknownPermission:
+		SendResponse(c, http.StatusBadRequest, err.Error(), nil)
+	case ErrCannotRevokeOwnPermissionManage:
+		SendResponse(c, http.StatusBadRequest, err.Error(), nil)
+	case ErrTooManyLoginAttempts:
+		SendResponse(c, http.StatusTooManyRequests, err.Error(), nil)
+	case ErrInvalidCurrentPassword:
+		SendResponse(c, http.StatusBadRequest, err.Error(), nil)
+	case ErrPasswordUnchanged:
+		SendResponse(c, http.StatusBadRequest, err.Error(), nil)
+	case ErrPasswordTooShort:
+		SendResponse(c, http.StatusBadRequest, err.Error(), nil)
+	case ErrPasswordTooLong:
+		SendResponse(c, http.StatusBadRequest, err.Error(), nil)
+	case ErrPasswordTooWeak:
+		SendResponse(c, http.StatusBadRequest, err.Error(), nil)
+	case ErrPasswordContainsUsername:
+		SendResponse(c, http.StatusBadRequest, err.Error(), nil)
+	case ErrUsernameRequired:
+		SendResponse(c, http.StatusBadRequest, err.Error(), nil)
 	default:
 		logrus.Error(err)
 		SendResponse(c, http.StatusInternalServerError, err.Error(), nil)
@@ -2,9 +2,14 @@ package utils
 
 import (
 	"errors"
+	"fmt"
+	"io"
+	"net/http"
 	"strconv"
 	"strings"
+	"time"
 	model "trackprosto/models"
+	"trackprosto/utils/export"
 
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt"
@@ -24,14 +29,25 @@ func ExtractTokenFromAuthHeader(authHeader string) (string, error) {
 	return token, nil
 }
 
-// VerifyJWTToken memverifikasi token JWT dan mengembalikan klaim JWT jika token valid
+var jwtKeys map[string][]byte
+
+// SetJWTKeys sets the keys, by key id, that VerifyJWTToken accepts.
+func SetJWTKeys(keys map[string][]byte) {
+	jwtKeys = keys
+}
+
+// VerifyJWTToken memverifikasi token JWT dan mengembalikan klaim JWT jika token valid.
+// Token harus ditandatangani dengan HMAC memakai kunci yang disebut pada header kid.
 func VerifyJWTToken(tokenString string) (jwt.MapClaims, error) {
-	// Menentukan fungsi validasi token
 	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-		// Verifikasi menggunakan HMAC, RSA, atau algoritma validasi lainnya
-		// Pastikan kunci rahasia (secret key) sesuai dengan yang digunakan saat pembuatan token
-		// Misalnya, untuk validasi menggunakan HMAC dengan algoritma HS256:
-		secretKey := []byte("secret-key")
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, fmt.Errorf("unexpected signing method %v", token.Header["alg"])
+		}
+		kid, _ := token.Header["kid"].(string)
+		secretKey, ok := jwtKeys[kid]
+		if !ok {
+			return nil, fmt.Errorf("unknown signing key %q", kid)
+		}
 		return secretKey, nil
 	})
 
@@ -93,6 +109,28 @@ func GetUsernameFromContext(c *gin.Context) (string, error) {
 	return username, nil
 }
 
+func GetUserIDFromContext(c *gin.Context) (string, error) {
+	token, err := ExtractTokenFromAuthHeader(c.GetHeader("Authorization"))
+	if err != nil {
+		logrus.Error(err)
+		return "", err
+	}
+
+	claims, err := VerifyJWTToken(token)
+	if err != nil {
+		logrus.Error(err)
+		return "", err
+	}
+
+	userID, ok := claims["user_id"].(string)
+	if !ok {
+		logrus.Error("User ID not found in claims")
+		return "", errors.New("user id not found in claims")
+	}
+
+	return userID, nil
+}
+
 func GetUserDetailsFromContext(c *gin.Context) (string, string, error) {
 	token, err := ExtractTokenFromAuthHeader(c.GetHeader("Authorization"))
 	if err != nil {
@@ -122,6 +160,77 @@ func GetUserDetailsFromContext(c *gin.Context) (string, string, error) {
 }
 
 
+// SendExport streams the spreadsheet produced by write as a file download named
+// filename. An error hit before anything was sent gets the usual JSON error
+// response; it is returned either way so the caller can log it.
+func SendExport(c *gin.Context, filename string, format string, write func(w io.Writer) error) error {
+	if !export.IsValidFormat(format) {
+		HandleError(c, ErrInvalidExportFormat)
+		return ErrInvalidExportFormat
+	}
+	c.Header("Content-Type", export.ContentType(format))
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename+"."+format))
+	c.Status(http.StatusOK)
+	err := write(c.Writer)
+	if err != nil && !c.Writer.Written() {
+		c.Writer.Header().Del("Content-Type")
+		c.Writer.Header().Del("Content-Disposition")
+		HandleError(c, err)
+	}
+	return err
+}
+
+// GetUploadedFile returns the file sent in the multipart field "file", or the
+// request body itself when the request is not multipart.
+func GetUploadedFile(c *gin.Context) (io.ReadCloser, error) {
+	if !strings.HasPrefix(c.ContentType(), "multipart/") {
+		return c.Request.Body, nil
+	}
+	header, err := c.FormFile("file")
+	if err != nil {
+		return nil, err
+	}
+	return header.Open()
+}
+
+// SendImportResult answers an import: 422 when rows were rejected and nothing
+// was stored, 200 for a clean dry run and 201 once the rows are stored.
+func SendImportResult(c *gin.Context, result *model.ImportResult) {
+	switch {
+	case len(result.Errors) > 0:
+		SendResponse(c, http.StatusUnprocessableEntity, "Import has errors, nothing was imported", result)
+	case result.DryRun:
+		SendResponse(c, http.StatusOK, "Import is valid, nothing was imported", result)
+	default:
+		SendResponse(c, http.StatusCreated, "Import succeeded", result)
+	}
+}
+
+// GetDateRangeFromQuery reads start_date and end_date (YYYY-MM-DD) from the
+// query string, defaulting to the current month up to today.
+func GetDateRangeFromQuery(c *gin.Context) (string, string, error) {
+	return GetDateRangeFromQueryKeys(c, "start_date", "end_date")
+}
+
+// GetDateRangeFromQueryKeys is GetDateRangeFromQuery with other query parameter names.
+func GetDateRangeFromQueryKeys(c *gin.Context, startKey string, endKey string) (string, string, error) {
+	now := time.Now()
+	startDate := c.DefaultQuery(startKey, now.AddDate(0, 0, 1-now.Day()).Format("2006-01-02"))
+	endDate := c.DefaultQuery(endKey, now.Format("2006-01-02"))
+	start, err := time.Parse("2006-01-02", startDate)
+	if err != nil {
+		return "", "", fmt.Errorf("invalid %s, use YYYY-MM-DD", startKey)
+	}
+	end, err := time.Parse("2006-01-02", endDate)
+	if err != nil {
+		return "", "", fmt.Errorf("invalid %s, use YYYY-MM-DD", endKey)
+	}
+	if end.Before(start) {
+		return "", "", fmt.Errorf("%s must not be before %s", endKey, startKey)
+	}
+	return startDate, endDate, nil
+}
+
 func NonEmpty(value, defaultValue string) string {
 	if value != "" {
 		return value
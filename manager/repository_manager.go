@@ -13,6 +13,17 @@ type RepoManager interface {
 	GetTransactionRepo() repository.TransactionRepository
 	GetCreditPaymentRepo() repository.CreditPaymentRepository
 	GetDailyExpenditureRepo() repository.DailyExpenditureRepository
+	GetCustomerLedgerRepo() repository.CustomerLedgerRepository
+	GetStockOpnameRepo() repository.StockOpnameRepository
+	GetMeatLotRepo() repository.MeatLotRepository
+	GetReportRepo() repository.ReportRepository
+	GetDayClosingRepo() repository.DayClosingRepository
+	GetSequenceRepo() repository.SequenceRepository
+	GetNumberingSchemeRepo() repository.NumberingSchemeRepository
+	GetPriceListRepo() repository.PriceListRepository
+	GetRefreshTokenRepo() repository.RefreshTokenRepository
+	GetPermissionRepo() repository.PermissionRepository
+	GetLoginAttemptRepo() repository.LoginAttemptRepository
 }
 
 type repoManager struct {
@@ -24,6 +35,17 @@ type repoManager struct {
 	transactionRepo      repository.TransactionRepository
 	creditPaymentRepo    repository.CreditPaymentRepository
 	dailyExpenditureRepo repository.DailyExpenditureRepository
+	customerLedgerRepo   repository.CustomerLedgerRepository
+	stockOpnameRepo      repository.StockOpnameRepository
+	meatLotRepo          repository.MeatLotRepository
+	reportRepo           repository.ReportRepository
+	dayClosingRepo       repository.DayClosingRepository
+	sequenceRepo         repository.SequenceRepository
+	numberingSchemeRepo  repository.NumberingSchemeRepository
+	priceListRepo        repository.PriceListRepository
+	refreshTokenRepo     repository.RefreshTokenRepository
+	permissionRepo       repository.PermissionRepository
+	loginAttemptRepo     repository.LoginAttemptRepository
 }
 
 // GetCustomerRepo implements RepoManager.
@@ -35,6 +57,17 @@ var onceLoadCompanyRepo sync.Once
 var onceLoadTxRepo sync.Once
 var onceLoadCreditPaymentRepo sync.Once
 var onceLoadDailyExpenditureRepo sync.Once
+var onceLoadCustomerLedgerRepo sync.Once
+var onceLoadStockOpnameRepo sync.Once
+var onceLoadMeatLotRepo sync.Once
+var onceLoadReportRepo sync.Once
+var onceLoadDayClosingRepo sync.Once
+var onceLoadSequenceRepo sync.Once
+var onceLoadNumberingSchemeRepo sync.Once
+var onceLoadPriceListRepo sync.Once
+var onceLoadRefreshTokenRepo sync.Once
+var onceLoadPermissionRepo sync.Once
+var onceLoadLoginAttemptRepo sync.Once
 
 func (rm *repoManager) GetDailyExpenditureRepo() repository.DailyExpenditureRepository {
 	onceLoadDailyExpenditureRepo.Do(func() {
@@ -85,6 +118,83 @@ func (rm *repoManager) GetCompanyRepo() repository.CompanyRepository {
 	return rm.companyRepo
 }
 
+func (rm *repoManager) GetCustomerLedgerRepo() repository.CustomerLedgerRepository {
+	onceLoadCustomerLedgerRepo.Do(func() {
+		rm.customerLedgerRepo = repository.NewCustomerLedgerRepository(rm.infraManager.GetDB())
+	})
+	return rm.customerLedgerRepo
+}
+
+func (rm *repoManager) GetStockOpnameRepo() repository.StockOpnameRepository {
+	onceLoadStockOpnameRepo.Do(func() {
+		rm.stockOpnameRepo = repository.NewStockOpnameRepository(rm.infraManager.GetDB())
+	})
+	return rm.stockOpnameRepo
+}
+
+func (rm *repoManager) GetMeatLotRepo() repository.MeatLotRepository {
+	onceLoadMeatLotRepo.Do(func() {
+		rm.meatLotRepo = repository.NewMeatLotRepository(rm.infraManager.GetDB())
+	})
+	return rm.meatLotRepo
+}
+
+func (rm *repoManager) GetReportRepo() repository.ReportRepository {
+	onceLoadReportRepo.Do(func() {
+		rm.reportRepo = repository.NewReportRepository(rm.infraManager.GetDB())
+	})
+	return rm.reportRepo
+}
+
+func (rm *repoManager) GetDayClosingRepo() repository.DayClosingRepository {
+	onceLoadDayClosingRepo.Do(func() {
+		rm.dayClosingRepo = repository.NewDayClosingRepository(rm.infraManager.GetDB())
+	})
+	return rm.dayClosingRepo
+}
+
+func (rm *repoManager) GetSequenceRepo() repository.SequenceRepository {
+	onceLoadSequenceRepo.Do(func() {
+		rm.sequenceRepo = repository.NewSequenceRepository(rm.infraManager.GetDB())
+	})
+	return rm.sequenceRepo
+}
+
+func (rm *repoManager) GetNumberingSchemeRepo() repository.NumberingSchemeRepository {
+	onceLoadNumberingSchemeRepo.Do(func() {
+		rm.numberingSchemeRepo = repository.NewNumberingSchemeRepository(rm.infraManager.GetDB())
+	})
+	return rm.numberingSchemeRepo
+}
+
+func (rm *repoManager) GetPriceListRepo() repository.PriceListRepository {
+	onceLoadPriceListRepo.Do(func() {
+		rm.priceListRepo = repository.NewPriceListRepository(rm.infraManager.GetDB())
+	})
+	return rm.priceListRepo
+}
+
+func (rm *repoManager) GetRefreshTokenRepo() repository.RefreshTokenRepository {
+	onceLoadRefreshTokenRepo.Do(func() {
+		rm.refreshTokenRepo = repository.NewRefreshTokenRepository(rm.infraManager.GetDB())
+	})
+	return rm.refreshTokenRepo
+}
+
+func (rm *repoManager) GetPermissionRepo() repository.PermissionRepository {
+	onceLoadPermissionRepo.Do(func() {
+		rm.permissionRepo = repository.NewPermissionRepository(rm.infraManager.GetDB())
+	})
+	return rm.permissionRepo
+}
+
+func (rm *repoManager) GetLoginAttemptRepo() repository.LoginAttemptRepository {
+	onceLoadLoginAttemptRepo.Do(func() {
+		rm.loginAttemptRepo = repository.NewLoginAttemptRepository(rm.infraManager.GetDB())
+	})
+	return rm.loginAttemptRepo
+}
+
 func NewRepoManager(infraManager InfraManager) RepoManager {
 	return &repoManager{
 		infraManager: infraManager,
@@ -2,7 +2,10 @@ package manager
 
 import (
 	"sync"
+	"trackprosto/config"
 	"trackprosto/usecase"
+	"trackprosto/utils/rolepermission"
+	"trackprosto/utils/tokenversion"
 )
 
 type UsecaseManager interface {
@@ -14,10 +17,19 @@ type UsecaseManager interface {
 	GetCustomerUsecase() usecase.CustomerUsecase
 	GetCompanyUsecase() usecase.CompanyUseCase
 	GetDailyExpenditureUseCase() usecase.DailyExpenditureUseCase
+	GetStockOpnameUseCase() usecase.StockOpnameUseCase
+	GetReportUseCase() usecase.ReportUseCase
+	GetNumberingSchemeUseCase() usecase.NumberingSchemeUseCase
+	GetPrintUseCase() usecase.PrintUseCase
+	GetPriceListUseCase() usecase.PriceListUseCase
+	GetTokenVersionCache() *tokenversion.Cache
+	GetRolePermissionCache() *rolepermission.Cache
+	GetPermissionUseCase() usecase.PermissionUseCase
 }
 
 type usecaseManager struct {
 	repoManager             RepoManager
+	cfg                     config.Config
 	userUsecase             usecase.UserUseCase
 	loginUsecase            usecase.LoginUseCase
 	meatUsecase             usecase.MeatUseCase
@@ -26,6 +38,14 @@ type usecaseManager struct {
 	customerUsecase         usecase.CustomerUsecase
 	companyUsecase          usecase.CompanyUseCase
 	dailyExpenditureUseCase usecase.DailyExpenditureUseCase
+	stockOpnameUseCase      usecase.StockOpnameUseCase
+	reportUseCase           usecase.ReportUseCase
+	numberingSchemeUseCase  usecase.NumberingSchemeUseCase
+	printUseCase            usecase.PrintUseCase
+	priceListUseCase        usecase.PriceListUseCase
+	tokenVersionCache       *tokenversion.Cache
+	rolePermissionCache     *rolepermission.Cache
+	permissionUseCase       usecase.PermissionUseCase
 }
 
 var onceLoadUserUsecase sync.Once
@@ -36,10 +56,18 @@ var onceLoadCreditPaymentUseCase sync.Once
 var onceLoadCustomerUseCase sync.Once
 var onceLoadCompanyUsecase sync.Once
 var onceLoadDailyExpenditureUseCase sync.Once
+var onceLoadStockOpnameUseCase sync.Once
+var onceLoadReportUseCase sync.Once
+var onceLoadNumberingSchemeUseCase sync.Once
+var onceLoadPrintUseCase sync.Once
+var onceLoadPriceListUseCase sync.Once
+var onceLoadTokenVersionCache sync.Once
+var onceLoadRolePermissionCache sync.Once
+var onceLoadPermissionUseCase sync.Once
 
 func (um *usecaseManager) GetDailyExpenditureUseCase() usecase.DailyExpenditureUseCase {
 	onceLoadDailyExpenditureUseCase.Do(func() {
-		um.dailyExpenditureUseCase = usecase.NewDailyExpenditureUseCase(um.repoManager.GetDailyExpenditureRepo(), um.repoManager.GetUserRepo())
+		um.dailyExpenditureUseCase = usecase.NewDailyExpenditureUseCase(um.repoManager.GetDailyExpenditureRepo(), um.repoManager.GetUserRepo(), um.repoManager.GetDayClosingRepo(), um.repoManager.GetSequenceRepo(), um.repoManager.GetNumberingSchemeRepo())
 	})
 	return um.dailyExpenditureUseCase
 }
@@ -53,28 +81,28 @@ func (um *usecaseManager) GetCompanyUsecase() usecase.CompanyUseCase {
 
 func (um *usecaseManager) GetCustomerUsecase() usecase.CustomerUsecase {
 	onceLoadCustomerUseCase.Do(func() {
-		um.customerUsecase = usecase.NewCustomerUsecase(um.repoManager.GetCustomerRepo(), um.repoManager.GetCompanyRepo(), um.repoManager.GetTransactionRepo())
+		um.customerUsecase = usecase.NewCustomerUsecase(um.repoManager.GetCustomerRepo(), um.repoManager.GetCompanyRepo(), um.repoManager.GetTransactionRepo(), um.repoManager.GetCustomerLedgerRepo())
 	})
 	return um.customerUsecase
 }
 
 func (um *usecaseManager) GetUserUsecase() usecase.UserUseCase {
 	onceLoadUserUsecase.Do(func() {
-		um.userUsecase = usecase.NewUserUseCase(um.repoManager.GetUserRepo())
+		um.userUsecase = usecase.NewUserUseCase(um.repoManager.GetUserRepo(), um.repoManager.GetRefreshTokenRepo(), um.GetTokenVersionCache(), um.cfg.PasswordPolicy)
 	})
 	return um.userUsecase
 }
 
 func (um *usecaseManager) GetCreditPaymentUseCase() usecase.CreditPaymentUseCase {
 	onceLoadCreditPaymentUseCase.Do(func() {
-		um.creditPaymentUseCase = usecase.NewCreditPaymentUseCase(um.repoManager.GetCreditPaymentRepo(), um.repoManager.GetTransactionRepo(), um.repoManager.GetDailyExpenditureRepo())
+		um.creditPaymentUseCase = usecase.NewCreditPaymentUseCase(um.repoManager.GetCreditPaymentRepo(), um.repoManager.GetTransactionRepo(), um.repoManager.GetDailyExpenditureRepo(), um.repoManager.GetCustomerLedgerRepo(), um.repoManager.GetDayClosingRepo())
 	})
 	return um.creditPaymentUseCase
 }
 
 func (um *usecaseManager) GetLoginUsecase() usecase.LoginUseCase {
 	onceLoadLoginUsecase.Do(func() {
-		um.loginUsecase = usecase.NewLoginUseCase(um.repoManager.GetUserRepo())
+		um.loginUsecase = usecase.NewLoginUseCase(um.repoManager.GetUserRepo(), um.repoManager.GetRefreshTokenRepo(), um.repoManager.GetLoginAttemptRepo(), um.GetTokenVersionCache(), um.cfg.JWT, um.cfg.PasswordPolicy)
 	})
 	return um.loginUsecase
 }
@@ -83,7 +111,8 @@ func (mm *usecaseManager) GetMeatUsecase() usecase.MeatUseCase {
 	onceLoadMeatUsecase.Do(func() {
 		mm.meatUsecase = usecase.NewMeatUseCase(
 			mm.repoManager.GetMeatRepo(),
-			mm.repoManager.GetTransactionRepo())
+			mm.repoManager.GetTransactionRepo(),
+			mm.repoManager.GetMeatLotRepo())
 	})
 	return mm.meatUsecase
 }
@@ -97,13 +126,81 @@ func (um *usecaseManager) GetTransactionUseCase() usecase.TransactionUseCase {
 			um.repoManager.GetCompanyRepo(),
 			um.repoManager.GetCreditPaymentRepo(),
 			um.repoManager.GetDailyExpenditureRepo(),
+			um.repoManager.GetCustomerLedgerRepo(),
+			um.repoManager.GetMeatLotRepo(),
+			um.repoManager.GetDayClosingRepo(),
+			um.repoManager.GetSequenceRepo(),
+			um.repoManager.GetNumberingSchemeRepo(),
+			um.repoManager.GetPriceListRepo(),
+			um.cfg.Shop.RoundingUnit,
 		)
 	})
 	return um.transactionUseCase
 }
 
-func NewUsecaseManager(repoManager RepoManager) UsecaseManager {
+func (um *usecaseManager) GetStockOpnameUseCase() usecase.StockOpnameUseCase {
+	onceLoadStockOpnameUseCase.Do(func() {
+		um.stockOpnameUseCase = usecase.NewStockOpnameUseCase(um.repoManager.GetStockOpnameRepo(), um.repoManager.GetMeatRepo(), um.repoManager.GetMeatLotRepo())
+	})
+	return um.stockOpnameUseCase
+}
+
+func (um *usecaseManager) GetReportUseCase() usecase.ReportUseCase {
+	onceLoadReportUseCase.Do(func() {
+		um.reportUseCase = usecase.NewReportUseCase(um.repoManager.GetReportRepo(), um.repoManager.GetDayClosingRepo())
+	})
+	return um.reportUseCase
+}
+
+func (um *usecaseManager) GetNumberingSchemeUseCase() usecase.NumberingSchemeUseCase {
+	onceLoadNumberingSchemeUseCase.Do(func() {
+		um.numberingSchemeUseCase = usecase.NewNumberingSchemeUseCase(um.repoManager.GetNumberingSchemeRepo())
+	})
+	return um.numberingSchemeUseCase
+}
+
+func (um *usecaseManager) GetPrintUseCase() usecase.PrintUseCase {
+	onceLoadPrintUseCase.Do(func() {
+		um.printUseCase = usecase.NewPrintUseCase(um.repoManager.GetTransactionRepo(), um.repoManager.GetCreditPaymentRepo(), um.cfg.Shop)
+	})
+	return um.printUseCase
+}
+
+func (um *usecaseManager) GetPriceListUseCase() usecase.PriceListUseCase {
+	onceLoadPriceListUseCase.Do(func() {
+		um.priceListUseCase = usecase.NewPriceListUseCase(um.repoManager.GetPriceListRepo(), um.repoManager.GetMeatRepo(), um.repoManager.GetCompanyRepo(), um.repoManager.GetCustomerRepo())
+	})
+	return um.priceListUseCase
+}
+
+// GetTokenVersionCache returns the cache the auth middleware checks token
+// versions against; the user usecase invalidates it on changes.
+func (um *usecaseManager) GetTokenVersionCache() *tokenversion.Cache {
+	onceLoadTokenVersionCache.Do(func() {
+		um.tokenVersionCache = tokenversion.NewCache(um.repoManager.GetUserRepo().GetTokenVersion)
+	})
+	return um.tokenVersionCache
+}
+
+func (um *usecaseManager) GetPermissionUseCase() usecase.PermissionUseCase {
+	onceLoadPermissionUseCase.Do(func() {
+		um.permissionUseCase = usecase.NewPermissionUseCase(um.repoManager.GetPermissionRepo(), um.GetRolePermissionCache())
+	})
+	return um.permissionUseCase
+}
+
+// GetRolePermissionCache returns the cache the auth middleware checks
+// permissions against; the permission usecase invalidates it on changes.
+func (um *usecaseManager) GetRolePermissionCache() *rolepermission.Cache {
+	onceLoadRolePermissionCache.Do(func() {
+		um.rolePermissionCache = rolepermission.NewCache(um.repoManager.GetPermissionRepo().GetAllRolePermissions)
+	})
+	return um.rolePermissionCache
+}
+
+func NewUsecaseManager(repoManager RepoManager, cfg config.Config) UsecaseManager {
 	return &usecaseManager{
 		repoManager: repoManager,
+		cfg:         cfg,
 	}
 }
@@ -8,6 +8,7 @@ type Company struct {
 	Address     string    `json:"address"`
 	Email       string    `json:"email"`
 	PhoneNumber string    `json:"phone_number"`
+	TaxRate     float64   `json:"tax_rate"`
 	IsActive    bool      `json:"is_active"`
 	CreatedAt   time.Time `json:"created_at" gorm:"autoCreateTime"`
 	UpdatedAt   time.Time `json:"updated_at" gorm:"autoUpdateTime"`
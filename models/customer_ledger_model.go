@@ -0,0 +1,44 @@
+package model
+
+import "time"
+
+const (
+	LedgerSourceTransaction   = "transaction"
+	LedgerSourceCreditPayment = "credit_payment"
+	LedgerSourceVoid          = "void"
+)
+
+// CustomerLedgerEntry is one debit or credit line in customer_ledger. Entries
+// of "out" invoices and of "in" invoices are kept as separate balances. A
+// positive balance (debit - credit) is owed to us, a negative one is owed by us.
+type CustomerLedgerEntry struct {
+	ID          string    `json:"id" gorm:"primaryKey"`
+	CustomerID  string    `json:"customer_id"`
+	TxType      string    `json:"tx_type"`
+	EntryDate   string    `json:"entry_date"`
+	SourceType  string    `json:"source_type"`
+	SourceID    string    `json:"source_id"`
+	Reference   string    `json:"reference"`
+	Debit       float64   `json:"debit"`
+	Credit      float64   `json:"credit"`
+	Description string    `json:"description"`
+	CreatedAt   time.Time `json:"created_at" gorm:"autoCreateTime"`
+	CreatedBy   string    `json:"created_by"`
+	Balance     float64   `json:"balance" gorm:"-"`
+}
+
+func (CustomerLedgerEntry) TableName() string {
+	return "customer_ledger"
+}
+
+type CustomerLedger struct {
+	CustomerID     string                 `json:"customer_id"`
+	TxType         string                 `json:"tx_type"`
+	StartDate      string                 `json:"start_date"`
+	EndDate        string                 `json:"end_date"`
+	OpeningBalance float64                `json:"opening_balance"`
+	TotalDebit     float64                `json:"total_debit"`
+	TotalCredit    float64                `json:"total_credit"`
+	ClosingBalance float64                `json:"closing_balance"`
+	Entries        []*CustomerLedgerEntry `json:"entries"`
+}
@@ -0,0 +1,56 @@
+package model
+
+import "time"
+
+const (
+	CashCustomerPayment = "customer_payment"
+	CashSupplierPayment = "supplier_payment"
+	CashExpenditure     = "expenditure"
+)
+
+// DayClosing locks a day: once it exists, payments and expenditures dated
+// that day can no longer be created, changed or voided.
+type DayClosing struct {
+	Date           string    `json:"date" gorm:"primaryKey"`
+	OpeningBalance float64   `json:"opening_balance"`
+	TotalInflow    float64   `json:"total_inflow"`
+	TotalOutflow   float64   `json:"total_outflow"`
+	ClosingBalance float64   `json:"closing_balance"`
+	CountedCash    *float64  `json:"counted_cash"`
+	CashDifference *float64  `json:"cash_difference"`
+	Notes          string    `json:"notes"`
+	ClosedAt       time.Time `json:"closed_at"`
+	ClosedBy       string    `json:"closed_by"`
+}
+
+func (DayClosing) TableName() string {
+	return "day_closings"
+}
+
+type DayClosingRequest struct {
+	Date        string   `json:"date" binding:"required"`
+	CountedCash *float64 `json:"counted_cash"`
+	Notes       string   `json:"notes"`
+}
+
+// CashLine is one cash movement of a day: a customer payment in, a supplier
+// payment or an operational expenditure out.
+type CashLine struct {
+	Type        string  `json:"type"`
+	Reference   string  `json:"reference"`
+	Description string  `json:"description"`
+	Amount      float64 `json:"amount"`
+	CreatedBy   string  `json:"created_by"`
+}
+
+type DailyClosingReport struct {
+	Date           string      `json:"date"`
+	OpeningBalance float64     `json:"opening_balance"`
+	Inflows        []*CashLine `json:"inflows"`
+	Outflows       []*CashLine `json:"outflows"`
+	TotalInflow    float64     `json:"total_inflow"`
+	TotalOutflow   float64     `json:"total_outflow"`
+	ClosingBalance float64     `json:"closing_balance"`
+	IsLocked       bool        `json:"is_locked"`
+	Closing        *DayClosing `json:"closing,omitempty"`
+}
@@ -8,6 +8,7 @@ type CompanyRequest struct {
 	Address     string    `json:"address"`
 	Email       string    `json:"email"`
 	PhoneNumber string    `json:"phone_number"`
+	TaxRate     *float64  `json:"tax_rate"`
 	IsActive    bool      `json:"is_active"`
 	CreatedAt   time.Time `json:"created_at" gorm:"autoCreateTime"`
 	UpdatedAt   time.Time `json:"updated_at" gorm:"autoUpdateTime"`
@@ -16,9 +17,10 @@ type CompanyRequest struct {
 }
 
 type CompanyResponse struct {
-	ID          string `json:"id" gorm:"primary_key"`
-	CompanyName string `json:"company_name"`
-	Address     string `json:"address"`
-	Email       string `json:"email"`
-	PhoneNumber string `json:"phone_number"`
+	ID          string  `json:"id" gorm:"primary_key"`
+	CompanyName string  `json:"company_name"`
+	Address     string  `json:"address"`
+	Email       string  `json:"email"`
+	PhoneNumber string  `json:"phone_number"`
+	TaxRate     float64 `json:"tax_rate"`
 }
\ No newline at end of file
@@ -0,0 +1,9 @@
+package model
+
+// ExportFilter narrows down a spreadsheet export. Empty fields do not filter.
+type ExportFilter struct {
+	StartDate     string
+	EndDate       string
+	TxType        string
+	PaymentStatus string
+}
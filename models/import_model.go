@@ -0,0 +1,22 @@
+package model
+
+// ImportRowError is a problem found in one row of an imported file. Row is the
+// line in the file, the header being line 1.
+type ImportRowError struct {
+	Row     int    `json:"row"`
+	Column  string `json:"column,omitempty"`
+	Message string `json:"message"`
+}
+
+// ImportResult reports on an import. Rows are only stored when none of them
+// has an error and it is not a dry run.
+type ImportResult struct {
+	DryRun   bool              `json:"dry_run"`
+	Rows     int               `json:"rows"`
+	Imported int               `json:"imported"`
+	Errors   []*ImportRowError `json:"errors"`
+}
+
+func (r *ImportResult) AddError(row int, column string, message string) {
+	r.Errors = append(r.Errors, &ImportRowError{Row: row, Column: column, Message: message})
+}
@@ -1,6 +1,64 @@
 package model
 
+import "time"
+
 type LoginData struct {
 	Username string `json:"username" binding:"required"`
 	Password string `json:"password" binding:"required"`
 }
+
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+}
+
+// TokenResponse is returned on login and refresh. ExpiresIn is the lifetime
+// of the access token in seconds. While PasswordChangeRequired is set the
+// access token only works for POST /me/password.
+type TokenResponse struct {
+	AccessToken            string `json:"access_token"`
+	RefreshToken           string `json:"refresh_token"`
+	TokenType              string `json:"token_type"`
+	ExpiresIn              int64  `json:"expires_in"`
+	PasswordChangeRequired bool   `json:"password_change_required,omitempty"`
+}
+
+// ChangePasswordRequest changes the password of the logged in user.
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"current_password" binding:"required"`
+	NewPassword     string `json:"new_password" binding:"required"`
+}
+
+const (
+	LoginSuccess       = "success"
+	LoginUnknownUser   = "unknown_user"
+	LoginWrongPassword = "wrong_password"
+	LoginThrottled     = "throttled"
+)
+
+// LoginAttempt is the audit record of one call to POST /login.
+type LoginAttempt struct {
+	ID        string    `json:"id" gorm:"primaryKey"`
+	Username  string    `json:"username"`
+	UserID    string    `json:"user_id,omitempty"`
+	IPAddress string    `json:"ip_address"`
+	UserAgent string    `json:"user_agent"`
+	Success   bool      `json:"success"`
+	Reason    string    `json:"reason"`
+	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"`
+}
+
+// LoginThrottle counts the failed logins of one username or IP address.
+type LoginThrottle struct {
+	Key          string `gorm:"primaryKey"`
+	FailedCount  int    `gorm:"default:0"`
+	LastFailedAt *time.Time
+	LockedUntil  *time.Time
+}
+
+func (LoginAttempt) TableName() string {
+	return "login_attempts"
+}
+
+func (LoginThrottle) TableName() string {
+	return "login_throttles"
+}
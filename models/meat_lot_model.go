@@ -0,0 +1,57 @@
+package model
+
+import "time"
+
+const (
+	PickingFIFO = "fifo"
+	PickingFEFO = "fefo"
+)
+
+func IsValidPickingMethod(method string) bool {
+	return method == PickingFIFO || method == PickingFEFO
+}
+
+// MeatLot is one delivery of a meat. QtyRemaining goes down as the lot is
+// consumed by sales and negative adjustments.
+type MeatLot struct {
+	ID                  string    `json:"id" gorm:"primaryKey"`
+	MeatID              string    `json:"meat_id"`
+	MeatName            string    `json:"meat_name"`
+	TransactionDetailID string    `json:"transaction_detail_id,omitempty"`
+	Reference           string    `json:"reference"`
+	SupplierID          string    `json:"supplier_id,omitempty"`
+	SupplierName        string    `json:"supplier_name,omitempty"`
+	ReceivedDate        string    `json:"received_date"`
+	ExpiryDate          *string   `json:"expiry_date"`
+	CostPrice           float64   `json:"cost_price"`
+	QtyReceived         float64   `json:"qty_received"`
+	QtyRemaining        float64   `json:"qty_remaining"`
+	IsActive            bool      `json:"is_active" gorm:"default:true"`
+	CreatedAt           time.Time `json:"created_at" gorm:"autoCreateTime"`
+	CreatedBy           string    `json:"created_by"`
+	DaysToExpiry        *int      `json:"days_to_expiry,omitempty" gorm:"-"`
+}
+
+func (MeatLot) TableName() string {
+	return "meat_lots"
+}
+
+// LotConsumption records qty taken from a lot by one stock movement. LotID is
+// empty for quantity that was not covered by any lot.
+type LotConsumption struct {
+	ID         string    `json:"id" gorm:"primaryKey"`
+	LotID      string    `json:"lot_id"`
+	MeatID     string    `json:"meat_id"`
+	MovementID string    `json:"movement_id"`
+	SourceType string    `json:"source_type"`
+	SourceID   string    `json:"source_id"`
+	Qty        float64   `json:"qty"`
+	UnitCost   float64   `json:"unit_cost"`
+	IsActive   bool      `json:"is_active" gorm:"default:true"`
+	CreatedAt  time.Time `json:"created_at" gorm:"autoCreateTime"`
+	CreatedBy  string    `json:"created_by"`
+}
+
+func (LotConsumption) TableName() string {
+	return "lot_consumptions"
+}
@@ -3,15 +3,17 @@ package model
 import "time"
 
 type Meat struct {
-	ID        string    `json:"id" gorm:"primaryKey"`
-	Name      string    `json:"name"`
-	Stock     float64   `json:"stock"`
-	Price     float64   `json:"price"`
-	IsActive  bool      `json:"is_active" gorm:"default:true"`
-	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"`
-	UpdatedAt time.Time `json:"updated_at" gorm:"autoUpdateTime"`
-	CreatedBy string    `json:"created_by"`
-	UpdatedBy string    `json:"updated_by"`
+	ID            string    `json:"id" gorm:"primaryKey"`
+	Name          string    `json:"name"`
+	Stock         float64   `json:"stock"`
+	Price         float64   `json:"price"`
+	PickingMethod string    `json:"picking_method" gorm:"default:fifo"`
+	ShelfLifeDays int       `json:"shelf_life_days"`
+	IsActive      bool      `json:"is_active" gorm:"default:true"`
+	CreatedAt     time.Time `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt     time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+	CreatedBy     string    `json:"created_by"`
+	UpdatedBy     string    `json:"updated_by"`
 }
 
 type MeatWithStock struct {
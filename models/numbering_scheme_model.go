@@ -0,0 +1,39 @@
+package model
+
+import "time"
+
+const (
+	DocumentInvoiceIn   = "invoice_in"
+	DocumentInvoiceOut  = "invoice_out"
+	DocumentExpenditure = "expenditure"
+)
+
+const (
+	ResetDaily   = "daily"
+	ResetMonthly = "monthly"
+	ResetYearly  = "yearly"
+	ResetNever   = "never"
+)
+
+// NumberingScheme formats the numbers of one document type. Template tokens:
+// {PREFIX}, {YYYY}, {YY}, {MM}, {DD} and {SEQ} or {SEQ:n} for a counter padded
+// to n digits. The counter restarts every reset period.
+type NumberingScheme struct {
+	DocumentType string    `json:"document_type" gorm:"primaryKey"`
+	Prefix       string    `json:"prefix"`
+	Template     string    `json:"template"`
+	ResetPeriod  string    `json:"reset_period"`
+	UpdatedAt    time.Time `json:"updated_at"`
+	UpdatedBy    string    `json:"updated_by"`
+	Example      string    `json:"example" gorm:"-"`
+}
+
+func (NumberingScheme) TableName() string {
+	return "numbering_schemes"
+}
+
+type NumberingSchemeRequest struct {
+	Prefix      string `json:"prefix" binding:"required"`
+	Template    string `json:"template" binding:"required"`
+	ResetPeriod string `json:"reset_period" binding:"required"`
+}
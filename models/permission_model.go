@@ -0,0 +1,85 @@
+package model
+
+// Permission codes are resource:action pairs. Routes require one of them and
+// roles are granted them in the role_permissions table.
+const (
+	PermissionTransactionView       = "transaction:view"
+	PermissionTransactionCreate     = "transaction:create"
+	PermissionTransactionVoid       = "transaction:void"
+	PermissionTransactionExport     = "transaction:export"
+	PermissionPriceApproveDeviation = "price:approve_deviation"
+	PermissionPriceSuggest          = "price:suggest"
+	PermissionPriceListView         = "price_list:view"
+	PermissionPriceListManage       = "price_list:manage"
+	PermissionCreditPaymentView     = "credit_payment:view"
+	PermissionCreditPaymentCreate   = "credit_payment:create"
+	PermissionCustomerView          = "customer:view"
+	PermissionCustomerCreate        = "customer:create"
+	PermissionCustomerUpdate        = "customer:update"
+	PermissionCustomerDelete        = "customer:delete"
+	PermissionCustomerImport        = "customer:import"
+	PermissionCustomerExport        = "customer:export"
+	PermissionCustomerLedger        = "customer:ledger"
+	PermissionCompanyView           = "company:view"
+	PermissionCompanyManage         = "company:manage"
+	PermissionCompanyImport         = "company:import"
+	PermissionMeatView              = "meat:view"
+	PermissionMeatManage            = "meat:manage"
+	PermissionMeatImport            = "meat:import"
+	PermissionStockView             = "stock:view"
+	PermissionStockAdjust           = "stock:adjust"
+	PermissionStockOpnameView       = "stock_opname:view"
+	PermissionStockOpnameStart      = "stock_opname:start"
+	PermissionStockOpnameCount      = "stock_opname:count"
+	PermissionStockOpnamePost       = "stock_opname:post"
+	PermissionExpenditureView       = "expenditure:view"
+	PermissionExpenditureManage     = "expenditure:manage"
+	PermissionExpenditureExport     = "expenditure:export"
+	PermissionReportView            = "report:view"
+	PermissionReportFinancial       = "report:financial"
+	PermissionDayClose              = "day:close"
+	PermissionNumberingSchemeManage = "numbering_scheme:manage"
+	PermissionUserView              = "user:view"
+	PermissionUserManage            = "user:manage"
+	PermissionLogSend               = "log:send"
+	PermissionPermissionManage      = "permission:manage"
+)
+
+var Roles = []string{"employee", "admin", "owner", "developer"}
+
+func IsValidRole(role string) bool {
+	for _, r := range Roles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
+type Permission struct {
+	Code        string `json:"code" gorm:"primaryKey"`
+	Description string `json:"description"`
+}
+
+type RolePermission struct {
+	Role           string `json:"role" gorm:"primaryKey"`
+	PermissionCode string `json:"permission_code" gorm:"primaryKey"`
+}
+
+// RolePermissions lists the permission codes granted to a role.
+type RolePermissions struct {
+	Role        string   `json:"role"`
+	Permissions []string `json:"permissions"`
+}
+
+type RolePermissionsRequest struct {
+	Permissions []string `json:"permissions" binding:"required"`
+}
+
+func (Permission) TableName() string {
+	return "permissions"
+}
+
+func (RolePermission) TableName() string {
+	return "role_permissions"
+}
@@ -0,0 +1,72 @@
+package model
+
+import "time"
+
+// Price list types. A contract list belongs to one company; retail and
+// wholesale lists apply to customers of that price tier.
+const (
+	PriceListRetail    = "retail"
+	PriceListWholesale = "wholesale"
+	PriceListContract  = "contract"
+)
+
+func IsValidPriceListType(listType string) bool {
+	switch listType {
+	case PriceListRetail, PriceListWholesale, PriceListContract:
+		return true
+	}
+	return false
+}
+
+// IsValidPriceTier tells whether a customer can be put on the given tier.
+func IsValidPriceTier(tier string) bool {
+	return tier == PriceListRetail || tier == PriceListWholesale
+}
+
+// PriceList holds the prices of a set of meats between ValidFrom and ValidTo
+// (open ended when nil). A price entered on a sale may differ from the list
+// price by at most MaxDeviationPct percent without an owner's approval.
+type PriceList struct {
+	ID              string           `json:"id" gorm:"primaryKey"`
+	Name            string           `json:"name"`
+	ListType        string           `json:"list_type"`
+	CompanyID       *string          `json:"company_id"`
+	ValidFrom       string           `json:"valid_from"`
+	ValidTo         *string          `json:"valid_to"`
+	MaxDeviationPct float64          `json:"max_deviation_pct"`
+	IsActive        bool             `json:"is_active" gorm:"default:true"`
+	CreatedAt       time.Time        `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt       time.Time        `json:"updated_at" gorm:"autoUpdateTime"`
+	CreatedBy       string           `json:"created_by"`
+	UpdatedBy       string           `json:"updated_by"`
+	Items           []*PriceListItem `json:"items" gorm:"foreignKey:PriceListID"`
+}
+
+type PriceListItem struct {
+	PriceListID string  `json:"-" gorm:"primaryKey"`
+	MeatID      string  `json:"meat_id" gorm:"primaryKey"`
+	Price       float64 `json:"price"`
+}
+
+type PriceListRequest struct {
+	Name            string           `json:"name" binding:"required"`
+	ListType        string           `json:"list_type" binding:"required"`
+	CompanyID       string           `json:"company_id"`
+	ValidFrom       string           `json:"valid_from" binding:"required"`
+	ValidTo         string           `json:"valid_to"`
+	MaxDeviationPct *float64         `json:"max_deviation_pct"`
+	Items           []*PriceListItem `json:"items" binding:"required"`
+}
+
+// PriceSuggestion is the price a customer is charged for a meat. Without a
+// price list covering the meat it is the meat's own price, and PriceListID is
+// empty.
+type PriceSuggestion struct {
+	MeatID          string  `json:"meat_id"`
+	MeatName        string  `json:"meat_name"`
+	Price           float64 `json:"price"`
+	PriceListID     string  `json:"price_list_id,omitempty"`
+	PriceListName   string  `json:"price_list_name,omitempty"`
+	ListType        string  `json:"list_type,omitempty"`
+	MaxDeviationPct float64 `json:"max_deviation_pct,omitempty"`
+}
@@ -0,0 +1,20 @@
+package model
+
+import "time"
+
+// RefreshToken is a login session. Only the SHA-256 hash of the token handed
+// to the client is stored. A refresh replaces the token with a new one and
+// revokes the old one, recording its successor in ReplacedBy.
+type RefreshToken struct {
+	ID         string     `json:"id" gorm:"primaryKey"`
+	UserID     string     `json:"user_id"`
+	TokenHash  string     `json:"-"`
+	ExpiresAt  time.Time  `json:"expires_at"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+	ReplacedBy string     `json:"replaced_by,omitempty"`
+	CreatedAt  time.Time  `json:"created_at" gorm:"autoCreateTime"`
+}
+
+func (RefreshToken) TableName() string {
+	return "refresh_tokens"
+}
@@ -0,0 +1,148 @@
+package model
+
+const (
+	MarginByInvoice  = "invoice"
+	MarginByMeat     = "meat"
+	MarginByCustomer = "customer"
+)
+
+// MarginLine is the gross margin of one invoice, meat or customer. Revenue is
+// net of discounts and without tax; Total is the grand total invoiced.
+type MarginLine struct {
+	Key         string  `json:"key"`
+	Name        string  `json:"name"`
+	Date        string  `json:"date,omitempty"`
+	Qty         float64 `json:"qty"`
+	Revenue     float64 `json:"revenue"`
+	Discount    float64 `json:"discount"`
+	Tax         float64 `json:"tax"`
+	Total       float64 `json:"total"`
+	COGS        float64 `json:"cogs" gorm:"column:cogs"`
+	GrossMargin float64 `json:"gross_margin"`
+	MarginPct   float64 `json:"margin_pct" gorm:"-"`
+}
+
+type MarginReport struct {
+	StartDate   string        `json:"start_date"`
+	EndDate     string        `json:"end_date"`
+	GroupBy     string        `json:"group_by"`
+	Revenue     float64       `json:"revenue"`
+	Discount    float64       `json:"discount"`
+	Tax         float64       `json:"tax"`
+	Total       float64       `json:"total"`
+	COGS        float64       `json:"cogs"`
+	GrossMargin float64       `json:"gross_margin"`
+	MarginPct   float64       `json:"margin_pct"`
+	Lines       []*MarginLine `json:"lines"`
+}
+
+const (
+	GranularityDay   = "day"
+	GranularityWeek  = "week"
+	GranularityMonth = "month"
+)
+
+// PeriodAmount is an amount summed over one day, week or month. Period is the
+// first date of that period.
+type PeriodAmount struct {
+	Period string  `json:"period"`
+	Amount float64 `json:"amount"`
+}
+
+// ProfitLossLine is the P&L of one period. Revenue is net of discounts and
+// without the tax collected, which is owed to the state and shown apart.
+// Purchases are informational: the cost of what was sold is in COGS, so
+// NetProfit is Revenue - COGS - Expenditures.
+type ProfitLossLine struct {
+	Period       string  `json:"period"`
+	Revenue      float64 `json:"revenue"`
+	Discount     float64 `json:"discount"`
+	Tax          float64 `json:"tax"`
+	Purchases    float64 `json:"purchases"`
+	COGS         float64 `json:"cogs"`
+	GrossProfit  float64 `json:"gross_profit"`
+	Expenditures float64 `json:"expenditures"`
+	NetProfit    float64 `json:"net_profit"`
+}
+
+type ProfitLossReport struct {
+	StartDate   string            `json:"start_date"`
+	EndDate     string            `json:"end_date"`
+	Granularity string            `json:"granularity"`
+	Total       *ProfitLossLine   `json:"total"`
+	Periods     []*ProfitLossLine `json:"periods"`
+}
+
+// OutstandingInvoice is an active invoice that is not fully paid as of a date.
+type OutstandingInvoice struct {
+	InvoiceNumber string  `json:"invoice_number" gorm:"column:inv_number"`
+	CustomerID    string  `json:"customer_id"`
+	Name          string  `json:"name"`
+	Company       string  `json:"company"`
+	Date          string  `json:"date"`
+	DueDate       string  `json:"due_date"`
+	Total         float64 `json:"total"`
+	Paid          float64 `json:"paid"`
+	Outstanding   float64 `json:"outstanding"`
+	DaysOverdue   int     `json:"days_overdue" gorm:"-"`
+}
+
+// AgingBuckets splits an outstanding amount by how many days it is past due.
+type AgingBuckets struct {
+	Current    float64 `json:"current"`
+	Days1To30  float64 `json:"days_1_30"`
+	Days31To60 float64 `json:"days_31_60"`
+	Days61To90 float64 `json:"days_61_90"`
+	Over90     float64 `json:"over_90"`
+	Total      float64 `json:"total"`
+}
+
+// Add puts amount into the bucket for daysOverdue.
+func (b *AgingBuckets) Add(daysOverdue int, amount float64) {
+	switch {
+	case daysOverdue <= 0:
+		b.Current += amount
+	case daysOverdue <= 30:
+		b.Days1To30 += amount
+	case daysOverdue <= 60:
+		b.Days31To60 += amount
+	case daysOverdue <= 90:
+		b.Days61To90 += amount
+	default:
+		b.Over90 += amount
+	}
+	b.Total += amount
+}
+
+type AgingLine struct {
+	Key  string `json:"key"`
+	Name string `json:"name"`
+	AgingBuckets
+	Invoices []*OutstandingInvoice `json:"invoices,omitempty"`
+}
+
+type AgingReport struct {
+	AsOf      string       `json:"as_of"`
+	Total     AgingBuckets `json:"total"`
+	Customers []*AgingLine `json:"customers"`
+	Companies []*AgingLine `json:"companies"`
+}
+
+// SupplierPayable is what we still owe one supplier on "in" invoices.
+type SupplierPayable struct {
+	SupplierID   string  `json:"supplier_id"`
+	Name         string  `json:"name"`
+	Company      string  `json:"company"`
+	Outstanding  float64 `json:"outstanding"`
+	Overdue      float64 `json:"overdue"`
+	InvoiceCount int     `json:"invoice_count"`
+	NextDueDate  string  `json:"next_due_date"`
+}
+
+type PayableList struct {
+	AsOf        string                `json:"as_of"`
+	Outstanding float64               `json:"outstanding"`
+	Overdue     float64               `json:"overdue"`
+	Suppliers   []*SupplierPayable    `json:"suppliers"`
+	Invoices    []*OutstandingInvoice `json:"invoices"`
+}
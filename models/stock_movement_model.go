@@ -0,0 +1,31 @@
+package model
+
+import "time"
+
+const (
+	StockSourcePurchase   = "purchase"
+	StockSourceSale       = "sale"
+	StockSourceAdjustment = "adjustment"
+	StockSourceVoid       = "void"
+	StockSourceWaste      = "waste"
+)
+
+// StockMovement is one entry of the stock journal. Qty is signed: positive
+// adds to meats.stock, negative takes from it.
+type StockMovement struct {
+	ID         string    `json:"id" gorm:"primaryKey"`
+	MeatID     string    `json:"meat_id"`
+	Qty        float64   `json:"qty"`
+	StockAfter float64   `json:"stock_after"`
+	SourceType string    `json:"source_type"`
+	SourceID   string    `json:"source_id"`
+	Reference  string    `json:"reference"`
+	ReasonCode string    `json:"reason_code,omitempty"`
+	Notes      string    `json:"notes"`
+	CreatedAt  time.Time `json:"created_at" gorm:"autoCreateTime"`
+	CreatedBy  string    `json:"created_by"`
+}
+
+func (StockMovement) TableName() string {
+	return "stock_movements"
+}
@@ -0,0 +1,106 @@
+package model
+
+import "time"
+
+const (
+	StockOpnameOpen      = "open"
+	StockOpnamePosted    = "posted"
+	StockOpnameCancelled = "cancelled"
+)
+
+// Reason codes for stock adjustments.
+const (
+	ReasonShrinkage       = "shrinkage"
+	ReasonSpoilage        = "spoilage"
+	ReasonTrimmingLoss    = "trimming_loss"
+	ReasonCountCorrection = "count_correction"
+)
+
+// AdjustmentSourceType maps a reason code to the stock movement source type:
+// spoiled and trimmed meat is waste, everything else a plain adjustment.
+func AdjustmentSourceType(reasonCode string) string {
+	switch reasonCode {
+	case ReasonSpoilage, ReasonTrimmingLoss:
+		return StockSourceWaste
+	}
+	return StockSourceAdjustment
+}
+
+func IsValidReasonCode(reasonCode string) bool {
+	switch reasonCode {
+	case ReasonShrinkage, ReasonSpoilage, ReasonTrimmingLoss, ReasonCountCorrection:
+		return true
+	}
+	return false
+}
+
+// StockOpname is one physical count session of the warehouse.
+type StockOpname struct {
+	ID        string             `json:"id" gorm:"primaryKey"`
+	Status    string             `json:"status"`
+	Notes     string             `json:"notes"`
+	StartedAt time.Time          `json:"started_at"`
+	StartedBy string             `json:"started_by"`
+	PostedAt  *time.Time         `json:"posted_at,omitempty"`
+	PostedBy  string             `json:"posted_by,omitempty"`
+	CreatedAt time.Time          `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt time.Time          `json:"updated_at" gorm:"autoUpdateTime"`
+	UpdatedBy string             `json:"updated_by"`
+	Items     []*StockOpnameItem `json:"items" gorm:"foreignKey:OpnameID"`
+}
+
+func (StockOpname) TableName() string {
+	return "stock_opnames"
+}
+
+// StockOpnameItem holds the counted kg of one meat. SystemStock and Variance
+// are taken from meats.stock when the count is saved and posted as they are.
+type StockOpnameItem struct {
+	ID           string    `json:"id" gorm:"primaryKey"`
+	OpnameID     string    `json:"opname_id"`
+	MeatID       string    `json:"meat_id"`
+	MeatName     string    `json:"meat_name"`
+	SystemStock  float64   `json:"system_stock"`
+	CountedStock float64   `json:"counted_stock"`
+	Variance     float64   `json:"variance"`
+	ReasonCode   string    `json:"reason_code"`
+	Notes        string    `json:"notes"`
+	CountedAt    time.Time `json:"counted_at"`
+	CountedBy    string    `json:"counted_by"`
+}
+
+func (StockOpnameItem) TableName() string {
+	return "stock_opname_items"
+}
+
+type StockOpnameItemRequest struct {
+	MeatID       string   `json:"meat_id" binding:"required"`
+	CountedStock *float64 `json:"counted_stock" binding:"required"`
+	ReasonCode   string   `json:"reason_code"`
+	Notes        string   `json:"notes"`
+}
+
+type StockAdjustmentRequest struct {
+	Qty        float64 `json:"qty" binding:"required"`
+	ReasonCode string  `json:"reason_code" binding:"required"`
+	Notes      string  `json:"notes"`
+}
+
+type StockVarianceLine struct {
+	MeatID        string  `json:"meat_id"`
+	MeatName      string  `json:"meat_name"`
+	SystemStock   float64 `json:"system_stock"`
+	CountedStock  float64 `json:"counted_stock"`
+	Variance      float64 `json:"variance"`
+	Price         float64 `json:"price"`
+	VarianceValue float64 `json:"variance_value"`
+	ReasonCode    string  `json:"reason_code"`
+}
+
+type StockVarianceReport struct {
+	OpnameID           string               `json:"opname_id"`
+	Status             string               `json:"status"`
+	TotalVarianceQty   float64              `json:"total_variance_qty"`
+	TotalVarianceValue float64              `json:"total_variance_value"`
+	Lines              []*StockVarianceLine `json:"lines"`
+}
@@ -1,11 +1,15 @@
 package model
 
-import "time"
+import (
+	"math"
+	"time"
+)
 
 // TransactionHeader adalah representasi dari tabel transaction_headers di database.
 type TransactionHeader struct {
 	ID                 string               `json:"id" gorm:"primaryKey" gorm:"tableName=transaction_headers"`
 	Date               string               `json:"date"`
+	DueDate            string               `json:"due_date"`
 	InvoiceNumber      string               `json:"invoice_number" gorm:"column:inv_number"`
 	CustomerID         string               `json:"customer_id"`
 	Name               string               `json:"name"`
@@ -15,6 +19,13 @@ type TransactionHeader struct {
 	TxType             string               `json:"tx_type"`
 	PaymentStatus      string               `json:"payment_status"`
 	PaymentAmount      float64              `json:"payment_amount"`
+	Subtotal           float64              `json:"subtotal"`
+	DiscountType       string               `json:"discount_type,omitempty"`
+	DiscountValue      float64              `json:"discount_value,omitempty"`
+	Discount           float64              `json:"discount"`
+	TaxRate            float64              `json:"tax_rate"`
+	Tax                float64              `json:"tax"`
+	Rounding           float64              `json:"rounding"`
 	Total              float64              `json:"total"`
 	IsActive           bool                 `json:"is_active"`
 	CreatedAt          time.Time            `json:"created_at" gorm:"autoCreateTime"`
@@ -22,6 +33,11 @@ type TransactionHeader struct {
 	CreatedBy          string               `json:"created_by"`
 	UpdatedBy          string               `json:"updated_by"`
 	Debt               float64              `json:"debt" gorm:"column:debt"`
+	VoidedAt           *time.Time           `json:"voided_at,omitempty"`
+	VoidedBy           string               `json:"voided_by,omitempty"`
+	VoidReason         string               `json:"void_reason,omitempty"`
+	PriceApprovedBy    string               `json:"price_approved_by,omitempty"`
+	ApproveDeviation   bool                 `json:"approve_price_deviation,omitempty" gorm:"-"`
 	TransactionDetails []*TransactionDetail `json:"transaction_details" gorm:"foreignKey:TransactionID"`
 }
 
@@ -33,22 +49,77 @@ type TransactionDetail struct {
 	MeatName      string    `json:"meat_name"`
 	Qty           float64   `json:"qty"`
 	Price         float64   `json:"price"`
+	ListPrice     float64   `json:"list_price"`
+	Subtotal      float64   `json:"subtotal"`
+	DiscountType  string    `json:"discount_type,omitempty"`
+	DiscountValue float64   `json:"discount_value,omitempty"`
+	Discount      float64   `json:"discount"`
 	Total         float64   `json:"total"`
+	COGS          float64   `json:"cogs" gorm:"column:cogs"`
 	IsActive      bool      `json:"is_active"`
 	CreatedAt     time.Time `json:"created_at" gorm:"autoCreateTime"`
 	UpdatedAt     time.Time `json:"updated_at" gorm:"autoUpdateTime"`
 	CreatedBy     string    `json:"created_by"`
 	UpdatedBy     string    `json:"updated_by"`
+	ExpiryDate    string    `json:"expiry_date,omitempty" gorm:"-"`
 }
 
-// CalulatedTotal menghitung total transaksi berdasarkan detail transaksi.
-func (h *TransactionHeader) CalulatedTotal() {
-	total := 0.0
+const (
+	DiscountPercent = "percent"
+	DiscountFixed   = "fixed"
+)
+
+// IsValidDiscount reports whether a discount can be taken off base: a percent
+// between 0 and 100 or a fixed amount not above base. No type means no
+// discount.
+func IsValidDiscount(discountType string, value float64, base float64) bool {
+	switch discountType {
+	case "":
+		return value == 0
+	case DiscountPercent:
+		return value >= 0 && value <= 100
+	case DiscountFixed:
+		return value >= 0 && value <= base
+	}
+	return false
+}
+
+// discountAmount returns the discount on base in whole rupiah.
+func discountAmount(discountType string, value float64, base float64) float64 {
+	switch discountType {
+	case DiscountPercent:
+		return math.Round(base * value / 100)
+	case DiscountFixed:
+		return math.Min(value, base)
+	}
+	return 0
+}
+
+// CalulatedTotal menghitung total transaksi berdasarkan detail transaksi:
+// diskon per baris, diskon faktur, PPN sesuai TaxRate, lalu grand total
+// dibulatkan ke kelipatan roundingUnit rupiah terdekat.
+func (h *TransactionHeader) CalulatedTotal(roundingUnit float64) {
+	subtotal := 0.0
 	for _, detail := range h.TransactionDetails {
-		detail.Total = detail.Price * detail.Qty
-		total += detail.Total
+		detail.CalculateTotal()
+		subtotal += detail.Total
+	}
+	h.Subtotal = subtotal
+	h.Discount = discountAmount(h.DiscountType, h.DiscountValue, subtotal)
+	h.Tax = math.Round((subtotal - h.Discount) * h.TaxRate / 100)
+	total := subtotal - h.Discount + h.Tax
+	if roundingUnit <= 0 {
+		roundingUnit = 1
 	}
-	h.Total = total
+	h.Total = math.Round(total/roundingUnit) * roundingUnit
+	h.Rounding = h.Total - total
+}
+
+// CalculateTotal menghitung total baris setelah diskon baris.
+func (d *TransactionDetail) CalculateTotal() {
+	d.Subtotal = d.Price * d.Qty
+	d.Discount = discountAmount(d.DiscountType, d.DiscountValue, d.Subtotal)
+	d.Total = d.Subtotal - d.Discount
 }
 
 func (TransactionHeader) TableName() string {
@@ -62,6 +133,7 @@ func (TransactionDetail) TableName() string {
 type TransactionHeaderResponse struct {
 	ID                 string               `json:"-" gorm:"primaryKey" gorm:"tableName=transaction_headers"`
 	Date               string               `json:"date"`
+	DueDate            string               `json:"due_date"`
 	InvoiceNumber      string               `json:"invoice_number" gorm:"column:inv_number"`
 	CustomerID         string               `json:"-"`
 	Name               string               `json:"name"`
@@ -71,6 +143,11 @@ type TransactionHeaderResponse struct {
 	TxType             string               `json:"tx_type"`
 	PaymentStatus      string               `json:"payment_status"`
 	PaymentAmount      float64              `json:"payment_amount"`
+	Subtotal           float64              `json:"subtotal"`
+	Discount           float64              `json:"discount"`
+	TaxRate            float64              `json:"tax_rate"`
+	Tax                float64              `json:"tax"`
+	Rounding           float64              `json:"rounding"`
 	Total              float64              `json:"total"`
 	IsActive           bool                 `json:"-"`
 	CreatedAt          time.Time            `json:"-" gorm:"autoCreateTime"`
@@ -78,5 +155,6 @@ type TransactionHeaderResponse struct {
 	CreatedBy          string               `json:"-"`
 	UpdatedBy          string               `json:"-"`
 	Debt               float64              `json:"debt" gorm:"column:debt"`
+	PriceApprovedBy    string               `json:"price_approved_by,omitempty"`
 	TransactionDetails []*TransactionDetail `json:"transaction_details" gorm:"foreignKey:TransactionID"`
 }
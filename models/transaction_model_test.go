@@ -0,0 +1,139 @@
+package model
+
+import "testing"
+
+func TestDiscountAmount(t *testing.T) {
+	tests := []struct {
+		name         string
+		discountType string
+		value        float64
+		base         float64
+		want         float64
+	}{
+		{"no discount", "", 10, 12345, 0},
+		{"unknown type", "voucher", 10, 12345, 0},
+		{"percent", DiscountPercent, 10, 100000, 10000},
+		{"percent rounds to rupiah", DiscountPercent, 10, 12345, 1235},
+		{"fixed", DiscountFixed, 5000, 12345, 5000},
+		{"fixed capped at base", DiscountFixed, 5000, 3000, 3000},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := discountAmount(tt.discountType, tt.value, tt.base); got != tt.want {
+				t.Errorf("discountAmount(%q, %v, %v) = %v, want %v", tt.discountType, tt.value, tt.base, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCalulatedTotal(t *testing.T) {
+	tests := []struct {
+		name          string
+		header        TransactionHeader
+		roundingUnit  float64
+		wantSubtotal  float64
+		wantDiscount  float64
+		wantTax       float64
+		wantRounding  float64
+		wantTotal     float64
+		wantLineTotal []float64
+	}{
+		{
+			name: "plain lines",
+			header: TransactionHeader{TransactionDetails: []*TransactionDetail{
+				{Qty: 2, Price: 50000},
+				{Qty: 1.5, Price: 80000},
+			}},
+			roundingUnit:  1,
+			wantSubtotal:  220000,
+			wantTotal:     220000,
+			wantLineTotal: []float64{100000, 120000},
+		},
+		{
+			name: "line and invoice discounts with tax",
+			header: TransactionHeader{
+				DiscountType:  DiscountFixed,
+				DiscountValue: 10000,
+				TaxRate:       11,
+				TransactionDetails: []*TransactionDetail{
+					{Qty: 2, Price: 50000, DiscountType: DiscountPercent, DiscountValue: 10},
+					{Qty: 1.5, Price: 80000},
+				},
+			},
+			roundingUnit:  1000,
+			wantSubtotal:  210000,
+			wantDiscount:  10000,
+			wantTax:       22000,
+			wantTotal:     222000,
+			wantLineTotal: []float64{90000, 120000},
+		},
+		{
+			name:          "rounded down",
+			header:        TransactionHeader{TransactionDetails: []*TransactionDetail{{Qty: 1, Price: 12345}}},
+			roundingUnit:  100,
+			wantSubtotal:  12345,
+			wantRounding:  -45,
+			wantTotal:     12300,
+			wantLineTotal: []float64{12345},
+		},
+		{
+			name:          "rounded up",
+			header:        TransactionHeader{TransactionDetails: []*TransactionDetail{{Qty: 1, Price: 12345}}},
+			roundingUnit:  500,
+			wantSubtotal:  12345,
+			wantRounding:  155,
+			wantTotal:     12500,
+			wantLineTotal: []float64{12345},
+		},
+		{
+			name: "tax rounded to rupiah before rounding the total",
+			header: TransactionHeader{
+				TaxRate:            11,
+				TransactionDetails: []*TransactionDetail{{Qty: 1, Price: 12345}},
+			},
+			roundingUnit:  100,
+			wantSubtotal:  12345,
+			wantTax:       1358,
+			wantRounding:  -3,
+			wantTotal:     13700,
+			wantLineTotal: []float64{12345},
+		},
+		{
+			name: "fixed invoice discount capped at subtotal",
+			header: TransactionHeader{
+				DiscountType:       DiscountFixed,
+				DiscountValue:      50000,
+				TransactionDetails: []*TransactionDetail{{Qty: 1, Price: 30000}},
+			},
+			roundingUnit:  1,
+			wantSubtotal:  30000,
+			wantDiscount:  30000,
+			wantLineTotal: []float64{30000},
+		},
+		{
+			name:          "zero rounding unit rounds to rupiah",
+			header:        TransactionHeader{TransactionDetails: []*TransactionDetail{{Qty: 0.333, Price: 10000}}},
+			roundingUnit:  0,
+			wantSubtotal:  3330,
+			wantTotal:     3330,
+			wantLineTotal: []float64{3330},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := tt.header
+			h.CalulatedTotal(tt.roundingUnit)
+			if h.Subtotal != tt.wantSubtotal || h.Discount != tt.wantDiscount || h.Tax != tt.wantTax ||
+				h.Rounding != tt.wantRounding || h.Total != tt.wantTotal {
+				t.Errorf("subtotal %v, discount %v, tax %v, rounding %v, total %v; want %v, %v, %v, %v, %v",
+					h.Subtotal, h.Discount, h.Tax, h.Rounding, h.Total,
+					tt.wantSubtotal, tt.wantDiscount, tt.wantTax, tt.wantRounding, tt.wantTotal)
+			}
+			for i, detail := range h.TransactionDetails {
+				if detail.Total != tt.wantLineTotal[i] {
+					t.Errorf("line %d total = %v, want %v", i, detail.Total, tt.wantLineTotal[i])
+				}
+			}
+		})
+	}
+}
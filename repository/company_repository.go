@@ -14,6 +14,8 @@ type CompanyRepository interface {
 	GetCompanyByName(string) (*model.Company, error)
 	GetAllCompany() ([]*model.Company, error)
 	DeleteCompany(string) error
+	GetDB() *gorm.DB
+	WithTx(tx *gorm.DB) CompanyRepository
 }
 
 type companyRepository struct {
@@ -26,6 +28,15 @@ func NewCompanyRepository(db *gorm.DB) CompanyRepository {
 	}
 }
 
+func (repo *companyRepository) GetDB() *gorm.DB {
+	return repo.db
+}
+
+// WithTx returns a copy of the repository bound to the given database transaction.
+func (repo *companyRepository) WithTx(tx *gorm.DB) CompanyRepository {
+	return &companyRepository{db: tx}
+}
+
 func (repo *companyRepository) CreateCompany(company *model.Company) error {
 	return repo.db.Create(company).Error
 }
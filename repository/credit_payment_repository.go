@@ -1,7 +1,9 @@
 package repository
 
 import (
+	"errors"
 	"fmt"
+	"time"
 
 	"gorm.io/gorm"
 
@@ -13,10 +15,13 @@ type CreditPaymentRepository interface {
 	CreateCreditPayment(payment *model.CreditPayment) error
 	GetAllCreditPayments() ([]*model.CreditPayment, error)
 	GetCreditPaymentByID(id string) (*model.CreditPayment, error)
+	GetCreditPaymentByIdempotencyKey(key string) (*model.CreditPayment, error)
 	UpdateCreditPayment(payment *model.CreditPayment) error
 	GetTotalCredit(inv_number string) (float64, error)
 	GetCreditPaymentsByInvoiceNumber(inv_number string) ([]*model.CreditPayment, error)
 	CountCreditPayments(invoiceNumber string) (int, error)
+	DeactivateCreditPaymentsByInvoiceNumber(inv_number string, updatedBy string) error
+	WithTx(tx *gorm.DB) CreditPaymentRepository
 }
 
 type creditPaymentRepository struct {
@@ -29,6 +34,11 @@ func NewCreditPaymentRepository(db *gorm.DB) CreditPaymentRepository {
 	}
 }
 
+// WithTx returns a copy of the repository bound to the given database transaction.
+func (repo *creditPaymentRepository) WithTx(tx *gorm.DB) CreditPaymentRepository {
+	return &creditPaymentRepository{db: tx}
+}
+
 func (repo *creditPaymentRepository) CreateCreditPayment(payment *model.CreditPayment) error {
 	return repo.db.Create(payment).Error
 }
@@ -44,6 +54,20 @@ func (repo *creditPaymentRepository) GetAllCreditPayments() ([]*model.CreditPaym
 func (repo *creditPaymentRepository) GetCreditPaymentByID(id string) (*model.CreditPayment, error) {
 	var payment model.CreditPayment
 	if err := repo.db.Where("id = ?", id).First(&payment).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &payment, nil
+}
+
+func (repo *creditPaymentRepository) GetCreditPaymentByIdempotencyKey(key string) (*model.CreditPayment, error) {
+	var payment model.CreditPayment
+	if err := repo.db.Where("idempotency_key = ?", key).First(&payment).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
 		return nil, err
 	}
 	return &payment, nil
@@ -55,7 +79,7 @@ func (repo *creditPaymentRepository) UpdateCreditPayment(payment *model.CreditPa
 
 func (repo *creditPaymentRepository) GetTotalCredit(inv_number string) (float64, error) {
 	var total float64
-	if err := repo.db.Model(&model.CreditPayment{}).Where("inv_number = ?", inv_number).Select("SUM(amount)").Row().Scan(&total); err != nil {
+	if err := repo.db.Model(&model.CreditPayment{}).Where("inv_number = ? AND is_active = true", inv_number).Select("COALESCE(SUM(amount), 0)").Row().Scan(&total); err != nil {
 		return 0, fmt.Errorf("failed to get total credit: %w", err)
 	}
 	return total, nil
@@ -75,9 +99,20 @@ func (repo *creditPaymentRepository) GetCreditPaymentsByInvoiceNumber(inv_number
 
 func (repo *creditPaymentRepository) CountCreditPayments(invoiceNumber string) (int, error) {
 	var count int64
-	err := repo.db.Model(&model.CreditPayment{}).Where("inv_number = ?", invoiceNumber).Count(&count).Error
+	err := repo.db.Model(&model.CreditPayment{}).Where("inv_number = ? AND is_active = true", invoiceNumber).Count(&count).Error
 	if err != nil {
 		return 0, fmt.Errorf("gagal menghitung pembayaran kredit: %w", err)
 	}
 	return int(count), nil
-}
\ No newline at end of file
+}
+func (repo *creditPaymentRepository) DeactivateCreditPaymentsByInvoiceNumber(inv_number string, updatedBy string) error {
+	err := repo.db.Model(&model.CreditPayment{}).Where("inv_number = ? AND is_active = true", inv_number).Updates(map[string]interface{}{
+		"is_active":  false,
+		"updated_at": time.Now(),
+		"updated_by": updatedBy,
+	}).Error
+	if err != nil {
+		return fmt.Errorf("failed to deactivate credit payments: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,90 @@
+package repository
+
+import (
+	"fmt"
+	model "trackprosto/models"
+
+	"gorm.io/gorm"
+)
+
+type CustomerLedgerRepository interface {
+	CreateEntry(entry *model.CustomerLedgerEntry) error
+	GetBalance(customerID string, txType string) (float64, error)
+	GetBalanceBefore(customerID string, txType string, date string) (float64, error)
+	GetBalanceByReference(customerID string, reference string) (float64, error)
+	GetEntries(customerID string, txType string, startDate string, endDate string) ([]*model.CustomerLedgerEntry, error)
+	WithTx(tx *gorm.DB) CustomerLedgerRepository
+}
+
+type customerLedgerRepository struct {
+	db *gorm.DB
+}
+
+func NewCustomerLedgerRepository(db *gorm.DB) CustomerLedgerRepository {
+	return &customerLedgerRepository{
+		db: db,
+	}
+}
+
+// WithTx returns a copy of the repository bound to the given database transaction.
+func (repo *customerLedgerRepository) WithTx(tx *gorm.DB) CustomerLedgerRepository {
+	return &customerLedgerRepository{db: tx}
+}
+
+func (repo *customerLedgerRepository) CreateEntry(entry *model.CustomerLedgerEntry) error {
+	if err := repo.db.Create(entry).Error; err != nil {
+		return fmt.Errorf("failed to create customer ledger entry: %w", err)
+	}
+	return nil
+}
+
+// GetBalance returns the balance of the entries of one tx type ("in" or "out").
+func (repo *customerLedgerRepository) GetBalance(customerID string, txType string) (float64, error) {
+	var balance float64
+	err := repo.db.Model(&model.CustomerLedgerEntry{}).
+		Select("COALESCE(SUM(debit - credit), 0)").
+		Where("customer_id = ? AND tx_type = ?", customerID, txType).
+		Scan(&balance).Error
+	if err != nil {
+		return 0, fmt.Errorf("failed to get customer balance: %w", err)
+	}
+	return balance, nil
+}
+
+// GetBalanceBefore returns the balance of the entries of one tx type dated
+// strictly before date.
+func (repo *customerLedgerRepository) GetBalanceBefore(customerID string, txType string, date string) (float64, error) {
+	var balance float64
+	err := repo.db.Model(&model.CustomerLedgerEntry{}).
+		Select("COALESCE(SUM(debit - credit), 0)").
+		Where("customer_id = ? AND tx_type = ? AND entry_date < ?", customerID, txType, date).
+		Scan(&balance).Error
+	if err != nil {
+		return 0, fmt.Errorf("failed to get customer opening balance: %w", err)
+	}
+	return balance, nil
+}
+
+// GetBalanceByReference returns the net balance booked for one document, e.g. an invoice number.
+func (repo *customerLedgerRepository) GetBalanceByReference(customerID string, reference string) (float64, error) {
+	var balance float64
+	err := repo.db.Model(&model.CustomerLedgerEntry{}).
+		Select("COALESCE(SUM(debit - credit), 0)").
+		Where("customer_id = ? AND reference = ?", customerID, reference).
+		Scan(&balance).Error
+	if err != nil {
+		return 0, fmt.Errorf("failed to get balance by reference: %w", err)
+	}
+	return balance, nil
+}
+
+func (repo *customerLedgerRepository) GetEntries(customerID string, txType string, startDate string, endDate string) ([]*model.CustomerLedgerEntry, error) {
+	var entries []*model.CustomerLedgerEntry
+	err := repo.db.Where("customer_id = ? AND tx_type = ? AND entry_date BETWEEN ? AND ?", customerID, txType, startDate, endDate).
+		Order("entry_date ASC, created_at ASC").
+		Find(&entries).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to get customer ledger entries: %w", err)
+	}
+	return entries, nil
+}
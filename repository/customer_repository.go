@@ -14,8 +14,17 @@ type CustomerRepository interface {
 	GetAllCustomer(page int, itemsPerPage int) ([]*model.CustomerModel, int, error)
 	DeleteCustomer(string) error
 	GetAllCustomerByCompanyId(page int, itemsPerPage int, company_id string) ([]*model.CustomerModel, int, error)
+	StreamCustomers(companyID string, fn func(*model.CustomerModel) error) error
+	WithTx(tx *gorm.DB) CustomerRepository
 }
 
+// customerWithDebt selects customers together with what they owe us on "out"
+// invoices and what we owe them on "in" invoices, both derived from
+// customer_ledger.
+const customerWithDebt = "customers.*, " +
+	"COALESCE((SELECT SUM(debit - credit) FROM customer_ledger WHERE customer_ledger.customer_id = customers.id AND customer_ledger.tx_type = 'out'), 0) AS debt, " +
+	"COALESCE((SELECT SUM(credit - debit) FROM customer_ledger WHERE customer_ledger.customer_id = customers.id AND customer_ledger.tx_type = 'in'), 0) AS payable"
+
 type customerRepository struct {
 	db *gorm.DB
 }
@@ -26,6 +35,11 @@ func NewCustomerRepository(db *gorm.DB) CustomerRepository {
 	}
 }
 
+// WithTx returns a copy of the repository bound to the given database transaction.
+func (repo *customerRepository) WithTx(tx *gorm.DB) CustomerRepository {
+	return &customerRepository{db: tx}
+}
+
 func (repo *customerRepository) CreateCustomer(customer *model.CustomerModel) (*model.CustomerModel, error) {
 	err := repo.db.Create(customer).Error
 	if err != nil {
@@ -40,7 +54,7 @@ func (repo *customerRepository) UpdateCustomer(customer *model.CustomerModel) er
 
 func (repo *customerRepository) GetCustomerById(id string) (*model.CustomerModel, error) {
 	var customer model.CustomerModel
-	if err := repo.db.First(&customer, "id = ?", id).Error; err != nil {
+	if err := repo.db.Select(customerWithDebt).First(&customer, "id = ?", id).Error; err != nil {
 		return nil, err
 	}
 	return &customer, nil
@@ -48,7 +62,7 @@ func (repo *customerRepository) GetCustomerById(id string) (*model.CustomerModel
 
 func (repo *customerRepository) GetCustomerByName(name string) (*model.CustomerModel, error) {
 	var customer model.CustomerModel
-	if err := repo.db.First(&customer, "fullname = ?", name).Error; err != nil {
+	if err := repo.db.Select(customerWithDebt).First(&customer, "fullname = ?", name).Error; err != nil {
 		return nil, err
 	}
 	return &customer, nil
@@ -72,7 +86,7 @@ func (repo *customerRepository) GetAllCustomer(page int, itemsPerPage int) ([]*m
 	}
 
 	offset := (page - 1) * itemsPerPage
-	if err := repo.db.Offset(offset).Limit(itemsPerPage).
+	if err := repo.db.Select(customerWithDebt).Offset(offset).Limit(itemsPerPage).
 		Order("created_at desc").Find(&customers).Error; err != nil {
 		return nil, 0, err
 	}
@@ -97,7 +111,7 @@ func (repo *customerRepository) GetAllCustomerByCompanyId(page int, itemsPerPage
 	}
 
 	offset := (page - 1) * itemsPerPage
-	if err := repo.db.Where("company_id = ?", company_id).Offset(offset).Limit(itemsPerPage).
+	if err := repo.db.Select(customerWithDebt).Where("company_id = ?", company_id).Offset(offset).Limit(itemsPerPage).
 		Order("created_at desc").Find(&customers).Error; err != nil {
 		return nil, 0, err
 	}
@@ -107,3 +121,27 @@ func (repo *customerRepository) GetAllCustomerByCompanyId(page int, itemsPerPage
 func (repo *customerRepository) DeleteCustomer(id string) error {
 	return repo.db.Delete(&model.CustomerModel{}, "id = ?", id).Error
 }
+
+// StreamCustomers calls fn for every customer, of one company when companyID is
+// set, reading them from the database one at a time.
+func (repo *customerRepository) StreamCustomers(companyID string, fn func(*model.CustomerModel) error) error {
+	query := repo.db.Model(&model.CustomerModel{}).Select(customerWithDebt)
+	if companyID != "" {
+		query = query.Where("company_id = ?", companyID)
+	}
+	rows, err := query.Order("fullname ASC").Rows()
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var customer model.CustomerModel
+		if err := repo.db.ScanRows(rows, &customer); err != nil {
+			return err
+		}
+		if err := fn(&customer); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
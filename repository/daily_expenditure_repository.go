@@ -15,7 +15,10 @@ type DailyExpenditureRepository interface {
 	DeleteDailyExpenditure(id string) error
 	GetTotalExpenditureByDateRange(startDate time.Time, endDate time.Time) (float64, error)
 	// GetExpendituresByDateRange(startDate time.Time, endDate time.Time) ([]*model.DailyExpenditureReport, error)
-	GetLastNotaNumber(date string) (int, error)
+	DeactivateDailyExpendituresByNote(deNote string, updatedBy string) error
+	StreamDailyExpenditures(startDate string, endDate string, fn func(*model.DailyExpenditure) error) error
+	GetDB() *gorm.DB
+	WithTx(tx *gorm.DB) DailyExpenditureRepository
 }
 
 type dailyExpenditureRepository struct {
@@ -28,6 +31,15 @@ func NewDailyExpenditureRepository(db *gorm.DB) DailyExpenditureRepository {
 	}
 }
 
+func (repo *dailyExpenditureRepository) GetDB() *gorm.DB {
+	return repo.db
+}
+
+// WithTx returns a copy of the repository bound to the given database transaction.
+func (repo *dailyExpenditureRepository) WithTx(tx *gorm.DB) DailyExpenditureRepository {
+	return &dailyExpenditureRepository{db: tx}
+}
+
 func (repo *dailyExpenditureRepository) GetTotalExpenditureByDateRange(startDate time.Time, endDate time.Time) (float64, error) {
 	var total float64
 	result := repo.db.Model(&model.DailyExpenditure{}).
@@ -131,15 +143,39 @@ func (repo *dailyExpenditureRepository) DeleteDailyExpenditure(id string) error
 // 	return expenditures, nil
 // }
 
-func (repo *dailyExpenditureRepository) GetLastNotaNumber(date string) (int, error) {
-	var count int64
+func (repo *dailyExpenditureRepository) DeactivateDailyExpendituresByNote(deNote string, updatedBy string) error {
 	result := repo.db.Model(&model.DailyExpenditure{}).
-		Where("DATE(created_at) = ?", date).
-		Count(&count)
-
+		Where("de_note = ? AND is_active = ?", deNote, true).
+		Updates(map[string]interface{}{
+			"is_active":  false,
+			"updated_at": time.Now(),
+			"updated_by": updatedBy,
+		})
 	if result.Error != nil {
-		return 0, fmt.Errorf("failed to get last nota number: %w", result.Error)
+		return fmt.Errorf("failed to deactivate daily expenditures: %w", result.Error)
 	}
 
-	return int(count) + 1, nil
+	return nil
+}
+
+// StreamDailyExpenditures calls fn for every active expenditure dated within
+// the range, reading them from the database one at a time.
+func (repo *dailyExpenditureRepository) StreamDailyExpenditures(startDate string, endDate string, fn func(*model.DailyExpenditure) error) error {
+	rows, err := repo.db.Model(&model.DailyExpenditure{}).
+		Where("is_active = ? AND date BETWEEN ? AND ?", true, startDate, endDate).
+		Order("date ASC").Order("created_at ASC").Rows()
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var expenditure model.DailyExpenditure
+		if err := repo.db.ScanRows(rows, &expenditure); err != nil {
+			return err
+		}
+		if err := fn(&expenditure); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
 }
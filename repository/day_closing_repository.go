@@ -0,0 +1,141 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	model "trackprosto/models"
+
+	"gorm.io/gorm"
+)
+
+type DayClosingRepository interface {
+	CreateDayClosing(closing *model.DayClosing) error
+	GetDayClosing(date string) (*model.DayClosing, error)
+	DeleteDayClosing(date string) error
+	IsDateLocked(date string) (bool, error)
+	LockDate(date string) error
+	LockDateShared(date string) error
+	GetCashLines(cashType string, date string) ([]*model.CashLine, error)
+	GetCashTotalBefore(cashType string, date string) (float64, error)
+	GetDB() *gorm.DB
+	WithTx(tx *gorm.DB) DayClosingRepository
+}
+
+type dayClosingRepository struct {
+	db *gorm.DB
+}
+
+func NewDayClosingRepository(db *gorm.DB) DayClosingRepository {
+	return &dayClosingRepository{db: db}
+}
+
+func (r *dayClosingRepository) GetDB() *gorm.DB {
+	return r.db
+}
+
+// WithTx returns a copy of the repository bound to the given database transaction.
+func (r *dayClosingRepository) WithTx(tx *gorm.DB) DayClosingRepository {
+	return &dayClosingRepository{db: tx}
+}
+
+func (r *dayClosingRepository) CreateDayClosing(closing *model.DayClosing) error {
+	if err := r.db.Create(closing).Error; err != nil {
+		return fmt.Errorf("failed to create day closing: %w", err)
+	}
+	return nil
+}
+
+func (r *dayClosingRepository) GetDayClosing(date string) (*model.DayClosing, error) {
+	var closing model.DayClosing
+	if err := r.db.First(&closing, "date = ?", date).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &closing, nil
+}
+
+func (r *dayClosingRepository) DeleteDayClosing(date string) error {
+	return r.db.Where("date = ?", date).Delete(&model.DayClosing{}).Error
+}
+
+func (r *dayClosingRepository) IsDateLocked(date string) (bool, error) {
+	var count int64
+	if err := r.db.Model(&model.DayClosing{}).Where("date = ?", date).Count(&count).Error; err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// dayClosingLockKey names the advisory lock that guards the cash of one day.
+func dayClosingLockKey(date string) string {
+	return "day_closing:" + date
+}
+
+// LockDate keeps every other transaction from booking cash on date until the
+// surrounding database transaction ends. It waits for those already booking.
+func (r *dayClosingRepository) LockDate(date string) error {
+	if err := r.db.Exec("SELECT pg_advisory_xact_lock(hashtext(?))", dayClosingLockKey(date)).Error; err != nil {
+		return fmt.Errorf("failed to lock day %s: %w", date, err)
+	}
+	return nil
+}
+
+// LockDateShared keeps date from being closed until the surrounding database
+// transaction ends. Other transactions may book cash on it meanwhile.
+func (r *dayClosingRepository) LockDateShared(date string) error {
+	if err := r.db.Exec("SELECT pg_advisory_xact_lock_shared(hashtext(?))", dayClosingLockKey(date)).Error; err != nil {
+		return fmt.Errorf("failed to lock day %s: %w", date, err)
+	}
+	return nil
+}
+
+// cashQuery selects the active cash movements of one type, as CashLine columns.
+func (r *dayClosingRepository) cashQuery(cashType string) *gorm.DB {
+	if cashType == model.CashExpenditure {
+		return r.db.Table("daily_expenditures e").
+			Select("? AS type, e.de_note AS reference, e.description, e.amount, e.created_by", cashType).
+			Where("e.is_active = ?", true).
+			Where("NOT EXISTS (SELECT 1 FROM transaction_headers h WHERE h.inv_number = e.de_note)")
+	}
+	txType := "out"
+	if cashType == model.CashSupplierPayment {
+		txType = "in"
+	}
+	return r.db.Table("credit_payments cp").
+		Select("? AS type, cp.inv_number AS reference, CONCAT(h.name, ' - ', cp.notes) AS description, cp.amount, cp.created_by", cashType).
+		Joins("JOIN transaction_headers h ON h.inv_number = cp.inv_number").
+		Where("cp.is_active = ? AND h.tx_type = ?", true, txType)
+}
+
+// cashDateColumn is the column holding the cash date of a movement type.
+func cashDateColumn(cashType string) string {
+	if cashType == model.CashExpenditure {
+		return "e.date"
+	}
+	return "cp.payment_date"
+}
+
+func (r *dayClosingRepository) GetCashLines(cashType string, date string) ([]*model.CashLine, error) {
+	var lines []*model.CashLine
+	err := r.cashQuery(cashType).
+		Where(cashDateColumn(cashType)+" = ?", date).
+		Order("reference").
+		Scan(&lines).Error
+	if err != nil {
+		return nil, err
+	}
+	return lines, nil
+}
+
+func (r *dayClosingRepository) GetCashTotalBefore(cashType string, date string) (float64, error) {
+	var total float64
+	err := r.db.Table("(?) AS cash", r.cashQuery(cashType).Where(cashDateColumn(cashType)+" < ?", date)).
+		Select("COALESCE(SUM(amount), 0)").
+		Scan(&total).Error
+	if err != nil {
+		return 0, err
+	}
+	return total, nil
+}
@@ -0,0 +1,91 @@
+package repository
+
+import (
+	model "trackprosto/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type LoginAttemptRepository interface {
+	CreateLoginAttempt(attempt *model.LoginAttempt) error
+	GetLoginAttempts(username string, page int, itemsPerPage int) ([]*model.LoginAttempt, int, error)
+	GetThrottles(keys ...string) ([]*model.LoginThrottle, error)
+	GetThrottleForUpdate(key string) (*model.LoginThrottle, error)
+	SaveThrottle(throttle *model.LoginThrottle) error
+	ResetThrottle(key string) error
+	GetDB() *gorm.DB
+	WithTx(tx *gorm.DB) LoginAttemptRepository
+}
+
+type loginAttemptRepository struct {
+	db *gorm.DB
+}
+
+func NewLoginAttemptRepository(db *gorm.DB) LoginAttemptRepository {
+	return &loginAttemptRepository{db: db}
+}
+
+func (r *loginAttemptRepository) GetDB() *gorm.DB {
+	return r.db
+}
+
+// WithTx returns a copy of the repository bound to the given database transaction.
+func (r *loginAttemptRepository) WithTx(tx *gorm.DB) LoginAttemptRepository {
+	return &loginAttemptRepository{db: tx}
+}
+
+func (r *loginAttemptRepository) CreateLoginAttempt(attempt *model.LoginAttempt) error {
+	return r.db.Create(attempt).Error
+}
+
+// GetLoginAttempts returns the newest attempts first, of one username when it
+// is not empty.
+func (r *loginAttemptRepository) GetLoginAttempts(username string, page int, itemsPerPage int) ([]*model.LoginAttempt, int, error) {
+	query := r.db.Model(&model.LoginAttempt{})
+	if username != "" {
+		query = query.Where("username = ?", username)
+	}
+	var totalCount int64
+	if err := query.Count(&totalCount).Error; err != nil {
+		return nil, 0, err
+	}
+	totalPages := int((totalCount + int64(itemsPerPage) - 1) / int64(itemsPerPage))
+
+	var attempts []*model.LoginAttempt
+	err := query.Order("created_at DESC").Offset((page - 1) * itemsPerPage).Limit(itemsPerPage).Find(&attempts).Error
+	if err != nil {
+		return nil, totalPages, err
+	}
+	return attempts, totalPages, nil
+}
+
+func (r *loginAttemptRepository) GetThrottles(keys ...string) ([]*model.LoginThrottle, error) {
+	var throttles []*model.LoginThrottle
+	if err := r.db.Where("key IN ?", keys).Find(&throttles).Error; err != nil {
+		return nil, err
+	}
+	return throttles, nil
+}
+
+// GetThrottleForUpdate locks the counter of key, creating it when missing, so
+// concurrent failures are all counted.
+func (r *loginAttemptRepository) GetThrottleForUpdate(key string) (*model.LoginThrottle, error) {
+	err := r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(&model.LoginThrottle{Key: key}).Error
+	if err != nil {
+		return nil, err
+	}
+	var throttle model.LoginThrottle
+	if err := r.db.Clauses(clause.Locking{Strength: "UPDATE"}).First(&throttle, "key = ?", key).Error; err != nil {
+		return nil, err
+	}
+	return &throttle, nil
+}
+
+func (r *loginAttemptRepository) SaveThrottle(throttle *model.LoginThrottle) error {
+	return r.db.Save(throttle).Error
+}
+
+func (r *loginAttemptRepository) ResetThrottle(key string) error {
+	return r.db.Delete(&model.LoginThrottle{}, "key = ?", key).Error
+}
@@ -0,0 +1,153 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	model "trackprosto/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type MeatLotRepository interface {
+	CreateLot(lot *model.MeatLot) error
+	GetLotByTransactionDetailIDForUpdate(detailID string) (*model.MeatLot, error)
+	GetAvailableLotsForUpdate(meatID string, pickingMethod string) ([]*model.MeatLot, error)
+	GetLotsByMeatID(meatID string, includeEmpty bool) ([]*model.MeatLot, error)
+	GetLotsExpiringBefore(date string) ([]*model.MeatLot, error)
+	GetLastCostPrice(meatID string) (float64, error)
+	UpdateLotRemaining(lotID string, delta float64) error
+	RetireLot(lotID string) error
+	CreateConsumption(consumption *model.LotConsumption) error
+	GetConsumptionsBySource(sourceType string, sourceID string) ([]*model.LotConsumption, error)
+	DeactivateConsumptionsBySource(sourceType string, sourceID string) error
+	WithTx(tx *gorm.DB) MeatLotRepository
+}
+
+type meatLotRepository struct {
+	db *gorm.DB
+}
+
+func NewMeatLotRepository(db *gorm.DB) MeatLotRepository {
+	return &meatLotRepository{db: db}
+}
+
+// WithTx returns a copy of the repository bound to the given database transaction.
+func (r *meatLotRepository) WithTx(tx *gorm.DB) MeatLotRepository {
+	return &meatLotRepository{db: tx}
+}
+
+func (r *meatLotRepository) CreateLot(lot *model.MeatLot) error {
+	if lot.ID == "" {
+		lot.ID = uuid.NewString()
+	}
+	if err := r.db.Create(lot).Error; err != nil {
+		return fmt.Errorf("failed to create meat lot: %w", err)
+	}
+	return nil
+}
+
+func (r *meatLotRepository) GetLotByTransactionDetailIDForUpdate(detailID string) (*model.MeatLot, error) {
+	var lot model.MeatLot
+	err := r.db.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("transaction_detail_id = ? AND is_active = ?", detailID, true).
+		First(&lot).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &lot, nil
+}
+
+// GetAvailableLotsForUpdate returns the lots of a meat that still hold stock,
+// locked and in the order they should be consumed.
+func (r *meatLotRepository) GetAvailableLotsForUpdate(meatID string, pickingMethod string) ([]*model.MeatLot, error) {
+	var lots []*model.MeatLot
+	query := r.db.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("meat_id = ? AND is_active = ? AND qty_remaining > 0", meatID, true)
+	if pickingMethod == model.PickingFEFO {
+		query = query.Order("expiry_date ASC NULLS LAST")
+	}
+	err := query.Order("received_date ASC").Order("created_at ASC").Find(&lots).Error
+	if err != nil {
+		return nil, err
+	}
+	return lots, nil
+}
+
+func (r *meatLotRepository) GetLotsByMeatID(meatID string, includeEmpty bool) ([]*model.MeatLot, error) {
+	var lots []*model.MeatLot
+	query := r.db.Where("meat_id = ? AND is_active = ?", meatID, true)
+	if !includeEmpty {
+		query = query.Where("qty_remaining > 0")
+	}
+	err := query.Order("received_date ASC").Order("created_at ASC").Find(&lots).Error
+	if err != nil {
+		return nil, err
+	}
+	return lots, nil
+}
+
+// GetLotsExpiringBefore returns the lots with stock left that expire on or before date.
+func (r *meatLotRepository) GetLotsExpiringBefore(date string) ([]*model.MeatLot, error) {
+	var lots []*model.MeatLot
+	err := r.db.Where("is_active = ? AND qty_remaining > 0 AND expiry_date <= ?", true, date).
+		Order("expiry_date ASC").Order("meat_name ASC").Find(&lots).Error
+	if err != nil {
+		return nil, err
+	}
+	return lots, nil
+}
+
+// GetLastCostPrice returns the cost of the most recently received lot of a meat, or 0.
+func (r *meatLotRepository) GetLastCostPrice(meatID string) (float64, error) {
+	var lots []*model.MeatLot
+	err := r.db.Where("meat_id = ? AND is_active = ?", meatID, true).
+		Order("received_date DESC").Order("created_at DESC").Limit(1).Find(&lots).Error
+	if err != nil {
+		return 0, err
+	}
+	if len(lots) == 0 {
+		return 0, nil
+	}
+	return lots[0].CostPrice, nil
+}
+
+func (r *meatLotRepository) UpdateLotRemaining(lotID string, delta float64) error {
+	return r.db.Model(&model.MeatLot{}).Where("id = ?", lotID).
+		UpdateColumn("qty_remaining", gorm.Expr("qty_remaining + ?", delta)).Error
+}
+
+func (r *meatLotRepository) RetireLot(lotID string) error {
+	return r.db.Model(&model.MeatLot{}).Where("id = ?", lotID).
+		UpdateColumns(map[string]interface{}{"qty_remaining": 0, "is_active": false}).Error
+}
+
+func (r *meatLotRepository) CreateConsumption(consumption *model.LotConsumption) error {
+	if consumption.ID == "" {
+		consumption.ID = uuid.NewString()
+	}
+	if err := r.db.Create(consumption).Error; err != nil {
+		return fmt.Errorf("failed to create lot consumption: %w", err)
+	}
+	return nil
+}
+
+func (r *meatLotRepository) GetConsumptionsBySource(sourceType string, sourceID string) ([]*model.LotConsumption, error) {
+	var consumptions []*model.LotConsumption
+	err := r.db.Where("source_type = ? AND source_id = ? AND is_active = ?", sourceType, sourceID, true).
+		Order("created_at ASC").Find(&consumptions).Error
+	if err != nil {
+		return nil, err
+	}
+	return consumptions, nil
+}
+
+func (r *meatLotRepository) DeactivateConsumptionsBySource(sourceType string, sourceID string) error {
+	return r.db.Model(&model.LotConsumption{}).
+		Where("source_type = ? AND source_id = ?", sourceType, sourceID).
+		Update("is_active", false).Error
+}
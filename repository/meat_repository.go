@@ -2,21 +2,25 @@ package repository
 
 import (
 	"errors"
-	"fmt"
+	"time"
 	model "trackprosto/models"
 
+	"github.com/google/uuid"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type MeatRepository interface {
 	CreateMeat(meat *model.Meat) error
 	GetMeatByID(string) (*model.Meat, error)
+	GetMeatByIDForUpdate(string) (*model.Meat, error)
 	GetAllMeats(page int, itemsPerPage int) ([]*model.Meat, int, error)
 	GetMeatByName(string) (*model.Meat, error)
 	UpdateMeat(meat *model.Meat) error
 	DeleteMeat(string) error
-	ReduceStock(meatID string, qty float64) error
-	IncreaseStock(meatID string, qty float64) error
+	MoveStock(movement *model.StockMovement) error
+	GetStockMovements(meatID string, startDate string, endDate string) ([]*model.StockMovement, error)
+	WithTx(tx *gorm.DB) MeatRepository
 }
 
 type meatRepository struct {
@@ -27,6 +31,11 @@ func NewMeatRepository(db *gorm.DB) MeatRepository {
 	return &meatRepository{db: db}
 }
 
+// WithTx returns a copy of the repository bound to the given database transaction.
+func (r *meatRepository) WithTx(tx *gorm.DB) MeatRepository {
+	return &meatRepository{db: tx}
+}
+
 func (mr *meatRepository) CreateMeat(meat *model.Meat) error {
 	return mr.db.Create(&meat).Error
 }
@@ -63,7 +72,7 @@ func (r *meatRepository) GetMeatByName(name string) (*model.Meat, error) {
 	var meat model.Meat
 	if err := r.db.Where("name = ? AND is_active = ?", name, true).First(&meat).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, fmt.Errorf("not Found")
+			return nil, nil
 		}
 		return nil, err
 	}
@@ -81,18 +90,54 @@ func (r *meatRepository) GetMeatByID(id string) (*model.Meat, error) {
 	return &meat, nil
 }
 
+// GetMeatByIDForUpdate locks the meat row until the surrounding database
+// transaction ends, so its stock cannot change under the caller.
+func (r *meatRepository) GetMeatByIDForUpdate(id string) (*model.Meat, error) {
+	var meat model.Meat
+	if err := r.db.Clauses(clause.Locking{Strength: "UPDATE"}).First(&meat, "id = ? AND is_active = ?", id, true).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &meat, nil
+}
+
 func (r *meatRepository) DeleteMeat(id string) error {
 	return r.db.Model(&model.Meat{}).Where("id = ?", id).Update("is_active", false).Error
 }
 
+// UpdateMeat saves every column except stock, which only MoveStock changes.
 func (r *meatRepository) UpdateMeat(meat *model.Meat) error {
-	return r.db.Save(&meat).Error
+	return r.db.Omit("stock").Save(&meat).Error
 }
 
-func (r *meatRepository) ReduceStock(meatID string, qty float64) error {
-	return r.db.Model(&model.Meat{}).Where("id = ?", meatID).UpdateColumn("stock", gorm.Expr("stock - ?", qty)).Error
+// MoveStock applies movement.Qty to meats.stock and writes the movement to the
+// stock journal. Every stock change goes through here.
+func (r *meatRepository) MoveStock(movement *model.StockMovement) error {
+	if movement.ID == "" {
+		movement.ID = uuid.NewString()
+	}
+	err := r.db.Model(&model.Meat{}).Where("id = ?", movement.MeatID).UpdateColumn("stock", gorm.Expr("COALESCE(stock, 0) + ?", movement.Qty)).Error
+	if err != nil {
+		return err
+	}
+	var meat model.Meat
+	if err := r.db.Select("stock").First(&meat, "id = ?", movement.MeatID).Error; err != nil {
+		return err
+	}
+	movement.StockAfter = meat.Stock
+	movement.CreatedAt = time.Now()
+	return r.db.Create(movement).Error
 }
 
-func (r *meatRepository) IncreaseStock(meatID string, qty float64) error {
-	return r.db.Model(&model.Meat{}).Where("id = ?", meatID).UpdateColumn("stock", gorm.Expr("stock + ?", qty)).Error
+// GetStockMovements returns the journal of one meat between startDate and endDate (inclusive, YYYY-MM-DD).
+func (r *meatRepository) GetStockMovements(meatID string, startDate string, endDate string) ([]*model.StockMovement, error) {
+	var movements []*model.StockMovement
+	err := r.db.Where("meat_id = ? AND DATE(created_at) BETWEEN ? AND ?", meatID, startDate, endDate).
+		Order("created_at ASC").Find(&movements).Error
+	if err != nil {
+		return nil, err
+	}
+	return movements, nil
 }
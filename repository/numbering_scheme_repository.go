@@ -0,0 +1,51 @@
+package repository
+
+import (
+	"errors"
+	model "trackprosto/models"
+
+	"gorm.io/gorm"
+)
+
+type NumberingSchemeRepository interface {
+	GetNumberingScheme(documentType string) (*model.NumberingScheme, error)
+	GetAllNumberingSchemes() ([]*model.NumberingScheme, error)
+	UpdateNumberingScheme(scheme *model.NumberingScheme) error
+	WithTx(tx *gorm.DB) NumberingSchemeRepository
+}
+
+type numberingSchemeRepository struct {
+	db *gorm.DB
+}
+
+func NewNumberingSchemeRepository(db *gorm.DB) NumberingSchemeRepository {
+	return &numberingSchemeRepository{db: db}
+}
+
+// WithTx returns a copy of the repository bound to the given database transaction.
+func (r *numberingSchemeRepository) WithTx(tx *gorm.DB) NumberingSchemeRepository {
+	return &numberingSchemeRepository{db: tx}
+}
+
+func (r *numberingSchemeRepository) GetNumberingScheme(documentType string) (*model.NumberingScheme, error) {
+	var scheme model.NumberingScheme
+	if err := r.db.First(&scheme, "document_type = ?", documentType).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &scheme, nil
+}
+
+func (r *numberingSchemeRepository) GetAllNumberingSchemes() ([]*model.NumberingScheme, error) {
+	var schemes []*model.NumberingScheme
+	if err := r.db.Order("document_type").Find(&schemes).Error; err != nil {
+		return nil, err
+	}
+	return schemes, nil
+}
+
+func (r *numberingSchemeRepository) UpdateNumberingScheme(scheme *model.NumberingScheme) error {
+	return r.db.Save(scheme).Error
+}
@@ -0,0 +1,69 @@
+package repository
+
+import (
+	model "trackprosto/models"
+
+	"gorm.io/gorm"
+)
+
+type PermissionRepository interface {
+	GetAllPermissions() ([]*model.Permission, error)
+	GetAllRolePermissions() (map[string][]string, error)
+	ReplaceRolePermissions(role string, permissions []string) error
+	GetDB() *gorm.DB
+	WithTx(tx *gorm.DB) PermissionRepository
+}
+
+type permissionRepository struct {
+	db *gorm.DB
+}
+
+func NewPermissionRepository(db *gorm.DB) PermissionRepository {
+	return &permissionRepository{db: db}
+}
+
+func (r *permissionRepository) GetDB() *gorm.DB {
+	return r.db
+}
+
+// WithTx returns a copy of the repository bound to the given database transaction.
+func (r *permissionRepository) WithTx(tx *gorm.DB) PermissionRepository {
+	return &permissionRepository{db: tx}
+}
+
+func (r *permissionRepository) GetAllPermissions() ([]*model.Permission, error) {
+	var permissions []*model.Permission
+	if err := r.db.Order("code").Find(&permissions).Error; err != nil {
+		return nil, err
+	}
+	return permissions, nil
+}
+
+// GetAllRolePermissions returns the permission codes granted to each role.
+func (r *permissionRepository) GetAllRolePermissions() (map[string][]string, error) {
+	var grants []*model.RolePermission
+	if err := r.db.Order("role").Order("permission_code").Find(&grants).Error; err != nil {
+		return nil, err
+	}
+	roles := make(map[string][]string)
+	for _, grant := range grants {
+		roles[grant.Role] = append(roles[grant.Role], grant.PermissionCode)
+	}
+	return roles, nil
+}
+
+// ReplaceRolePermissions sets the permissions of a role to exactly the given
+// codes. Callers should run it in a transaction.
+func (r *permissionRepository) ReplaceRolePermissions(role string, permissions []string) error {
+	if err := r.db.Where("role = ?", role).Delete(&model.RolePermission{}).Error; err != nil {
+		return err
+	}
+	if len(permissions) == 0 {
+		return nil
+	}
+	grants := make([]*model.RolePermission, 0, len(permissions))
+	for _, permission := range permissions {
+		grants = append(grants, &model.RolePermission{Role: role, PermissionCode: permission})
+	}
+	return r.db.Create(&grants).Error
+}
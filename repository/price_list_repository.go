@@ -0,0 +1,113 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	model "trackprosto/models"
+
+	"gorm.io/gorm"
+)
+
+type PriceListRepository interface {
+	CreatePriceList(priceList *model.PriceList) error
+	UpdatePriceList(priceList *model.PriceList) error
+	GetPriceListByID(id string) (*model.PriceList, error)
+	GetAllPriceLists(activeOn string) ([]*model.PriceList, error)
+	DeactivatePriceList(id string, updatedBy string) error
+	FindPrice(meatID string, listType string, companyID string, date string) (*model.PriceSuggestion, error)
+	GetDB() *gorm.DB
+	WithTx(tx *gorm.DB) PriceListRepository
+}
+
+type priceListRepository struct {
+	db *gorm.DB
+}
+
+func NewPriceListRepository(db *gorm.DB) PriceListRepository {
+	return &priceListRepository{db: db}
+}
+
+func (r *priceListRepository) GetDB() *gorm.DB {
+	return r.db
+}
+
+// WithTx returns a copy of the repository bound to the given database transaction.
+func (r *priceListRepository) WithTx(tx *gorm.DB) PriceListRepository {
+	return &priceListRepository{db: tx}
+}
+
+func (r *priceListRepository) CreatePriceList(priceList *model.PriceList) error {
+	if err := r.db.Create(priceList).Error; err != nil {
+		return fmt.Errorf("failed to create price list: %w", err)
+	}
+	return nil
+}
+
+// UpdatePriceList saves the price list and replaces its items.
+func (r *priceListRepository) UpdatePriceList(priceList *model.PriceList) error {
+	if err := r.db.Omit("Items").Save(priceList).Error; err != nil {
+		return fmt.Errorf("failed to update price list: %w", err)
+	}
+	if err := r.db.Where("price_list_id = ?", priceList.ID).Delete(&model.PriceListItem{}).Error; err != nil {
+		return err
+	}
+	if len(priceList.Items) == 0 {
+		return nil
+	}
+	return r.db.Create(priceList.Items).Error
+}
+
+func (r *priceListRepository) GetPriceListByID(id string) (*model.PriceList, error) {
+	var priceList model.PriceList
+	err := r.db.Preload("Items").Where("id = ? AND is_active = ?", id, true).First(&priceList).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &priceList, nil
+}
+
+// GetAllPriceLists returns the active price lists, only those valid on
+// activeOn when it is set.
+func (r *priceListRepository) GetAllPriceLists(activeOn string) ([]*model.PriceList, error) {
+	var priceLists []*model.PriceList
+	query := r.db.Preload("Items").Where("is_active = ?", true)
+	if activeOn != "" {
+		query = query.Where("valid_from <= ? AND (valid_to IS NULL OR valid_to >= ?)", activeOn, activeOn)
+	}
+	err := query.Order("list_type ASC").Order("valid_from DESC").Find(&priceLists).Error
+	if err != nil {
+		return nil, err
+	}
+	return priceLists, nil
+}
+
+func (r *priceListRepository) DeactivatePriceList(id string, updatedBy string) error {
+	return r.db.Model(&model.PriceList{}).Where("id = ?", id).
+		Updates(map[string]interface{}{"is_active": false, "updated_by": updatedBy}).Error
+}
+
+// FindPrice returns the price of a meat on the list of the given type valid on
+// date, the most recently started list winning, or nil when no list has it.
+// Contract lists are looked up for companyID.
+func (r *priceListRepository) FindPrice(meatID string, listType string, companyID string, date string) (*model.PriceSuggestion, error) {
+	var suggestions []*model.PriceSuggestion
+	query := r.db.Table("price_list_items").
+		Select("price_list_items.meat_id, price_list_items.price, price_lists.id AS price_list_id, price_lists.name AS price_list_name, price_lists.list_type, price_lists.max_deviation_pct").
+		Joins("JOIN price_lists ON price_lists.id = price_list_items.price_list_id").
+		Where("price_list_items.meat_id = ? AND price_lists.list_type = ? AND price_lists.is_active = ?", meatID, listType, true).
+		Where("price_lists.valid_from <= ? AND (price_lists.valid_to IS NULL OR price_lists.valid_to >= ?)", date, date)
+	if listType == model.PriceListContract {
+		query = query.Where("price_lists.company_id = ?", companyID)
+	}
+	err := query.Order("price_lists.valid_from DESC").Limit(1).Scan(&suggestions).Error
+	if err != nil {
+		return nil, err
+	}
+	if len(suggestions) == 0 {
+		return nil, nil
+	}
+	return suggestions[0], nil
+}
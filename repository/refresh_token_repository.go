@@ -0,0 +1,67 @@
+package repository
+
+import (
+	"errors"
+	"time"
+	model "trackprosto/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type RefreshTokenRepository interface {
+	CreateRefreshToken(token *model.RefreshToken) error
+	GetRefreshTokenByHashForUpdate(tokenHash string) (*model.RefreshToken, error)
+	RevokeRefreshToken(id string, replacedBy string) error
+	RevokeUserRefreshTokens(userID string) error
+	GetDB() *gorm.DB
+	WithTx(tx *gorm.DB) RefreshTokenRepository
+}
+
+type refreshTokenRepository struct {
+	db *gorm.DB
+}
+
+func NewRefreshTokenRepository(db *gorm.DB) RefreshTokenRepository {
+	return &refreshTokenRepository{db: db}
+}
+
+func (r *refreshTokenRepository) GetDB() *gorm.DB {
+	return r.db
+}
+
+// WithTx returns a copy of the repository bound to the given database transaction.
+func (r *refreshTokenRepository) WithTx(tx *gorm.DB) RefreshTokenRepository {
+	return &refreshTokenRepository{db: tx}
+}
+
+func (r *refreshTokenRepository) CreateRefreshToken(token *model.RefreshToken) error {
+	return r.db.Create(token).Error
+}
+
+// GetRefreshTokenByHashForUpdate locks the token so two refreshes with the
+// same token cannot both succeed.
+func (r *refreshTokenRepository) GetRefreshTokenByHashForUpdate(tokenHash string) (*model.RefreshToken, error) {
+	var token model.RefreshToken
+	err := r.db.Clauses(clause.Locking{Strength: "UPDATE"}).First(&token, "token_hash = ?", tokenHash).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &token, nil
+}
+
+func (r *refreshTokenRepository) RevokeRefreshToken(id string, replacedBy string) error {
+	return r.db.Model(&model.RefreshToken{}).
+		Where("id = ? AND revoked_at IS NULL", id).
+		Updates(map[string]interface{}{"revoked_at": time.Now(), "replaced_by": replacedBy}).Error
+}
+
+// RevokeUserRefreshTokens ends every session of a user.
+func (r *refreshTokenRepository) RevokeUserRefreshTokens(userID string) error {
+	return r.db.Model(&model.RefreshToken{}).
+		Where("user_id = ? AND revoked_at IS NULL", userID).
+		Update("revoked_at", time.Now()).Error
+}
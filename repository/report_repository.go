@@ -0,0 +1,135 @@
+package repository
+
+import (
+	model "trackprosto/models"
+
+	"gorm.io/gorm"
+)
+
+type ReportRepository interface {
+	GetMarginLines(groupBy string, startDate string, endDate string) ([]*model.MarginLine, error)
+	GetInvoiceAmountsByPeriod(txType string, column string, granularity string, startDate string, endDate string) ([]*model.PeriodAmount, error)
+	GetOperationalExpendituresByPeriod(granularity string, startDate string, endDate string) ([]*model.PeriodAmount, error)
+	GetOutstandingInvoices(txType string, asOf string) ([]*model.OutstandingInvoice, error)
+	WithTx(tx *gorm.DB) ReportRepository
+}
+
+type reportRepository struct {
+	db *gorm.DB
+}
+
+func NewReportRepository(db *gorm.DB) ReportRepository {
+	return &reportRepository{db: db}
+}
+
+// WithTx returns a copy of the repository bound to the given database transaction.
+func (r *reportRepository) WithTx(tx *gorm.DB) ReportRepository {
+	return &reportRepository{db: tx}
+}
+
+// marginColumns are the key, name and date selected for each margin grouping.
+var marginColumns = map[string][]string{
+	model.MarginByInvoice:  {"h.inv_number", "h.name", "h.date"},
+	model.MarginByMeat:     {"d.meat_id", "d.meat_name", "NULL::DATE"},
+	model.MarginByCustomer: {"h.customer_id", "h.name", "NULL::DATE"},
+}
+
+// lineAmounts split the invoice discount, tax and rounding of an invoice over
+// its details in proportion to their totals. Revenue is net of line and
+// invoice discounts and leaves out the tax; rounding counts as revenue.
+var lineAmounts = map[string]string{
+	"revenue":  "COALESCE(d.total * (h.total - h.tax) / NULLIF(h.subtotal, 0), d.total)",
+	"discount": "d.discount + COALESCE(d.total * h.discount / NULLIF(h.subtotal, 0), 0)",
+	"tax":      "COALESCE(d.total * h.tax / NULLIF(h.subtotal, 0), 0)",
+	"total":    "COALESCE(d.total * h.total / NULLIF(h.subtotal, 0), d.total)",
+	"cogs":     "d.cogs",
+}
+
+// GetMarginLines sums revenue, discounts, tax, grand total and COGS of active
+// "out" invoice details dated between startDate and endDate, grouped by
+// invoice, meat or customer.
+func (r *reportRepository) GetMarginLines(groupBy string, startDate string, endDate string) ([]*model.MarginLine, error) {
+	columns, ok := marginColumns[groupBy]
+	if !ok {
+		columns = marginColumns[model.MarginByInvoice]
+	}
+	key, name, date := columns[0], columns[1], columns[2]
+
+	var lines []*model.MarginLine
+	err := r.db.Table("transaction_details d").
+		Select(key+" AS key, MAX("+name+") AS name, MAX("+date+")::VARCHAR AS date, "+
+			"SUM(d.qty) AS qty, SUM("+lineAmounts["revenue"]+") AS revenue, SUM("+lineAmounts["discount"]+") AS discount, "+
+			"SUM("+lineAmounts["tax"]+") AS tax, SUM("+lineAmounts["total"]+") AS total, SUM(d.cogs) AS cogs, "+
+			"SUM("+lineAmounts["revenue"]+" - d.cogs) AS gross_margin").
+		Joins("JOIN transaction_headers h ON h.id = d.transaction_id").
+		Where("h.tx_type = ? AND h.is_active = ? AND d.is_active = ?", "out", true, true).
+		Where("h.date BETWEEN ? AND ?", startDate, endDate).
+		Group(key).
+		Order("gross_margin DESC").
+		Scan(&lines).Error
+	if err != nil {
+		return nil, err
+	}
+	return lines, nil
+}
+
+// GetInvoiceAmountsByPeriod sums one of the lineAmounts ("revenue",
+// "discount", "tax", "total" or "cogs") of active invoices of one tx type per
+// day, week or month.
+func (r *reportRepository) GetInvoiceAmountsByPeriod(txType string, column string, granularity string, startDate string, endDate string) ([]*model.PeriodAmount, error) {
+	var amounts []*model.PeriodAmount
+	err := r.db.Table("transaction_details d").
+		Select("TO_CHAR(DATE_TRUNC(?, h.date), 'YYYY-MM-DD') AS period, SUM("+lineAmounts[column]+") AS amount", granularity).
+		Joins("JOIN transaction_headers h ON h.id = d.transaction_id").
+		Where("h.tx_type = ? AND h.is_active = ? AND d.is_active = ?", txType, true, true).
+		Where("h.date BETWEEN ? AND ?", startDate, endDate).
+		Group("period").
+		Order("period").
+		Scan(&amounts).Error
+	if err != nil {
+		return nil, err
+	}
+	return amounts, nil
+}
+
+// GetOperationalExpendituresByPeriod sums active daily expenditures per day,
+// week or month, leaving out the ones booked for "in" invoices and their
+// payments (their de_note is the invoice number).
+func (r *reportRepository) GetOperationalExpendituresByPeriod(granularity string, startDate string, endDate string) ([]*model.PeriodAmount, error) {
+	var amounts []*model.PeriodAmount
+	err := r.db.Table("daily_expenditures e").
+		Select("TO_CHAR(DATE_TRUNC(?, e.date), 'YYYY-MM-DD') AS period, SUM(e.amount) AS amount", granularity).
+		Where("e.is_active = ? AND e.date BETWEEN ? AND ?", true, startDate, endDate).
+		Where("NOT EXISTS (SELECT 1 FROM transaction_headers h WHERE h.inv_number = e.de_note)").
+		Group("period").
+		Order("period").
+		Scan(&amounts).Error
+	if err != nil {
+		return nil, err
+	}
+	return amounts, nil
+}
+
+// GetOutstandingInvoices returns the active invoices of one tx type dated on or
+// before asOf that are not fully paid by the credit payments made up to asOf.
+func (r *reportRepository) GetOutstandingInvoices(txType string, asOf string) ([]*model.OutstandingInvoice, error) {
+	paid := r.db.Table("credit_payments cp").
+		Select("cp.inv_number, SUM(cp.amount) AS paid").
+		Where("cp.is_active = ? AND cp.payment_date <= ?", true, asOf).
+		Group("cp.inv_number")
+
+	var invoices []*model.OutstandingInvoice
+	err := r.db.Table("transaction_headers h").
+		Select("h.inv_number, h.customer_id, h.name, h.company, h.date::VARCHAR AS date, "+
+			"COALESCE(h.due_date, h.date)::VARCHAR AS due_date, h.total, "+
+			"COALESCE(p.paid, 0) AS paid, h.total - COALESCE(p.paid, 0) AS outstanding").
+		Joins("LEFT JOIN (?) AS p ON p.inv_number = h.inv_number", paid).
+		Where("h.tx_type = ? AND h.is_active = ? AND h.date <= ?", txType, true, asOf).
+		Where("h.total - COALESCE(p.paid, 0) > 0").
+		Order("due_date ASC").Order("h.inv_number ASC").
+		Scan(&invoices).Error
+	if err != nil {
+		return nil, err
+	}
+	return invoices, nil
+}
@@ -0,0 +1,41 @@
+package repository
+
+import (
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+type SequenceRepository interface {
+	NextValue(prefix string, period string) (int, error)
+	WithTx(tx *gorm.DB) SequenceRepository
+}
+
+type sequenceRepository struct {
+	db *gorm.DB
+}
+
+func NewSequenceRepository(db *gorm.DB) SequenceRepository {
+	return &sequenceRepository{db: db}
+}
+
+// WithTx returns a copy of the repository bound to the given database transaction.
+func (r *sequenceRepository) WithTx(tx *gorm.DB) SequenceRepository {
+	return &sequenceRepository{db: tx}
+}
+
+// NextValue increments and returns the counter of prefix in period. The row
+// stays locked until the surrounding transaction ends, so concurrent callers
+// get consecutive values and a rolled back document gives its number back.
+func (r *sequenceRepository) NextValue(prefix string, period string) (int, error) {
+	var value int
+	err := r.db.Raw(`INSERT INTO document_sequences (prefix, period, last_value, updated_at)
+		VALUES (?, ?, 1, CURRENT_TIMESTAMP)
+		ON CONFLICT (prefix, period)
+		DO UPDATE SET last_value = document_sequences.last_value + 1, updated_at = CURRENT_TIMESTAMP
+		RETURNING last_value`, prefix, period).Scan(&value).Error
+	if err != nil {
+		return 0, fmt.Errorf("failed to allocate %s number: %w", prefix, err)
+	}
+	return value, nil
+}
@@ -0,0 +1,126 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	model "trackprosto/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type StockOpnameRepository interface {
+	CreateStockOpname(opname *model.StockOpname) error
+	UpdateStockOpname(opname *model.StockOpname) error
+	GetStockOpnameByID(id string) (*model.StockOpname, error)
+	GetStockOpnameByIDForUpdate(id string) (*model.StockOpname, error)
+	GetOpenStockOpname() (*model.StockOpname, error)
+	LockStockOpnames() error
+	GetAllStockOpnames() ([]*model.StockOpname, error)
+	SaveStockOpnameItem(item *model.StockOpnameItem) error
+	GetDB() *gorm.DB
+	WithTx(tx *gorm.DB) StockOpnameRepository
+}
+
+type stockOpnameRepository struct {
+	db *gorm.DB
+}
+
+func NewStockOpnameRepository(db *gorm.DB) StockOpnameRepository {
+	return &stockOpnameRepository{
+		db: db,
+	}
+}
+
+// WithTx returns a copy of the repository bound to the given database transaction.
+func (repo *stockOpnameRepository) WithTx(tx *gorm.DB) StockOpnameRepository {
+	return &stockOpnameRepository{db: tx}
+}
+
+func (repo *stockOpnameRepository) GetDB() *gorm.DB {
+	return repo.db
+}
+
+func (repo *stockOpnameRepository) CreateStockOpname(opname *model.StockOpname) error {
+	if err := repo.db.Omit("Items").Create(opname).Error; err != nil {
+		return fmt.Errorf("failed to create stock opname: %w", err)
+	}
+	return nil
+}
+
+func (repo *stockOpnameRepository) UpdateStockOpname(opname *model.StockOpname) error {
+	if err := repo.db.Omit("Items").Save(opname).Error; err != nil {
+		return fmt.Errorf("failed to update stock opname: %w", err)
+	}
+	return nil
+}
+
+func (repo *stockOpnameRepository) GetStockOpnameByID(id string) (*model.StockOpname, error) {
+	var opname model.StockOpname
+	err := repo.db.Preload("Items", func(db *gorm.DB) *gorm.DB {
+		return db.Order("meat_name ASC")
+	}).First(&opname, "id = ?", id).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &opname, nil
+}
+
+// GetStockOpnameByIDForUpdate locks the session row until the surrounding
+// database transaction ends and returns it with its items.
+func (repo *stockOpnameRepository) GetStockOpnameByIDForUpdate(id string) (*model.StockOpname, error) {
+	var locked model.StockOpname
+	err := repo.db.Clauses(clause.Locking{Strength: "UPDATE"}).First(&locked, "id = ?", id).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return repo.GetStockOpnameByID(id)
+}
+
+func (repo *stockOpnameRepository) GetOpenStockOpname() (*model.StockOpname, error) {
+	var opname model.StockOpname
+	err := repo.db.Where("status = ?", model.StockOpnameOpen).First(&opname).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &opname, nil
+}
+
+// LockStockOpnames keeps other transactions from starting a session until the
+// surrounding database transaction ends. Reads are not blocked.
+func (repo *stockOpnameRepository) LockStockOpnames() error {
+	if err := repo.db.Exec("LOCK TABLE stock_opnames IN SHARE ROW EXCLUSIVE MODE").Error; err != nil {
+		return fmt.Errorf("failed to lock stock opnames: %w", err)
+	}
+	return nil
+}
+
+func (repo *stockOpnameRepository) GetAllStockOpnames() ([]*model.StockOpname, error) {
+	var opnames []*model.StockOpname
+	if err := repo.db.Order("started_at desc").Find(&opnames).Error; err != nil {
+		return nil, fmt.Errorf("failed to get stock opnames: %w", err)
+	}
+	return opnames, nil
+}
+
+// SaveStockOpnameItem inserts the count of a meat or replaces the one already
+// entered in the same session.
+func (repo *stockOpnameRepository) SaveStockOpnameItem(item *model.StockOpnameItem) error {
+	err := repo.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "opname_id"}, {Name: "meat_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"meat_name", "system_stock", "counted_stock", "variance", "reason_code", "notes", "counted_at", "counted_by"}),
+	}).Create(item).Error
+	if err != nil {
+		return fmt.Errorf("failed to save stock opname item: %w", err)
+	}
+	return nil
+}
@@ -6,6 +6,7 @@ import (
 	model "trackprosto/models"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type TransactionRepository interface {
@@ -13,9 +14,10 @@ type TransactionRepository interface {
 	GetTransactionByID(id string) (*model.TransactionHeader, error)
 	GetTransactionByRangeDate(startDate time.Time, endDate time.Time) ([]*model.TransactionHeader, error)
 	GetAllTransactions(page int, itemsPerPage int) ([]*model.TransactionHeader, int, error)
-	DeleteTransaction(id string) error
-	CountTransactions() (int, error)
+	GetTransactionByIDForUpdate(id string) (*model.TransactionHeader, error)
+	VoidTransaction(id string, voidedBy string, reason string) error
 	GetByInvoiceNumber(invoice_number string) (*model.TransactionHeader, error)
+	GetByInvoiceNumberForUpdate(invoice_number string) (*model.TransactionHeader, error)
 	UpdateStatusInvoicePaid(id string) error
 	UpdateStatusPaymentAmount(id string, total float64) error
 	GetTransactionByRangeDateWithTxType(startDate time.Time, endDate time.Time, tx_type string) ([]*model.TransactionHeader, error)
@@ -25,9 +27,10 @@ type TransactionRepository interface {
 	getCustomerDebt(customer_id string) (float64, error)
 	getTransactionDebt(id string) (float64, error)
 	CalculateMeatStockByDate(meatID string, startDate string) (stockIn float64, stockOut float64, err error)
-	UpdateCustomerDebt(id string, additionalDebt float64) error
 	GetDB() *gorm.DB
+	StreamTransactions(filter *model.ExportFilter, fn func(*model.TransactionHeader) error) error
 	UpdateDebtTransaction(id string, total float64) error
+	WithTx(tx *gorm.DB) TransactionRepository
 }
 
 type transactionRepository struct {
@@ -85,6 +88,11 @@ func NewTransactionRepository(db *gorm.DB) TransactionRepository {
 	}
 }
 
+// WithTx returns a copy of the repository bound to the given database transaction.
+func (repo *transactionRepository) WithTx(tx *gorm.DB) TransactionRepository {
+	return &transactionRepository{db: tx}
+}
+
 // GetAllTransactionsByCustomerUsername implements TransactionRepository.
 func (repo *transactionRepository) GetAllTransactionsByCustomerId(customer_id string,page int, itemsPerPage int ) ([]*model.TransactionHeader, int, error) {
 	var transactions []*model.TransactionHeader
@@ -171,14 +179,40 @@ func (repo *transactionRepository) GetAllTransactions(page int, itemsPerPage int
 	return transactions, totalPages, nil
 }
 
-func (repo *transactionRepository) DeleteTransaction(id string) error {
-	transaction := model.TransactionHeader{ID: id}
+// GetTransactionByIDForUpdate locks the active header row until the surrounding
+// database transaction ends and returns it with its details.
+func (repo *transactionRepository) GetTransactionByIDForUpdate(id string) (*model.TransactionHeader, error) {
+	var locked model.TransactionHeader
+	if err := repo.db.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ? AND is_active = true", id).First(&locked).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return repo.GetTransactionByID(id)
+}
 
-	if err := repo.db.Model(&transaction).Update("is_active", false).Error; err != nil {
+// VoidTransaction deactivates the header and its details and records who voided it and why.
+func (repo *transactionRepository) VoidTransaction(id string, voidedBy string, reason string) error {
+	now := time.Now()
+	err := repo.db.Model(&model.TransactionHeader{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"is_active":   false,
+		"voided_at":   now,
+		"voided_by":   voidedBy,
+		"void_reason": reason,
+		"updated_at":  now,
+		"updated_by":  voidedBy,
+	}).Error
+	if err != nil {
 		return err
 	}
 
-	return nil
+	return repo.db.Model(&model.TransactionDetail{}).Where("transaction_id = ?", id).Updates(map[string]interface{}{
+		"is_active":  false,
+		"updated_at": now,
+		"updated_by": voidedBy,
+	}).Error
 }
 
 func (repo *transactionRepository) GetTransactionByRangeDate(startDate time.Time, endDate time.Time) ([]*model.TransactionHeader, error) {
@@ -206,24 +240,24 @@ func (repo *transactionRepository) GetByInvoiceNumber(invoice_number string) (*m
 	return &transaction, nil
 }
 
-func (repo *transactionRepository) UpdateStatusInvoicePaid(id string) error {
-	return repo.db.Model(&model.TransactionHeader{}).Where("id = ?", id).Update("payment_status", "paid").Error
-}
-
-func (repo *transactionRepository) UpdateCustomerDebt(id string, additionalDebt float64) error {
-	var customer model.CustomerModel
+// GetByInvoiceNumberForUpdate locks the header row with SELECT ... FOR UPDATE
+// until the surrounding database transaction ends.
+func (repo *transactionRepository) GetByInvoiceNumberForUpdate(invoice_number string) (*model.TransactionHeader, error) {
+	var transaction model.TransactionHeader
 
-	if err := repo.db.Where("id = ?", id).First(&customer).Error; err != nil {
-		return err
+	err := repo.db.Clauses(clause.Locking{Strength: "UPDATE"}).Where("inv_number = ? AND is_active = true", invoice_number).First(&transaction).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
 	}
 
-	currentDebt := customer.Debt
+	return &transaction, nil
+}
 
-	newDebt := currentDebt + additionalDebt
-	if err := repo.db.Model(&customer).Update("debt", newDebt).Error; err != nil {
-		return err
-	}
-	return nil
+func (repo *transactionRepository) UpdateStatusInvoicePaid(id string) error {
+	return repo.db.Model(&model.TransactionHeader{}).Where("id = ?", id).Update("payment_status", "paid").Error
 }
 
 func (repo *transactionRepository) UpdateStatusPaymentAmount(id string, total float64) error {
@@ -245,16 +279,6 @@ func (repo *transactionRepository) GetTransactionByRangeDateWithTxType(startDate
 	return transactions, nil
 }
 
-func (repo *transactionRepository) CountTransactions() (int, error) {
-	var count int64
-	today := time.Now()
-	err := repo.db.Where("date = ? ", today.Format("2006-01-02")).Model(&model.TransactionHeader{}).Count(&count).Error
-	if err != nil {
-		return 0, err
-	}
-	return int(count), nil
-}
-
 func (repo *transactionRepository) GetTransactionByRangeDateWithTxTypeAndPaid(startDate time.Time, endDate time.Time, tx_type, payment_status string) ([]*model.TransactionHeader, error) {
 	var transactions []*model.TransactionHeader
 
@@ -287,3 +311,36 @@ func (repo *transactionRepository) getTransactionDebt(id string) (float64, error
 
 	return transaction.Total - transaction.PaymentAmount, nil
 }
+
+// StreamTransactions calls fn for every active invoice matching the filter, in
+// date order, reading them from the database one at a time.
+func (repo *transactionRepository) StreamTransactions(filter *model.ExportFilter, fn func(*model.TransactionHeader) error) error {
+	query := repo.db.Model(&model.TransactionHeader{}).Where("is_active = ?", true)
+	if filter.StartDate != "" {
+		query = query.Where("date >= ?", filter.StartDate)
+	}
+	if filter.EndDate != "" {
+		query = query.Where("date <= ?", filter.EndDate)
+	}
+	if filter.TxType != "" {
+		query = query.Where("tx_type = ?", filter.TxType)
+	}
+	if filter.PaymentStatus != "" {
+		query = query.Where("payment_status = ?", filter.PaymentStatus)
+	}
+	rows, err := query.Order("date ASC").Order("inv_number ASC").Rows()
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var transaction model.TransactionHeader
+		if err := repo.db.ScanRows(rows, &transaction); err != nil {
+			return err
+		}
+		if err := fn(&transaction); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
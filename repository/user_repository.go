@@ -17,6 +17,9 @@ type UserRepository interface {
 	DeleteUser(id string) error
 	GetByUsername(username string) (*model.User, error)
 	CountUsers(username string) (int, error)
+	GetTokenVersion(userID string) (int, bool, error)
+	GetDB() *gorm.DB
+	WithTx(tx *gorm.DB) UserRepository
 }
 
 type userRepository struct {
@@ -27,6 +30,15 @@ func NewUserRepository(db *gorm.DB) UserRepository {
 	return &userRepository{db: db}
 }
 
+func (r *userRepository) GetDB() *gorm.DB {
+	return r.db
+}
+
+// WithTx returns a copy of the repository bound to the given database transaction.
+func (r *userRepository) WithTx(tx *gorm.DB) UserRepository {
+	return &userRepository{db: tx}
+}
+
 func (r *userRepository) CountUsers(username string) (int, error) {
     var count int64
     if err := r.db.Model(&model.User{}).Where("is_active = true AND username = ?", username).Count(&count).Error; err != nil {
@@ -80,6 +92,25 @@ func (r *userRepository) GetAllUsers() ([]*model.User, error) {
 	return users, nil
 }
 
+// DeleteUser deactivates a user and bumps the token version so the tokens
+// already issued to them stop working.
 func (r *userRepository) DeleteUser(username string) error {
-	return r.db.Model(&model.User{}).Where("username = ?", username).Update("is_active", false).Error
+	return r.db.Model(&model.User{}).Where("username = ?", username).Updates(map[string]interface{}{
+		"is_active":     false,
+		"token_version": gorm.Expr("token_version + 1"),
+	}).Error
+}
+
+// GetTokenVersion returns the token version of an active user; ok is false
+// when the user is inactive or deleted.
+func (r *userRepository) GetTokenVersion(userID string) (int, bool, error) {
+	var user model.User
+	err := r.db.Select("token_version").First(&user, "id = ? AND is_active = true", userID).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return 0, false, nil
+		}
+		return 0, false, err
+	}
+	return user.TokenVersion, true, nil
 }
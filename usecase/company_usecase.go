@@ -1,12 +1,17 @@
 package usecase
 
 import (
+	"fmt"
+	"io"
+	"strings"
 	"trackprosto/delivery/utils"
 	model "trackprosto/models"
 	"trackprosto/models/dto"
 	"trackprosto/repository"
 
+	"github.com/google/uuid"
 	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
 )
 
 type CompanyUseCase interface {
@@ -15,6 +20,7 @@ type CompanyUseCase interface {
 	GetCompanyById(string) (*model.Company, error)
 	GetAllCompany() ([]*model.Company, error)
 	DeleteCompany(string) error
+	ImportCompanies(file io.Reader, dryRun bool, importedBy string) (*model.ImportResult, error)
 }
 
 type companyUseCase struct {
@@ -37,6 +43,9 @@ func (cu *companyUseCase) CreateCompany(company *model.Company) error {
 		logrus.WithField("error", err).Error("Company name already exists")
 		return utils.ErrCompanyNameAlreadyExist
 	}
+	if !isValidTaxRate(company.TaxRate) {
+		return utils.ErrInvalidTaxRate
+	}
 	err = cu.companyRepo.CreateCompany(company)
 	return err
 }
@@ -64,6 +73,12 @@ func (cu *companyUseCase) UpdateCompany(companyRequest *dto.CompanyRequest) (*dt
 	currentCompany.CompanyName = utils.NonEmpty(companyRequest.CompanyName, currentCompany.CompanyName)
 	currentCompany.Email = utils.NonEmpty(companyRequest.Email, currentCompany.Email)
 	currentCompany.PhoneNumber = utils.NonEmpty(companyRequest.PhoneNumber, currentCompany.PhoneNumber)
+	if companyRequest.TaxRate != nil {
+		if !isValidTaxRate(*companyRequest.TaxRate) {
+			return nil, utils.ErrInvalidTaxRate
+		}
+		currentCompany.TaxRate = *companyRequest.TaxRate
+	}
 	err = cu.companyRepo.UpdateCompany(currentCompany)
 	if err != nil {
 		return nil, err
@@ -75,6 +90,7 @@ func (cu *companyUseCase) UpdateCompany(companyRequest *dto.CompanyRequest) (*dt
 		Address:     currentCompany.Address,
 		Email:       currentCompany.Email,
 		PhoneNumber: currentCompany.PhoneNumber,
+		TaxRate:     currentCompany.TaxRate,
 	}
 
 	return companyResponse, nil
@@ -111,3 +127,73 @@ func (cu *companyUseCase) DeleteCompany(id string) error {
 	}
 	return nil
 }
+
+// ImportCompanies creates the companies listed in a CSV file with the columns
+// company_name, address, email, phone_number and tax_rate, of which only
+// company_name is required. Nothing is stored unless every row is valid.
+func (cu *companyUseCase) ImportCompanies(file io.Reader, dryRun bool, importedBy string) (*model.ImportResult, error) {
+	rows, err := readImportFile(file, "company_name")
+	if err != nil {
+		return nil, err
+	}
+
+	result := &model.ImportResult{DryRun: dryRun, Rows: len(rows)}
+	companies := make([]*model.Company, 0, len(rows))
+	seen := make(map[string]int)
+	for _, row := range rows {
+		company := &model.Company{
+			ID:          uuid.NewString(),
+			CompanyName: row.required("company_name", result),
+			Address:     row.get("address"),
+			Email:       row.get("email"),
+			PhoneNumber: row.get("phone_number"),
+			TaxRate:     row.float("tax_rate", result),
+			IsActive:    true,
+			CreatedBy:   importedBy,
+			UpdatedBy:   importedBy,
+		}
+		if company.CompanyName != "" {
+			if line, ok := seen[strings.ToLower(company.CompanyName)]; ok {
+				result.AddError(row.line, "company_name", fmt.Sprintf("%s is already on line %d", company.CompanyName, line))
+			} else {
+				existing, err := cu.companyRepo.GetCompanyByName(company.CompanyName)
+				if err != nil {
+					return nil, err
+				}
+				if existing != nil {
+					result.AddError(row.line, "company_name", utils.ErrCompanyNameAlreadyExist.Error())
+				}
+			}
+			seen[strings.ToLower(company.CompanyName)] = row.line
+		}
+		if !isValidTaxRate(company.TaxRate) {
+			result.AddError(row.line, "tax_rate", utils.ErrInvalidTaxRate.Error())
+		}
+		companies = append(companies, company)
+	}
+	if dryRun || len(result.Errors) > 0 {
+		return result, nil
+	}
+
+	err = cu.companyRepo.GetDB().Transaction(func(tx *gorm.DB) error {
+		companyRepo := cu.companyRepo.WithTx(tx)
+		for _, company := range companies {
+			if err := companyRepo.CreateCompany(company); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		logrus.WithField("error", err).Error("Failed to import companies")
+		return nil, err
+	}
+	result.Imported = len(companies)
+	return result, nil
+}
+
+// isValidTaxRate reports whether rate is a usable PPN percentage. Zero means
+// the company's invoices carry no tax.
+func isValidTaxRate(rate float64) bool {
+	return rate >= 0 && rate <= 100
+}
@@ -1,6 +1,7 @@
 package usecase
 
 import (
+	"math"
 	"time"
 	"trackprosto/delivery/utils"
 	model "trackprosto/models"
@@ -8,6 +9,7 @@ import (
 
 	"github.com/google/uuid"
 	log "github.com/sirupsen/logrus"
+	"gorm.io/gorm"
 )
 
 type CreditPaymentUseCase interface {
@@ -21,22 +23,52 @@ type creditPaymentUseCase struct {
 	creditPaymentRepo    repository.CreditPaymentRepository
 	transactionRepo      repository.TransactionRepository
 	dailyExpenditureRepo repository.DailyExpenditureRepository
+	customerLedgerRepo   repository.CustomerLedgerRepository
+	dayClosingRepo       repository.DayClosingRepository
 }
 
-func NewCreditPaymentUseCase(creditPaymentRepo repository.CreditPaymentRepository, transactionRepo repository.TransactionRepository, dailyExpenditureRepo repository.DailyExpenditureRepository) CreditPaymentUseCase {
+func NewCreditPaymentUseCase(creditPaymentRepo repository.CreditPaymentRepository, transactionRepo repository.TransactionRepository, dailyExpenditureRepo repository.DailyExpenditureRepository, customerLedgerRepo repository.CustomerLedgerRepository, dayClosingRepo repository.DayClosingRepository) CreditPaymentUseCase {
 	return &creditPaymentUseCase{
-		creditPaymentRepo: creditPaymentRepo,
-		transactionRepo:   transactionRepo,
+		creditPaymentRepo:    creditPaymentRepo,
+		transactionRepo:      transactionRepo,
 		dailyExpenditureRepo: dailyExpenditureRepo,
+		customerLedgerRepo:   customerLedgerRepo,
+		dayClosingRepo:       dayClosingRepo,
 	}
 }
 
 func (uc *creditPaymentUseCase) CreateCreditPayment(payment *model.CreditPayment) (*model.CreditPaymentResponse, error) {
+	var creditPaymentResponse *model.CreditPaymentResponse
+	err := uc.transactionRepo.GetDB().Transaction(func(tx *gorm.DB) error {
+		var err error
+		creditPaymentResponse, err = uc.createCreditPayment(tx, payment)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return creditPaymentResponse, nil
+}
+
+// createCreditPayment posts one installment while holding a row lock on the
+// invoice header, so concurrent installments on the same invoice are applied
+// one after another and cannot overpay it.
+func (uc *creditPaymentUseCase) createCreditPayment(tx *gorm.DB, payment *model.CreditPayment) (*model.CreditPaymentResponse, error) {
+	if payment.Amount <= 0 {
+		log.WithFields(log.Fields{
+			"invoiceNumber": payment.InvoiceNumber,
+			"amount":        payment.Amount,
+		}).Error("Amount must be positive")
+		return nil, utils.ErrInvalidAmount
+	}
 
-	tx := uc.transactionRepo.GetDB().Begin() // Start a transaction
-	defer tx.Rollback()
+	transactionRepo := uc.transactionRepo.WithTx(tx)
+	creditPaymentRepo := uc.creditPaymentRepo.WithTx(tx)
+	dailyExpenditureRepo := uc.dailyExpenditureRepo.WithTx(tx)
+	customerLedgerRepo := uc.customerLedgerRepo.WithTx(tx)
 
-	transaction, err := uc.transactionRepo.GetByInvoiceNumber(payment.InvoiceNumber)
+	transaction, err := transactionRepo.GetByInvoiceNumberForUpdate(payment.InvoiceNumber)
 	if err != nil {
 		log.WithFields(log.Fields{
 			"invoiceNumber": payment.InvoiceNumber,
@@ -48,12 +80,44 @@ func (uc *creditPaymentUseCase) CreateCreditPayment(payment *model.CreditPayment
 		log.WithField("invoiceNumber", payment.InvoiceNumber).Error("Invoice not found")
 		return nil, utils.ErrInvoiceNumberNotExist
 	}
+
+	// A retried request carrying an already used key gets the original
+	// installment back instead of recording it twice.
+	if payment.IdempotencyKey != "" {
+		existingPayment, err := creditPaymentRepo.GetCreditPaymentByIdempotencyKey(payment.IdempotencyKey)
+		if err != nil {
+			log.WithFields(log.Fields{
+				"invoiceNumber":  payment.InvoiceNumber,
+				"idempotencyKey": payment.IdempotencyKey,
+				"error":          err,
+			}).Error("Failed to get credit payment by idempotency key")
+			return nil, err
+		}
+		if existingPayment != nil {
+			if existingPayment.InvoiceNumber != payment.InvoiceNumber {
+				return nil, utils.ErrIdempotencyKeyReused
+			}
+			log.WithFields(log.Fields{
+				"invoiceNumber":  payment.InvoiceNumber,
+				"idempotencyKey": payment.IdempotencyKey,
+			}).Info("Credit payment already recorded, returning the original")
+			transaction, err = transactionRepo.GetByInvoiceNumber(payment.InvoiceNumber)
+			if err != nil {
+				return nil, err
+			}
+			return &model.CreditPaymentResponse{
+				Transaction:   transaction,
+				CreditPayment: existingPayment,
+			}, nil
+		}
+	}
+
 	if transaction.PaymentStatus == "paid" {
 		log.WithField("invoiceNumber", payment.InvoiceNumber).Error("Invoice has already been paid.")
 		return nil, utils.ErrInvoiceAlreadyPaid
 	}
 
-	CountCreditPayments, err := uc.creditPaymentRepo.CountCreditPayments(payment.InvoiceNumber)
+	CountCreditPayments, err := creditPaymentRepo.CountCreditPayments(payment.InvoiceNumber)
 	if err != nil {
 		log.WithFields(log.Fields{
 			"invoiceNumber": payment.InvoiceNumber,
@@ -65,16 +129,19 @@ func (uc *creditPaymentUseCase) CreateCreditPayment(payment *model.CreditPayment
 
 	createdat := time.Now()
 	todayDate := time.Now().Format("2006-01-02")
+	if err := ensureDayOpen(uc.dayClosingRepo.WithTx(tx), todayDate); err != nil {
+		return nil, err
+	}
 	payment.ID = uuid.NewString()
 	payment.PaymentDate = todayDate
 	payment.CreatedAt = createdat
 	payment.UpdatedAt = createdat
 	payment.UpdatedBy = payment.CreatedBy
+	payment.IsActive = true
 	payment.Notes = utils.NumberToOrdinal(totalcount+1) + " Installment"
 
-	totalCredit, err := uc.creditPaymentRepo.GetTotalCredit(payment.InvoiceNumber)
+	totalCredit, err := creditPaymentRepo.GetTotalCredit(payment.InvoiceNumber)
 	if err != nil {
-		tx.Rollback() // Rollback in case of error
 		log.WithFields(log.Fields{
 			"invoiceNumber": payment.InvoiceNumber,
 			"error":         err,
@@ -82,11 +149,21 @@ func (uc *creditPaymentUseCase) CreateCreditPayment(payment *model.CreditPayment
 		return nil, err
 	}
 	totalAmountAfterCredit := totalCredit + payment.Amount
-	if totalAmountAfterCredit >= transaction.Total {
+	// Installments are summed as floats; what is left is compared to the
+	// rupiah so fractions of one neither refuse nor leave open the last payment.
+	remaining := math.Round(transaction.Total - totalAmountAfterCredit)
+	if remaining < 0 {
+		log.WithFields(log.Fields{
+			"invoiceNumber": payment.InvoiceNumber,
+			"totalCredit":   totalCredit,
+			"amount":        payment.Amount,
+		}).Error("Amount greater than total")
+		return nil, utils.ErrAmountGreaterThanTotal
+	}
+	if remaining == 0 {
 		payment.Notes = "Settled"
-		err = uc.transactionRepo.UpdateStatusInvoicePaid(transaction.ID)
+		err = transactionRepo.UpdateStatusInvoicePaid(transaction.ID)
 		if err != nil {
-			tx.Rollback() // Rollback in case of error
 			log.WithFields(log.Fields{
 				"invoiceNumber": payment.InvoiceNumber,
 				"error":         err,
@@ -94,57 +171,75 @@ func (uc *creditPaymentUseCase) CreateCreditPayment(payment *model.CreditPayment
 			return nil, err
 		}
 	}
-	if totalAmountAfterCredit > transaction.Total {
-		tx.Rollback() // Rollback in case of error
-		return nil, utils.ErrAmountGreaterThanTotal
-	}
 
 	if transaction.TxType == "in" {
-		uc.dailyExpenditureRepo.CreateDailyExpenditure(&model.DailyExpenditure{
-			ID:         uuid.NewString(),
-			Date:       todayDate,
-			DeNote:     payment.InvoiceNumber,
-			Amount:     payment.Amount,
-			IsActive:   true,
-			CreatedAt:  createdat,
-			UpdatedAt:  createdat,
-			CreatedBy:  payment.CreatedBy,
-			UpdatedBy:  payment.CreatedBy,
+		err = dailyExpenditureRepo.CreateDailyExpenditure(&model.DailyExpenditure{
+			ID:          uuid.NewString(),
+			Date:        todayDate,
+			DeNote:      payment.InvoiceNumber,
+			Amount:      payment.Amount,
+			IsActive:    true,
+			CreatedAt:   createdat,
+			UpdatedAt:   createdat,
+			CreatedBy:   payment.CreatedBy,
+			UpdatedBy:   payment.CreatedBy,
 			Description: payment.Notes,
 		})
+		if err != nil {
+			log.WithFields(log.Fields{
+				"invoiceNumber": payment.InvoiceNumber,
+				"error":         err,
+			}).Error("Failed to create daily expenditure")
+			return nil, err
+		}
 	}
 
-	err = uc.creditPaymentRepo.CreateCreditPayment(payment)
+	err = creditPaymentRepo.CreateCreditPayment(payment)
 	if err != nil {
-		tx.Rollback() // Rollback in case of error
 		log.WithFields(log.Fields{
 			"invoiceNumber": payment.InvoiceNumber,
 			"error":         err,
 		}).Error("Failed to create credit payment")
 		return nil, err
 	}
-
-	newDebt := transaction.Total - totalAmountAfterCredit
-	uc.transactionRepo.UpdateStatusPaymentAmount(transaction.ID, totalAmountAfterCredit)
-	uc.transactionRepo.UpdateDebtTransaction(transaction.ID, newDebt)
-
-	// Update the transaction after the payment amount update
-	transaction.PaymentAmount = totalCredit
-	transaction.Debt = newDebt
-
-	creditPaymentResponse := &model.CreditPaymentResponse{
-		Transaction:   transaction,
-		CreditPayment: payment,
+	err = customerLedgerRepo.CreateEntry(newPaymentLedgerEntry(transaction, payment))
+	if err != nil {
+		log.WithFields(log.Fields{
+			"invoiceNumber": payment.InvoiceNumber,
+			"error":         err,
+		}).Error("Failed to book payment on customer ledger")
+		return nil, err
 	}
 
-	if err := tx.Commit().Error; err != nil {
+	newDebt := remaining
+	err = transactionRepo.UpdateStatusPaymentAmount(transaction.ID, totalAmountAfterCredit)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"invoiceNumber": payment.InvoiceNumber,
+			"error":         err,
+		}).Error("Failed to update payment amount")
+		return nil, err
+	}
+	err = transactionRepo.UpdateDebtTransaction(transaction.ID, newDebt)
+	if err != nil {
 		log.WithFields(log.Fields{
 			"invoiceNumber": payment.InvoiceNumber,
 			"error":         err,
-		}).Error("Failed to complete the transaction")
+		}).Error("Failed to update transaction debt")
 		return nil, err
 	}
 
+	// Reload the invoice with its details after the payment amount update
+	transaction, err = transactionRepo.GetByInvoiceNumber(payment.InvoiceNumber)
+	if err != nil {
+		return nil, err
+	}
+
+	creditPaymentResponse := &model.CreditPaymentResponse{
+		Transaction:   transaction,
+		CreditPayment: payment,
+	}
+
 	return creditPaymentResponse, nil
 }
 
@@ -169,13 +264,21 @@ func (uc *creditPaymentUseCase) GetCreditPaymentByID(id string) (*model.CreditPa
 }
 
 func (uc *creditPaymentUseCase) UpdateCreditPayment(payment *model.CreditPayment) error {
+	return uc.transactionRepo.GetDB().Transaction(func(tx *gorm.DB) error {
+		creditPaymentRepo := uc.creditPaymentRepo.WithTx(tx)
+		existingPayment, err := creditPaymentRepo.GetCreditPaymentByID(payment.ID)
+		if err != nil {
+			return err
+		}
+		if existingPayment == nil {
+			return utils.ErrCreditPaymentNotFound
+		}
+		if err := ensureDayOpen(uc.dayClosingRepo.WithTx(tx), existingPayment.PaymentDate, payment.PaymentDate); err != nil {
+			return err
+		}
 
-	err := uc.creditPaymentRepo.UpdateCreditPayment(payment)
-	if err != nil {
-		return err
-	}
-
-	return nil
+		return creditPaymentRepo.UpdateCreditPayment(payment)
+	})
 }
 
 func (uc *creditPaymentUseCase) GetCreditPaymentsByInvoiceNumber(inv_number string) ([]*model.CreditPayment, error) {
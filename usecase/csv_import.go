@@ -0,0 +1,106 @@
+package usecase
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"trackprosto/delivery/utils"
+	model "trackprosto/models"
+)
+
+// importRow is one data row of an imported CSV file, by lower case column name.
+type importRow struct {
+	line   int
+	values map[string]string
+}
+
+// readImportFile reads a CSV file whose first line names the columns. Every
+// required column must be present, other columns are optional.
+func readImportFile(r io.Reader, required ...string) ([]*importRow, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, utils.ErrInvalidImportFile
+	}
+	for i, column := range header {
+		header[i] = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(column, "\ufeff")))
+	}
+	for _, column := range required {
+		if !slices.Contains(header, column) {
+			return nil, utils.ErrInvalidImportFile
+		}
+	}
+
+	var rows []*importRow
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, utils.ErrInvalidImportFile
+		}
+		line, _ := reader.FieldPos(0)
+		row := &importRow{line: line, values: make(map[string]string, len(header))}
+		empty := true
+		for i, value := range record {
+			if i < len(header) {
+				row.values[header[i]] = strings.TrimSpace(value)
+				empty = empty && row.values[header[i]] == ""
+			}
+		}
+		if !empty {
+			rows = append(rows, row)
+		}
+	}
+	return rows, nil
+}
+
+func (row *importRow) get(column string) string {
+	return row.values[column]
+}
+
+// required returns the value of a column, reporting it when it is empty.
+func (row *importRow) required(column string, result *model.ImportResult) string {
+	value := row.get(column)
+	if value == "" {
+		result.AddError(row.line, column, column+" is required")
+	}
+	return value
+}
+
+// float returns the non-negative finite number in a column, 0 when it is empty.
+func (row *importRow) float(column string, result *model.ImportResult) float64 {
+	value := row.get(column)
+	if value == "" {
+		return 0
+	}
+	number, err := strconv.ParseFloat(value, 64)
+	if err != nil || number < 0 || math.IsNaN(number) || math.IsInf(number, 0) {
+		result.AddError(row.line, column, fmt.Sprintf("%s must be a number not below 0, got %q", column, value))
+		return 0
+	}
+	return number
+}
+
+// int returns the non-negative whole number in a column, 0 when it is empty.
+func (row *importRow) int(column string, result *model.ImportResult) int {
+	value := row.get(column)
+	if value == "" {
+		return 0
+	}
+	number, err := strconv.Atoi(value)
+	if err != nil || number < 0 {
+		result.AddError(row.line, column, fmt.Sprintf("%s must be a whole number not below 0, got %q", column, value))
+		return 0
+	}
+	return number
+}
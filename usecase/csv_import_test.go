@@ -0,0 +1,111 @@
+package usecase
+
+import (
+	"strings"
+	"testing"
+	"trackprosto/delivery/utils"
+	model "trackprosto/models"
+)
+
+func TestReadImportFile(t *testing.T) {
+	tests := []struct {
+		name      string
+		file      string
+		wantErr   error
+		wantLines []int
+		wantNames []string
+	}{
+		{
+			name:      "header normalized",
+			file:      "\ufeff Name ,PRICE\nSirloin,150000\nTenderloin, 175000\n",
+			wantLines: []int{2, 3},
+			wantNames: []string{"Sirloin", "Tenderloin"},
+		},
+		{
+			name:      "blank rows skipped",
+			file:      "name,price\n\nSirloin,150000\n , \nIga,90000\n",
+			wantLines: []int{3, 5},
+			wantNames: []string{"Sirloin", "Iga"},
+		},
+		{
+			name:      "short and long rows",
+			file:      "name,price\nSirloin\nIga,90000,extra\n",
+			wantLines: []int{2, 3},
+			wantNames: []string{"Sirloin", "Iga"},
+		},
+		{
+			name:    "required column missing",
+			file:    "name,stock\nSirloin,10\n",
+			wantErr: utils.ErrInvalidImportFile,
+		},
+		{
+			name:    "empty file",
+			file:    "",
+			wantErr: utils.ErrInvalidImportFile,
+		},
+		{
+			name:    "broken quoting",
+			file:    "name,price\n\"Sirloin,150000\n",
+			wantErr: utils.ErrInvalidImportFile,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rows, err := readImportFile(strings.NewReader(tt.file), "name", "price")
+			if err != tt.wantErr {
+				t.Fatalf("readImportFile() error = %v, want %v", err, tt.wantErr)
+			}
+			if len(rows) != len(tt.wantLines) {
+				t.Fatalf("rows = %d, want %d", len(rows), len(tt.wantLines))
+			}
+			for i, row := range rows {
+				if row.line != tt.wantLines[i] || row.get("name") != tt.wantNames[i] {
+					t.Errorf("row %d = line %d %q, want line %d %q", i, row.line, row.get("name"), tt.wantLines[i], tt.wantNames[i])
+				}
+			}
+		})
+	}
+}
+
+func TestImportRowValues(t *testing.T) {
+	row := &importRow{line: 4, values: map[string]string{
+		"price":     "1500.5",
+		"bad_price": "abc",
+		"negative":  "-1",
+		"nan":       "NaN",
+		"infinite":  "+Inf",
+		"days":      "7",
+		"half_days": "1.5",
+	}}
+	result := &model.ImportResult{}
+
+	if got := row.float("price", result); got != 1500.5 {
+		t.Errorf("float(price) = %v, want 1500.5", got)
+	}
+	if got := row.float("missing", result); got != 0 {
+		t.Errorf("float(missing) = %v, want 0", got)
+	}
+	if got := row.int("days", result); got != 7 {
+		t.Errorf("int(days) = %v, want 7", got)
+	}
+	if len(result.Errors) != 0 {
+		t.Fatalf("valid values reported errors: %v", result.Errors)
+	}
+
+	row.float("bad_price", result)
+	row.float("negative", result)
+	row.float("nan", result)
+	row.float("infinite", result)
+	row.int("half_days", result)
+	row.required("name", result)
+	var columns []string
+	for _, rowError := range result.Errors {
+		if rowError.Row != 4 {
+			t.Errorf("error on row %d, want 4", rowError.Row)
+		}
+		columns = append(columns, rowError.Column)
+	}
+	if got, want := strings.Join(columns, ","), "bad_price,negative,nan,infinite,half_days,name"; got != want {
+		t.Errorf("errors on %s, want %s", got, want)
+	}
+}
@@ -0,0 +1,52 @@
+package usecase
+
+import (
+	model "trackprosto/models"
+
+	"github.com/google/uuid"
+)
+
+// newInvoiceLedgerEntry books an invoice total against the customer: a sale
+// ("out") is owed to us and is a debit, a purchase ("in") is owed by us and is
+// a credit.
+func newInvoiceLedgerEntry(transaction *model.TransactionHeader) *model.CustomerLedgerEntry {
+	entry := &model.CustomerLedgerEntry{
+		ID:          uuid.NewString(),
+		CustomerID:  transaction.CustomerID,
+		TxType:      transaction.TxType,
+		EntryDate:   transaction.Date,
+		SourceType:  model.LedgerSourceTransaction,
+		SourceID:    transaction.ID,
+		Reference:   transaction.InvoiceNumber,
+		Description: "Invoice " + transaction.InvoiceNumber,
+		CreatedBy:   transaction.CreatedBy,
+	}
+	if transaction.TxType == "in" {
+		entry.Credit = transaction.Total
+	} else {
+		entry.Debit = transaction.Total
+	}
+	return entry
+}
+
+// newPaymentLedgerEntry books a payment on an invoice, reducing whatever the
+// invoice left outstanding.
+func newPaymentLedgerEntry(transaction *model.TransactionHeader, payment *model.CreditPayment) *model.CustomerLedgerEntry {
+	entry := &model.CustomerLedgerEntry{
+		ID:          uuid.NewString(),
+		CustomerID:  transaction.CustomerID,
+		TxType:      transaction.TxType,
+		EntryDate:   payment.PaymentDate,
+		SourceType:  model.LedgerSourceCreditPayment,
+		SourceID:    payment.ID,
+		Reference:   payment.InvoiceNumber,
+		Description: payment.Notes,
+		CreatedBy:   payment.CreatedBy,
+	}
+	if transaction.TxType == "in" {
+		entry.Debit = payment.Amount
+	} else {
+		entry.Credit = payment.Amount
+	}
+	return entry
+}
@@ -1,11 +1,16 @@
 package usecase
 
 import (
+	"io"
+	"strings"
 	"trackprosto/delivery/utils"
 	model "trackprosto/models"
 	"trackprosto/repository"
+	"trackprosto/utils/export"
 
+	"github.com/google/uuid"
 	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
 )
 
 type CustomerUsecase interface {
@@ -17,24 +22,75 @@ type CustomerUsecase interface {
 	DeleteCustomer(id string) error
 	GetAllCustomerByCompanyId(page int, itemsPerPage int, company_id string) ([]*model.CustomerModel, int, error)
 	GetAllTransactionsByCustomerId(customer_id string, payment_status string, page int, itemsPerPage int) ([]*model.TransactionHeader, int, error)
+	GetCustomerLedger(customer_id string, txType string, startDate string, endDate string) (*model.CustomerLedger, error)
+	ExportCustomers(companyID string, format string, w io.Writer) error
+	ImportCustomers(file io.Reader, dryRun bool, importedBy string) (*model.ImportResult, error)
 }
 
 type customerUsecase struct {
-	customerRepo    repository.CustomerRepository
-	companyRepo     repository.CompanyRepository
-	transactionRepo repository.TransactionRepository
+	customerRepo       repository.CustomerRepository
+	companyRepo        repository.CompanyRepository
+	transactionRepo    repository.TransactionRepository
+	customerLedgerRepo repository.CustomerLedgerRepository
 }
 
 
 
-func NewCustomerUsecase(cr repository.CustomerRepository, cpr repository.CompanyRepository, txr repository.TransactionRepository) CustomerUsecase {
+func NewCustomerUsecase(cr repository.CustomerRepository, cpr repository.CompanyRepository, txr repository.TransactionRepository, clr repository.CustomerLedgerRepository) CustomerUsecase {
 	return &customerUsecase{
-		customerRepo:    cr,
-		companyRepo:     cpr,
-		transactionRepo: txr,
+		customerRepo:       cr,
+		companyRepo:        cpr,
+		transactionRepo:    txr,
+		customerLedgerRepo: clr,
 	}
 }
 
+// GetCustomerLedger returns the ledger entries of "out" (receivable) or "in"
+// (payable) invoices between startDate and endDate (inclusive, YYYY-MM-DD)
+// with a running balance carried from the opening balance.
+func (uc *customerUsecase) GetCustomerLedger(customer_id string, txType string, startDate string, endDate string) (*model.CustomerLedger, error) {
+	if txType != "in" && txType != "out" {
+		return nil, utils.ErrInvalidTxType
+	}
+	customer, err := uc.customerRepo.GetCustomerById(customer_id)
+	if customer == nil {
+		return nil, utils.ErrCustomerNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	openingBalance, err := uc.customerLedgerRepo.GetBalanceBefore(customer_id, txType, startDate)
+	if err != nil {
+		logrus.Error(err)
+		return nil, err
+	}
+	entries, err := uc.customerLedgerRepo.GetEntries(customer_id, txType, startDate, endDate)
+	if err != nil {
+		logrus.Error(err)
+		return nil, err
+	}
+
+	ledger := &model.CustomerLedger{
+		CustomerID:     customer_id,
+		TxType:         txType,
+		StartDate:      startDate,
+		EndDate:        endDate,
+		OpeningBalance: openingBalance,
+		Entries:        entries,
+	}
+	balance := openingBalance
+	for _, entry := range entries {
+		balance += entry.Debit - entry.Credit
+		entry.Balance = balance
+		ledger.TotalDebit += entry.Debit
+		ledger.TotalCredit += entry.Credit
+	}
+	ledger.ClosingBalance = balance
+
+	return ledger, nil
+}
+
 // GetAllTransactionsByCustomerId implements CustomerUsecase.
 func (uc *customerUsecase) GetAllTransactionsByCustomerId(customer_id string, payment_status string, page int, itemsPerPage int) ([]*model.TransactionHeader, int, error) {
 	custExist , err := uc.customerRepo.GetCustomerById(customer_id);
@@ -70,6 +126,13 @@ func (uc *customerUsecase) CreateCustomer(customer *model.CustomerModel) (*model
 	if companyExist == nil {
 		return nil, utils.ErrCompanyNotFound
 	}
+	if customer.PaymentTermDays != nil && *customer.PaymentTermDays < 0 {
+		return nil, utils.ErrInvalidPaymentTerm
+	}
+	customer.PriceTier = utils.NonEmpty(customer.PriceTier, model.PriceListRetail)
+	if !model.IsValidPriceTier(customer.PriceTier) {
+		return nil, utils.ErrInvalidPriceTier
+	}
 	
 	customer, err = uc.customerRepo.CreateCustomer(customer)
 	if err != nil {
@@ -91,6 +154,16 @@ func (uc *customerUsecase) UpdateCustomer(customer *model.CustomerModel) error {
 	customer.FullName = utils.NonEmpty(customer.FullName, currentCustomer.FullName)
 	customer.Address = utils.NonEmpty(customer.Address, currentCustomer.Address)
 	customer.PhoneNumber = utils.NonEmpty(customer.PhoneNumber, currentCustomer.PhoneNumber)
+	if customer.PaymentTermDays == nil {
+		customer.PaymentTermDays = currentCustomer.PaymentTermDays
+	}
+	if customer.PaymentTermDays != nil && *customer.PaymentTermDays < 0 {
+		return utils.ErrInvalidPaymentTerm
+	}
+	customer.PriceTier = utils.NonEmpty(customer.PriceTier, currentCustomer.PriceTier)
+	if !model.IsValidPriceTier(customer.PriceTier) {
+		return utils.ErrInvalidPriceTier
+	}
 	customer.CreatedAt = currentCustomer.CreatedAt
 	customer.CreatedBy = currentCustomer.CreatedBy
 	return uc.customerRepo.UpdateCustomer(customer)
@@ -155,3 +228,101 @@ func (uc *customerUsecase) GetAllCustomerByCompanyId(page int, itemsPerPage int,
 	}
 	return customers, totalPages, nil
 }
+
+// ExportCustomers writes the customers with what they owe us and what we owe
+// them to w as a spreadsheet.
+func (uc *customerUsecase) ExportCustomers(companyID string, format string, w io.Writer) error {
+	companies, err := uc.companyRepo.GetAllCompany()
+	if err != nil {
+		logrus.WithField("error", err).Error("Failed to get companies")
+		return err
+	}
+	companyNames := make(map[string]string, len(companies))
+	for _, company := range companies {
+		companyNames[company.ID] = company.CompanyName
+	}
+
+	writer, err := export.NewWriter(format, w, "Customer ID", "Name", "Company", "Address", "Phone Number", "Payment Term (days)", "Debt", "Payable", "Created At")
+	if err != nil {
+		return utils.ErrInvalidExportFormat
+	}
+	err = uc.customerRepo.StreamCustomers(companyID, func(customer *model.CustomerModel) error {
+		paymentTermDays := 0
+		if customer.PaymentTermDays != nil {
+			paymentTermDays = *customer.PaymentTermDays
+		}
+		return writer.WriteRow(customer.Id, customer.FullName, companyNames[customer.CompanyId], customer.Address,
+			customer.PhoneNumber, paymentTermDays, customer.Debt, customer.Payable, customer.CreatedAt.Format("2006-01-02 15:04:05"))
+	})
+	if err != nil {
+		logrus.WithField("error", err).Error("Failed to export customers")
+		return err
+	}
+	return writer.Close()
+}
+
+// ImportCustomers creates the customers listed in a CSV file with the columns
+// fullname, company_id, phone_number, address, payment_term_days and
+// price_tier, of which the first three are required. Nothing is stored unless
+// every row is valid.
+func (uc *customerUsecase) ImportCustomers(file io.Reader, dryRun bool, importedBy string) (*model.ImportResult, error) {
+	rows, err := readImportFile(file, "fullname", "company_id", "phone_number")
+	if err != nil {
+		return nil, err
+	}
+
+	result := &model.ImportResult{DryRun: dryRun, Rows: len(rows)}
+	customers := make([]*model.CustomerModel, 0, len(rows))
+	companies := make(map[string]bool)
+	for _, row := range rows {
+		paymentTermDays := row.int("payment_term_days", result)
+		customer := &model.CustomerModel{
+			Id:              uuid.NewString(),
+			FullName:        row.required("fullname", result),
+			Address:         row.get("address"),
+			CompanyId:       row.required("company_id", result),
+			PhoneNumber:     row.required("phone_number", result),
+			PaymentTermDays: &paymentTermDays,
+			PriceTier:       utils.NonEmpty(strings.ToLower(row.get("price_tier")), model.PriceListRetail),
+			CreatedBy:       importedBy,
+			UpdatedBy:       importedBy,
+		}
+		if !model.IsValidPriceTier(customer.PriceTier) {
+			result.AddError(row.line, "price_tier", utils.ErrInvalidPriceTier.Error())
+		}
+		if customer.CompanyId != "" {
+			exists, ok := companies[customer.CompanyId]
+			if !ok {
+				company, err := uc.companyRepo.GetCompanyById(customer.CompanyId)
+				if err != nil {
+					return nil, err
+				}
+				exists = company != nil
+				companies[customer.CompanyId] = exists
+			}
+			if !exists {
+				result.AddError(row.line, "company_id", utils.ErrCompanyNotFound.Error())
+			}
+		}
+		customers = append(customers, customer)
+	}
+	if dryRun || len(result.Errors) > 0 {
+		return result, nil
+	}
+
+	err = uc.transactionRepo.GetDB().Transaction(func(tx *gorm.DB) error {
+		customerRepo := uc.customerRepo.WithTx(tx)
+		for _, customer := range customers {
+			if _, err := customerRepo.CreateCustomer(customer); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		logrus.WithField("error", err).Error("Failed to import customers")
+		return nil, err
+	}
+	result.Imported = len(customers)
+	return result, nil
+}
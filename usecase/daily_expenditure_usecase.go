@@ -1,10 +1,14 @@
 package usecase
 
 import (
-	"fmt"
+	"io"
 	"time"
+	"trackprosto/delivery/utils"
 	model "trackprosto/models"
 	"trackprosto/repository"
+	"trackprosto/utils/export"
+
+	"gorm.io/gorm"
 )
 
 type DailyExpenditureUseCase interface {
@@ -14,42 +18,57 @@ type DailyExpenditureUseCase interface {
 	GetAllDailyExpenditures() ([]*model.DailyExpenditure, error)
 	DeleteDailyExpenditure(id string) error
 	GetTotalExpenditureByDateRange(startDate time.Time, endDate time.Time) (float64, error)
-	GenerateNotaNumber() (string, error)
+	ExportDailyExpenditures(filter *model.ExportFilter, format string, w io.Writer) error
 }
 
 type dailyExpenditureUseCase struct {
 	dailyExpenditureRepo repository.DailyExpenditureRepository
 	userRepo             repository.UserRepository
+	dayClosingRepo       repository.DayClosingRepository
+	sequenceRepo         repository.SequenceRepository
+	numberingSchemeRepo  repository.NumberingSchemeRepository
 }
 
-func NewDailyExpenditureUseCase(deRepo repository.DailyExpenditureRepository, userRepo repository.UserRepository) DailyExpenditureUseCase {
+func NewDailyExpenditureUseCase(deRepo repository.DailyExpenditureRepository, userRepo repository.UserRepository, dayClosingRepo repository.DayClosingRepository, sequenceRepo repository.SequenceRepository, numberingSchemeRepo repository.NumberingSchemeRepository) DailyExpenditureUseCase {
 	return &dailyExpenditureUseCase{
 		dailyExpenditureRepo: deRepo,
 		userRepo:             userRepo,
+		dayClosingRepo:       dayClosingRepo,
+		sequenceRepo:         sequenceRepo,
+		numberingSchemeRepo:  numberingSchemeRepo,
 	}
 }
 
 func (uc *dailyExpenditureUseCase) CreateDailyExpenditure(expenditure *model.DailyExpenditure) error {
-	nota_number, err := uc.GenerateNotaNumber()
-	if err != nil {
-		return err
-	}
 	date := time.Now().Format("2006-01-02")
-	
-	expenditure.DeNote = nota_number
-	expenditure.Date = date
-	err = uc.dailyExpenditureRepo.CreateDailyExpenditure(expenditure)
-	if err != nil {
-		return err
-	}
-	return nil
+	return uc.dailyExpenditureRepo.GetDB().Transaction(func(tx *gorm.DB) error {
+		if err := ensureDayOpen(uc.dayClosingRepo.WithTx(tx), date); err != nil {
+			return err
+		}
+		nota_number, err := nextDocumentNumber(uc.numberingSchemeRepo.WithTx(tx), uc.sequenceRepo.WithTx(tx), model.DocumentExpenditure, time.Now())
+		if err != nil {
+			return err
+		}
+		expenditure.DeNote = nota_number
+		expenditure.Date = date
+		return uc.dailyExpenditureRepo.WithTx(tx).CreateDailyExpenditure(expenditure)
+	})
 }
 
 func (uc *dailyExpenditureUseCase) UpdateDailyExpenditure(expenditure *model.DailyExpenditure) error {
-	// Perform any business logic or validation before updating the daily expenditure
-	// ...
-
-	return uc.dailyExpenditureRepo.UpdateDailyExpenditure(expenditure)
+	return uc.dailyExpenditureRepo.GetDB().Transaction(func(tx *gorm.DB) error {
+		dailyExpenditureRepo := uc.dailyExpenditureRepo.WithTx(tx)
+		dayClosingRepo := uc.dayClosingRepo.WithTx(tx)
+		if err := ensureExpenditureDayOpen(dailyExpenditureRepo, dayClosingRepo, expenditure.ID); err != nil {
+			return err
+		}
+		// Moving an expenditure onto a closed day would change that day's cash book too.
+		if err := ensureDayOpen(dayClosingRepo, expenditure.Date); err != nil {
+			return err
+		}
+
+		return dailyExpenditureRepo.UpdateDailyExpenditure(expenditure)
+	})
 }
 
 func (uc *dailyExpenditureUseCase) GetDailyExpenditureByID(id string) (*model.DailyExpenditure, error) {
@@ -61,27 +80,44 @@ func (uc *dailyExpenditureUseCase) GetAllDailyExpenditures() ([]*model.DailyExpe
 }
 
 func (uc *dailyExpenditureUseCase) DeleteDailyExpenditure(id string) error {
-	return uc.dailyExpenditureRepo.DeleteDailyExpenditure(id)
+	return uc.dailyExpenditureRepo.GetDB().Transaction(func(tx *gorm.DB) error {
+		dailyExpenditureRepo := uc.dailyExpenditureRepo.WithTx(tx)
+		if err := ensureExpenditureDayOpen(dailyExpenditureRepo, uc.dayClosingRepo.WithTx(tx), id); err != nil {
+			return err
+		}
+		return dailyExpenditureRepo.DeleteDailyExpenditure(id)
+	})
+}
+
+// ensureExpenditureDayOpen rejects changes to an expenditure dated on a closed day.
+func ensureExpenditureDayOpen(dailyExpenditureRepo repository.DailyExpenditureRepository, dayClosingRepo repository.DayClosingRepository, id string) error {
+	expenditure, err := dailyExpenditureRepo.GetDailyExpenditureByID(id)
+	if err != nil {
+		return err
+	}
+	if expenditure == nil {
+		return nil
+	}
+	return ensureDayOpen(dayClosingRepo, expenditure.Date)
 }
 
 func (uc *dailyExpenditureUseCase) GetTotalExpenditureByDateRange(startDate time.Time, endDate time.Time) (float64, error) {
 	return uc.dailyExpenditureRepo.GetTotalExpenditureByDateRange(startDate, endDate)
 }
 
-func (uc *dailyExpenditureUseCase) GenerateNotaNumber() (string, error) {
-	now := time.Now().Format("20060102")
-	lastNotaNumber, err := uc.dailyExpenditureRepo.GetLastNotaNumber(now)
+// ExportDailyExpenditures writes the expenditures dated within the filter's
+// range to w as a spreadsheet.
+func (uc *dailyExpenditureUseCase) ExportDailyExpenditures(filter *model.ExportFilter, format string, w io.Writer) error {
+	writer, err := export.NewWriter(format, w, "Date", "Note", "Description", "Amount", "Created By", "Created At")
 	if err != nil {
-		return "", err
+		return utils.ErrInvalidExportFormat
 	}
-
-	year := time.Now().Format("2006")
-	month := time.Now().Format("01")
-	day := time.Now().Format("02")
-
-	noteNumber := fmt.Sprintf("DE-%s%s%s%04d", year, month, day, lastNotaNumber)
-
-	return noteNumber, nil
+	err = uc.dailyExpenditureRepo.StreamDailyExpenditures(filter.StartDate, filter.EndDate, func(expenditure *model.DailyExpenditure) error {
+		return writer.WriteRow(expenditure.Date[:min(len(expenditure.Date), 10)], expenditure.DeNote, expenditure.Description,
+			expenditure.Amount, expenditure.CreatedBy, expenditure.CreatedAt.Format("2006-01-02 15:04:05"))
+	})
+	if err != nil {
+		return err
+	}
+	return writer.Close()
 }
-
-
@@ -0,0 +1,34 @@
+package usecase
+
+import (
+	"trackprosto/delivery/utils"
+	"trackprosto/repository"
+
+	"github.com/sirupsen/logrus"
+)
+
+// ensureDayOpen rejects changes to cash booked on any of the given dates once
+// that day has been closed. dayClosingRepo must be bound to the transaction
+// making the change: the dates stay open until it ends.
+func ensureDayOpen(dayClosingRepo repository.DayClosingRepository, dates ...string) error {
+	for _, date := range dates {
+		if date == "" {
+			continue
+		}
+		if len(date) > 10 {
+			date = date[:10]
+		}
+		if err := dayClosingRepo.LockDateShared(date); err != nil {
+			return err
+		}
+		locked, err := dayClosingRepo.IsDateLocked(date)
+		if err != nil {
+			return err
+		}
+		if locked {
+			logrus.WithField("date", date).Error("Day is closed")
+			return utils.ErrDayClosed
+		}
+	}
+	return nil
+}
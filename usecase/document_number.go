@@ -0,0 +1,89 @@
+package usecase
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"trackprosto/delivery/utils"
+	model "trackprosto/models"
+	"trackprosto/repository"
+)
+
+var seqTokenPattern = regexp.MustCompile(`\{SEQ(?::(\d+))?\}`)
+
+// nextDocumentNumber allocates the next number of a document type according to
+// its numbering scheme. Both repositories must be bound to the transaction that
+// stores the document.
+func nextDocumentNumber(schemeRepo repository.NumberingSchemeRepository, sequenceRepo repository.SequenceRepository, documentType string, now time.Time) (string, error) {
+	scheme, err := schemeRepo.GetNumberingScheme(documentType)
+	if err != nil {
+		return "", err
+	}
+	if scheme == nil {
+		return "", utils.ErrNumberingSchemeNotFound
+	}
+	seq, err := sequenceRepo.NextValue(scheme.Prefix, resetPeriodKey(scheme.ResetPeriod, now))
+	if err != nil {
+		return "", err
+	}
+	return renderDocumentNumber(scheme, now, seq), nil
+}
+
+// resetPeriodKey names the period a counter belongs to, so it restarts when
+// the period changes.
+func resetPeriodKey(resetPeriod string, now time.Time) string {
+	switch resetPeriod {
+	case model.ResetDaily:
+		return now.Format("20060102")
+	case model.ResetMonthly:
+		return now.Format("200601")
+	case model.ResetYearly:
+		return now.Format("2006")
+	}
+	return ""
+}
+
+func renderDocumentNumber(scheme *model.NumberingScheme, now time.Time, seq int) string {
+	number := strings.NewReplacer(
+		"{PREFIX}", scheme.Prefix,
+		"{YYYY}", now.Format("2006"),
+		"{YY}", now.Format("06"),
+		"{MM}", now.Format("01"),
+		"{DD}", now.Format("02"),
+	).Replace(scheme.Template)
+	return seqTokenPattern.ReplaceAllStringFunc(number, func(token string) string {
+		width, _ := strconv.Atoi(seqTokenPattern.FindStringSubmatch(token)[1])
+		return fmt.Sprintf("%0*d", width, seq)
+	})
+}
+
+// validateNumberingScheme makes sure a scheme cannot hand out the same number
+// twice: it needs its prefix, a counter and every date token its reset period
+// relies on.
+func validateNumberingScheme(scheme *model.NumberingScheme) error {
+	var required []string
+	switch scheme.ResetPeriod {
+	case model.ResetDaily:
+		required = []string{"{MM}", "{DD}"}
+	case model.ResetMonthly:
+		required = []string{"{MM}"}
+	case model.ResetYearly, model.ResetNever:
+	default:
+		return utils.ErrInvalidResetPeriod
+	}
+	if !seqTokenPattern.MatchString(scheme.Template) || !strings.Contains(scheme.Template, "{PREFIX}") {
+		return utils.ErrInvalidNumberingTemplate
+	}
+	if scheme.ResetPeriod != model.ResetNever &&
+		!strings.Contains(scheme.Template, "{YYYY}") && !strings.Contains(scheme.Template, "{YY}") {
+		return utils.ErrInvalidNumberingTemplate
+	}
+	for _, token := range required {
+		if !strings.Contains(scheme.Template, token) {
+			return utils.ErrInvalidNumberingTemplate
+		}
+	}
+	return nil
+}
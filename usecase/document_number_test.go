@@ -0,0 +1,78 @@
+package usecase
+
+import (
+	"testing"
+	"time"
+	"trackprosto/delivery/utils"
+	model "trackprosto/models"
+)
+
+func TestRenderDocumentNumber(t *testing.T) {
+	now := time.Date(2026, time.March, 7, 10, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name     string
+		template string
+		seq      int
+		want     string
+	}{
+		{"padded counter", "{PREFIX}/{YYYY}/{MM}/{SEQ:4}", 12, "INV/2026/03/0012"},
+		{"short year and day", "{PREFIX}-{YY}{MM}{DD}-{SEQ:3}", 5, "INV-260307-005"},
+		{"unpadded counter", "{PREFIX}{SEQ}", 42, "INV42"},
+		{"counter wider than padding", "{PREFIX}{SEQ:2}", 1234, "INV1234"},
+		{"literal text kept", "No. {PREFIX}.{SEQ:2}", 7, "No. INV.07"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scheme := &model.NumberingScheme{Prefix: "INV", Template: tt.template}
+			if got := renderDocumentNumber(scheme, now, tt.seq); got != tt.want {
+				t.Errorf("renderDocumentNumber(%q) = %q, want %q", tt.template, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateNumberingScheme(t *testing.T) {
+	tests := []struct {
+		name        string
+		template    string
+		resetPeriod string
+		want        error
+	}{
+		{"daily with full date", "{PREFIX}{YY}{MM}{DD}{SEQ:3}", model.ResetDaily, nil},
+		{"daily without day", "{PREFIX}{YY}{MM}{SEQ:3}", model.ResetDaily, utils.ErrInvalidNumberingTemplate},
+		{"monthly", "{PREFIX}/{YYYY}/{MM}/{SEQ:4}", model.ResetMonthly, nil},
+		{"monthly without month", "{PREFIX}/{YYYY}/{SEQ:4}", model.ResetMonthly, utils.ErrInvalidNumberingTemplate},
+		{"yearly", "{PREFIX}/{YY}/{SEQ:5}", model.ResetYearly, nil},
+		{"yearly without year", "{PREFIX}/{SEQ:5}", model.ResetYearly, utils.ErrInvalidNumberingTemplate},
+		{"never without date", "{PREFIX}{SEQ:6}", model.ResetNever, nil},
+		{"without counter", "{PREFIX}/{YYYY}", model.ResetNever, utils.ErrInvalidNumberingTemplate},
+		{"without prefix", "{YYYY}/{SEQ:4}", model.ResetYearly, utils.ErrInvalidNumberingTemplate},
+		{"unknown reset period", "{PREFIX}{SEQ}", "weekly", utils.ErrInvalidResetPeriod},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scheme := &model.NumberingScheme{Prefix: "INV", Template: tt.template, ResetPeriod: tt.resetPeriod}
+			if got := validateNumberingScheme(scheme); got != tt.want {
+				t.Errorf("validateNumberingScheme(%q, %q) = %v, want %v", tt.template, tt.resetPeriod, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResetPeriodKey(t *testing.T) {
+	now := time.Date(2026, time.March, 7, 10, 0, 0, 0, time.UTC)
+	tests := []struct {
+		resetPeriod string
+		want        string
+	}{
+		{model.ResetDaily, "20260307"},
+		{model.ResetMonthly, "202603"},
+		{model.ResetYearly, "2026"},
+		{model.ResetNever, ""},
+	}
+	for _, tt := range tests {
+		if got := resetPeriodKey(tt.resetPeriod, now); got != tt.want {
+			t.Errorf("resetPeriodKey(%q) = %q, want %q", tt.resetPeriod, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,60 @@
+package usecase
+
+import (
+	"strings"
+	"time"
+	model "trackprosto/models"
+)
+
+// Failed logins are counted per username and per IP address. From the
+// loginBackoffAfter-th failure on each further attempt has to wait twice as
+// long as the previous one, and a key reaching its lockout threshold is locked
+// for loginLockoutDuration. Counters left alone for loginFailureWindow start
+// over.
+const (
+	loginBackoffAfter     = 3
+	loginBackoffBase      = time.Second
+	loginBackoffMax       = 5 * time.Minute
+	loginLockoutUsername  = 10
+	loginLockoutIPAddress = 50
+	loginLockoutDuration  = 15 * time.Minute
+	loginFailureWindow    = 24 * time.Hour
+)
+
+func usernameThrottleKey(username string) string {
+	return "user:" + strings.ToLower(username)
+}
+
+func ipAddressThrottleKey(ipAddress string) string {
+	return "ip:" + ipAddress
+}
+
+// loginRetryAfter returns how long a key has to wait before its next attempt.
+func loginRetryAfter(throttle *model.LoginThrottle, now time.Time) time.Duration {
+	if throttle.LockedUntil != nil && throttle.LockedUntil.After(now) {
+		return throttle.LockedUntil.Sub(now)
+	}
+	if throttle.LastFailedAt == nil || throttle.FailedCount < loginBackoffAfter ||
+		now.Sub(*throttle.LastFailedAt) > loginFailureWindow {
+		return 0
+	}
+	backoff := loginBackoffMax
+	if shift := throttle.FailedCount - loginBackoffAfter; shift < 20 {
+		backoff = min(loginBackoffBase<<shift, loginBackoffMax)
+	}
+	return max(throttle.LastFailedAt.Add(backoff).Sub(now), 0)
+}
+
+// countLoginFailure adds a failure to a key and locks it once it reaches
+// lockoutAfter failures.
+func countLoginFailure(throttle *model.LoginThrottle, lockoutAfter int, now time.Time) {
+	if throttle.LastFailedAt != nil && now.Sub(*throttle.LastFailedAt) > loginFailureWindow {
+		throttle.FailedCount = 0
+	}
+	throttle.FailedCount++
+	throttle.LastFailedAt = &now
+	if throttle.FailedCount >= lockoutAfter {
+		lockedUntil := now.Add(loginLockoutDuration)
+		throttle.LockedUntil = &lockedUntil
+	}
+}
@@ -0,0 +1,85 @@
+package usecase
+
+import (
+	"testing"
+	"time"
+	model "trackprosto/models"
+)
+
+func timeAgo(now time.Time, d time.Duration) *time.Time {
+	t := now.Add(-d)
+	return &t
+}
+
+func TestLoginRetryAfter(t *testing.T) {
+	now := time.Date(2026, time.March, 7, 10, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name     string
+		throttle model.LoginThrottle
+		want     time.Duration
+	}{
+		{"no failures", model.LoginThrottle{}, 0},
+		{"below backoff", model.LoginThrottle{FailedCount: 2, LastFailedAt: timeAgo(now, 0)}, 0},
+		{"first backoff", model.LoginThrottle{FailedCount: 3, LastFailedAt: timeAgo(now, 0)}, time.Second},
+		{"backoff doubles", model.LoginThrottle{FailedCount: 5, LastFailedAt: timeAgo(now, 0)}, 4 * time.Second},
+		{"part of backoff waited", model.LoginThrottle{FailedCount: 4, LastFailedAt: timeAgo(now, time.Second)}, time.Second},
+		{"backoff waited out", model.LoginThrottle{FailedCount: 5, LastFailedAt: timeAgo(now, 10*time.Second)}, 0},
+		{"backoff capped", model.LoginThrottle{FailedCount: 12, LastFailedAt: timeAgo(now, 0)}, loginBackoffMax},
+		{"backoff capped without overflow", model.LoginThrottle{FailedCount: 100, LastFailedAt: timeAgo(now, 0)}, loginBackoffMax},
+		{"failures outside window", model.LoginThrottle{FailedCount: 8, LastFailedAt: timeAgo(now, 25*time.Hour)}, 0},
+		{
+			"locked",
+			model.LoginThrottle{FailedCount: 10, LastFailedAt: timeAgo(now, 5*time.Minute), LockedUntil: timeAgo(now, -10*time.Minute)},
+			10 * time.Minute,
+		},
+		{
+			"lock expired",
+			model.LoginThrottle{FailedCount: 10, LastFailedAt: timeAgo(now, 16*time.Minute), LockedUntil: timeAgo(now, time.Minute)},
+			0,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := loginRetryAfter(&tt.throttle, now); got != tt.want {
+				t.Errorf("loginRetryAfter() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCountLoginFailure(t *testing.T) {
+	now := time.Date(2026, time.March, 7, 10, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name         string
+		throttle     model.LoginThrottle
+		lockoutAfter int
+		wantCount    int
+		wantLocked   bool
+	}{
+		{"first failure", model.LoginThrottle{}, loginLockoutUsername, 1, false},
+		{"below lockout", model.LoginThrottle{FailedCount: 8, LastFailedAt: timeAgo(now, time.Minute)}, loginLockoutUsername, 9, false},
+		{"reaches username lockout", model.LoginThrottle{FailedCount: 9, LastFailedAt: timeAgo(now, time.Minute)}, loginLockoutUsername, 10, true},
+		{"ip address allows more", model.LoginThrottle{FailedCount: 9, LastFailedAt: timeAgo(now, time.Minute)}, loginLockoutIPAddress, 10, false},
+		{"reaches ip address lockout", model.LoginThrottle{FailedCount: 49, LastFailedAt: timeAgo(now, time.Minute)}, loginLockoutIPAddress, 50, true},
+		{"starts over after window", model.LoginThrottle{FailedCount: 9, LastFailedAt: timeAgo(now, 25*time.Hour)}, loginLockoutUsername, 1, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			throttle := tt.throttle
+			countLoginFailure(&throttle, tt.lockoutAfter, now)
+			if throttle.FailedCount != tt.wantCount {
+				t.Errorf("FailedCount = %v, want %v", throttle.FailedCount, tt.wantCount)
+			}
+			if throttle.LastFailedAt == nil || !throttle.LastFailedAt.Equal(now) {
+				t.Errorf("LastFailedAt = %v, want %v", throttle.LastFailedAt, now)
+			}
+			locked := throttle.LockedUntil != nil
+			if locked != tt.wantLocked {
+				t.Fatalf("locked = %v, want %v", locked, tt.wantLocked)
+			}
+			if locked && !throttle.LockedUntil.Equal(now.Add(loginLockoutDuration)) {
+				t.Errorf("LockedUntil = %v, want %v", throttle.LockedUntil, now.Add(loginLockoutDuration))
+			}
+		})
+	}
+}
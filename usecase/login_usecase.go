@@ -1,74 +1,353 @@
 package usecase
 
 import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
 	"fmt"
 	"time"
+	"trackprosto/config"
 	"trackprosto/delivery/utils"
+	model "trackprosto/models"
 	"trackprosto/repository"
+	"trackprosto/utils/tokenversion"
 
 	"github.com/golang-jwt/jwt"
+	"github.com/google/uuid"
 	"github.com/sirupsen/logrus"
 	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
 )
 
 type LoginUseCase interface {
 	// VerifyLogin(username, password string) (*model.User, error)
-	Login(username, password string) (string, error)
+	Login(username, password, ipAddress, userAgent string) (*model.TokenResponse, error)
+	Refresh(refreshToken string) (*model.TokenResponse, error)
+	Logout(refreshToken string) error
+	ChangePassword(userID string, request *model.ChangePasswordRequest) (*model.TokenResponse, error)
+	GetLoginAttempts(username string, page int, itemsPerPage int) ([]*model.LoginAttempt, int, error)
+	GetPasswordPolicy() config.PasswordPolicy
 }
 
+// dummyPasswordHash is compared against when a login names an unknown user.
+var dummyPasswordHash, _ = bcrypt.GenerateFromPassword([]byte("unknown user"), bcrypt.DefaultCost)
+
 type loginUseCase struct {
-	userRepository repository.UserRepository
+	userRepository         repository.UserRepository
+	refreshTokenRepository repository.RefreshTokenRepository
+	loginAttemptRepository repository.LoginAttemptRepository
+	tokenVersions          *tokenversion.Cache
+	jwtConfig              config.JWTConfig
+	passwordPolicy         config.PasswordPolicy
 }
 
-func NewLoginUseCase(userRepo repository.UserRepository) LoginUseCase {
+func NewLoginUseCase(userRepo repository.UserRepository, refreshTokenRepo repository.RefreshTokenRepository, loginAttemptRepo repository.LoginAttemptRepository, tokenVersions *tokenversion.Cache, jwtConfig config.JWTConfig, passwordPolicy config.PasswordPolicy) LoginUseCase {
 	return &loginUseCase{
-		userRepository: userRepo,
+		userRepository:         userRepo,
+		refreshTokenRepository: refreshTokenRepo,
+		loginAttemptRepository: loginAttemptRepo,
+		tokenVersions:          tokenVersions,
+		jwtConfig:              jwtConfig,
+		passwordPolicy:         passwordPolicy,
 	}
 }
 
-func (uc *loginUseCase) Login(username, password string) (string, error) {
+// Login checks the password of a user and issues their tokens. Usernames and
+// IP addresses with too many failed attempts are refused before the password
+// is checked; every attempt is recorded in the login audit.
+func (uc *loginUseCase) Login(username, password, ipAddress, userAgent string) (*model.TokenResponse, error) {
+	attempt := &model.LoginAttempt{
+		ID:        uuid.NewString(),
+		Username:  username,
+		IPAddress: ipAddress,
+		UserAgent: userAgent,
+	}
+	throttles, err := uc.loginAttemptRepository.GetThrottles(usernameThrottleKey(username), ipAddressThrottleKey(ipAddress))
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	for _, throttle := range throttles {
+		if wait := loginRetryAfter(throttle, now); wait > 0 {
+			logrus.Warnf("Login of [%s] from %s refused for %v: %s", username, ipAddress, wait.Round(time.Second), throttle.Key)
+			attempt.Reason = model.LoginThrottled
+			uc.auditLogin(attempt)
+			return nil, utils.ErrTooManyLoginAttempts
+		}
+	}
+
 	// Mengecek apakah pengguna dengan username tersebut ada di penyimpanan data
 	user, err := uc.userRepository.GetByUsername(username)
 	if err != nil {
-		return "", fmt.Errorf("failed to retrieve user")
+		return nil, fmt.Errorf("failed to retrieve user")
 	}
 	if condition := user == nil; condition {
-		return "", utils.ErrInvalidUsernamePassword
+		// Pay the same bcrypt cost as for a known user, so the response time
+		// does not tell which usernames exist.
+		bcrypt.CompareHashAndPassword(dummyPasswordHash, []byte(password))
+		return nil, uc.loginFailed(attempt, model.LoginUnknownUser)
 	}
+	attempt.UserID = user.ID
 
 	// Verifikasi password pengguna dengan menggunakan bcrypt
 	err = bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password))
 	if err != nil {
 		logrus.Errorf("Failed to verify password: %v", err)
-		return "", utils.ErrInvalidUsernamePassword
+		return nil, uc.loginFailed(attempt, model.LoginWrongPassword)
+	}
+	if err := uc.loginAttemptRepository.ResetThrottle(usernameThrottleKey(username)); err != nil {
+		logrus.Errorf("Failed to reset login throttle: %v", err)
 	}
+	attempt.Success = true
+	attempt.Reason = model.LoginSuccess
+	uc.auditLogin(attempt)
 
-	// Menghasilkan token JWT
-	token, err := generateJWTToken(user.ID, user.Username, user.Role)
+	// Menghasilkan access token dan refresh token
+	tokens, _, err := uc.issueTokens(uc.refreshTokenRepository, user)
 	if err != nil {
 		logrus.Errorf("Failed to generate token: %v", err)
-		return "", fmt.Errorf("failed to generate token: %v", err)
+		return nil, fmt.Errorf("failed to generate token: %v", err)
+	}
+
+	return tokens, nil
+}
+
+// loginFailed counts a failed attempt against its username and IP address
+// and records it. It returns the error the login fails with.
+func (uc *loginUseCase) loginFailed(attempt *model.LoginAttempt, reason string) error {
+	attempt.Reason = reason
+	uc.auditLogin(attempt)
+
+	now := time.Now()
+	err := uc.loginAttemptRepository.GetDB().Transaction(func(tx *gorm.DB) error {
+		loginAttemptRepo := uc.loginAttemptRepository.WithTx(tx)
+		// Always username first, then IP address: concurrent failures lock
+		// the rows in the same order and cannot deadlock.
+		for _, limit := range []struct {
+			key          string
+			lockoutAfter int
+		}{
+			{usernameThrottleKey(attempt.Username), loginLockoutUsername},
+			{ipAddressThrottleKey(attempt.IPAddress), loginLockoutIPAddress},
+		} {
+			key, lockoutAfter := limit.key, limit.lockoutAfter
+			throttle, err := loginAttemptRepo.GetThrottleForUpdate(key)
+			if err != nil {
+				return err
+			}
+			countLoginFailure(throttle, lockoutAfter, now)
+			if throttle.FailedCount == lockoutAfter {
+				logrus.Warnf("Login locked for %v after %d failures: %s", loginLockoutDuration, throttle.FailedCount, key)
+			}
+			if err := loginAttemptRepo.SaveThrottle(throttle); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		logrus.Errorf("Failed to count failed login: %v", err)
+		return err
+	}
+	return utils.ErrInvalidUsernamePassword
+}
+
+// auditLogin records an attempt. A failure to record it is only logged, so
+// the audit cannot lock everybody out.
+func (uc *loginUseCase) auditLogin(attempt *model.LoginAttempt) {
+	if err := uc.loginAttemptRepository.CreateLoginAttempt(attempt); err != nil {
+		logrus.Errorf("Failed to record login attempt of [%s]: %v", attempt.Username, err)
 	}
+}
 
-	return token, nil
+func (uc *loginUseCase) GetLoginAttempts(username string, page int, itemsPerPage int) ([]*model.LoginAttempt, int, error) {
+	return uc.loginAttemptRepository.GetLoginAttempts(username, page, itemsPerPage)
 }
 
-func generateJWTToken(userID, username, role string) (string, error) {
-	// Membuat claim JWT
+// GetPasswordPolicy returns the policy new passwords are checked against, so
+// clients can tell users what is expected of them.
+func (uc *loginUseCase) GetPasswordPolicy() config.PasswordPolicy {
+	return uc.passwordPolicy
+}
+
+// ChangePassword sets a new password for the user after checking the current
+// one. It ends the user's other sessions and returns new tokens for this one.
+func (uc *loginUseCase) ChangePassword(userID string, request *model.ChangePasswordRequest) (*model.TokenResponse, error) {
+	user, err := uc.userRepository.GetUserByID(userID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil || !user.IsActive {
+		return nil, utils.ErrUserNotFound
+	}
+	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(request.CurrentPassword)); err != nil {
+		return nil, utils.ErrInvalidCurrentPassword
+	}
+	if request.NewPassword == request.CurrentPassword {
+		return nil, utils.ErrPasswordUnchanged
+	}
+	hashedPassword, err := hashPassword(uc.passwordPolicy, user.Username, request.NewPassword)
+	if err != nil {
+		return nil, err
+	}
+	user.Password = hashedPassword
+	user.MustChangePassword = false
+	user.TokenVersion++
+	user.UpdatedBy = user.Username
+
+	var tokens *model.TokenResponse
+	err = uc.userRepository.GetDB().Transaction(func(tx *gorm.DB) error {
+		if err := uc.userRepository.WithTx(tx).UpdateUser(user); err != nil {
+			return err
+		}
+		refreshTokenRepo := uc.refreshTokenRepository.WithTx(tx)
+		if err := refreshTokenRepo.RevokeUserRefreshTokens(user.ID); err != nil {
+			return err
+		}
+		tokens, _, err = uc.issueTokens(refreshTokenRepo, user)
+		return err
+	})
+	if err != nil {
+		logrus.Errorf("Failed to change password of [%s]: %v", user.Username, err)
+		return nil, err
+	}
+	uc.tokenVersions.Invalidate(user.ID)
+	return tokens, nil
+}
+
+// Refresh trades a refresh token for a new access token and a new refresh
+// token. A refresh token works once: presenting one that was already replaced
+// means it leaked, so every session of its user is ended.
+func (uc *loginUseCase) Refresh(refreshToken string) (*model.TokenResponse, error) {
+	var tokens *model.TokenResponse
+	reused := false
+	err := uc.refreshTokenRepository.GetDB().Transaction(func(tx *gorm.DB) error {
+		refreshTokenRepo := uc.refreshTokenRepository.WithTx(tx)
+		stored, err := refreshTokenRepo.GetRefreshTokenByHashForUpdate(hashRefreshToken(refreshToken))
+		if err != nil {
+			return err
+		}
+		if stored == nil || stored.ExpiresAt.Before(time.Now()) {
+			return utils.ErrInvalidRefreshToken
+		}
+		if stored.RevokedAt != nil {
+			if stored.ReplacedBy == "" {
+				return utils.ErrInvalidRefreshToken
+			}
+			reused = true
+			return refreshTokenRepo.RevokeUserRefreshTokens(stored.UserID)
+		}
+
+		user, err := uc.userRepository.WithTx(tx).GetUserByID(stored.UserID)
+		if err != nil {
+			return err
+		}
+		if user == nil || !user.IsActive {
+			return utils.ErrInvalidRefreshToken
+		}
+		var replacement *model.RefreshToken
+		tokens, replacement, err = uc.issueTokens(refreshTokenRepo, user)
+		if err != nil {
+			return err
+		}
+		return refreshTokenRepo.RevokeRefreshToken(stored.ID, replacement.ID)
+	})
+	if reused {
+		logrus.Warn("Replaced refresh token presented again, all sessions of its user are revoked")
+		return nil, utils.ErrInvalidRefreshToken
+	}
+	if err != nil {
+		return nil, err
+	}
+	return tokens, nil
+}
+
+// Logout revokes a refresh token. Access tokens already handed out stay valid
+// until they expire.
+func (uc *loginUseCase) Logout(refreshToken string) error {
+	return uc.refreshTokenRepository.GetDB().Transaction(func(tx *gorm.DB) error {
+		refreshTokenRepo := uc.refreshTokenRepository.WithTx(tx)
+		stored, err := refreshTokenRepo.GetRefreshTokenByHashForUpdate(hashRefreshToken(refreshToken))
+		if err != nil {
+			return err
+		}
+		if stored == nil {
+			return utils.ErrInvalidRefreshToken
+		}
+		if stored.RevokedAt != nil {
+			return nil
+		}
+		return refreshTokenRepo.RevokeRefreshToken(stored.ID, "")
+	})
+}
+
+// issueTokens signs an access token for user and stores a new refresh token
+// with refreshTokenRepo.
+func (uc *loginUseCase) issueTokens(refreshTokenRepo repository.RefreshTokenRepository, user *model.User) (*model.TokenResponse, *model.RefreshToken, error) {
+	accessToken, err := generateJWTToken(uc.jwtConfig, user)
+	if err != nil {
+		return nil, nil, err
+	}
+	refreshToken, err := newRefreshToken()
+	if err != nil {
+		return nil, nil, err
+	}
+	stored := &model.RefreshToken{
+		ID:        uuid.NewString(),
+		UserID:    user.ID,
+		TokenHash: hashRefreshToken(refreshToken),
+		ExpiresAt: time.Now().Add(uc.jwtConfig.RefreshTokenTTL),
+	}
+	if err := refreshTokenRepo.CreateRefreshToken(stored); err != nil {
+		return nil, nil, err
+	}
+
+	return &model.TokenResponse{
+		AccessToken:            accessToken,
+		RefreshToken:           refreshToken,
+		TokenType:              "Bearer",
+		ExpiresIn:              int64(uc.jwtConfig.AccessTokenTTL.Seconds()),
+		PasswordChangeRequired: user.MustChangePassword,
+	}, stored, nil
+}
+
+func generateJWTToken(jwtConfig config.JWTConfig, user *model.User) (string, error) {
+	// Membuat claim JWT; ver adalah token version user saat token dibuat
+	now := time.Now()
 	claims := jwt.MapClaims{
-		"user_id":  userID,
-		"username": username,
-		"role":     role,
-		"exp":      time.Now().Add(time.Hour * 24).Unix(), // Token berlaku selama 1 hari
+		"user_id":  user.ID,
+		"username": user.Username,
+		"role":     user.Role,
+		"ver":      user.TokenVersion,
+		"iat":      now.Unix(),
+		"exp":      now.Add(jwtConfig.AccessTokenTTL).Unix(),
+	}
+	if user.MustChangePassword {
+		claims["pwd_change"] = true
 	}
 
-	// Membuat token JWT dengan menggunakan secret key
+	// Membuat token JWT yang ditandatangani dengan kunci aktif; header kid
+	// memberi tahu verifikasi kunci mana yang dipakai
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	secretKey := []byte("secret-key") // Ganti dengan secret key Anda sendiri
-	signedToken, err := token.SignedString(secretKey)
+	token.Header["kid"] = jwtConfig.ActiveKeyID
+	signedToken, err := token.SignedString(jwtConfig.Keys[jwtConfig.ActiveKeyID])
 	if err != nil {
 		return "", err
 	}
 
 	return signedToken, nil
 }
+
+func newRefreshToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+func hashRefreshToken(refreshToken string) string {
+	sum := sha256.Sum256([]byte(refreshToken))
+	return hex.EncodeToString(sum[:])
+}
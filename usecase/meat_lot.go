@@ -0,0 +1,149 @@
+package usecase
+
+import (
+	"math"
+	"time"
+	"trackprosto/delivery/utils"
+	model "trackprosto/models"
+	"trackprosto/repository"
+
+	"github.com/google/uuid"
+)
+
+// lotQtyTolerance absorbs float rounding when splitting a quantity over lots.
+const lotQtyTolerance = 1e-9
+
+// moveStock writes movement to the stock journal and keeps the meat's lots in
+// step with it. Stock taken out is consumed from the lots in the meat's picking
+// order, stock put back is received as a new lot at the last known cost.
+// Purchases, sales and voids manage their own lots and call MoveStock directly.
+func moveStock(meatRepo repository.MeatRepository, lotRepo repository.MeatLotRepository, meat *model.Meat, movement *model.StockMovement) error {
+	if movement.ID == "" {
+		movement.ID = uuid.NewString()
+	}
+	if movement.Qty < 0 {
+		if _, err := consumeLots(lotRepo, meat, -movement.Qty, movement); err != nil {
+			return err
+		}
+	}
+	if movement.Qty > 0 {
+		cost, err := lotRepo.GetLastCostPrice(meat.ID)
+		if err != nil {
+			return err
+		}
+		receivedDate := time.Now().Format("2006-01-02")
+		expiryDate, err := lotExpiryDate(meat, receivedDate, "")
+		if err != nil {
+			return err
+		}
+		reference := movement.Reference
+		if reference == "" {
+			reference = movement.SourceType
+		}
+		err = lotRepo.CreateLot(&model.MeatLot{
+			MeatID:       meat.ID,
+			MeatName:     meat.Name,
+			Reference:    reference,
+			ReceivedDate: receivedDate,
+			ExpiryDate:   expiryDate,
+			CostPrice:    cost,
+			QtyReceived:  movement.Qty,
+			QtyRemaining: movement.Qty,
+			IsActive:     true,
+			CreatedBy:    movement.CreatedBy,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return meatRepo.MoveStock(movement)
+}
+
+// consumeLots takes qty of a meat out of its lots. Whatever the lots cannot
+// cover is recorded as an untracked consumption at the last known cost.
+func consumeLots(lotRepo repository.MeatLotRepository, meat *model.Meat, qty float64, movement *model.StockMovement) ([]*model.LotConsumption, error) {
+	lots, err := lotRepo.GetAvailableLotsForUpdate(meat.ID, meat.PickingMethod)
+	if err != nil {
+		return nil, err
+	}
+
+	var consumptions []*model.LotConsumption
+	remaining := qty
+	for _, lot := range lots {
+		if remaining <= lotQtyTolerance {
+			break
+		}
+		take := math.Min(remaining, lot.QtyRemaining)
+		if err := lotRepo.UpdateLotRemaining(lot.ID, -take); err != nil {
+			return nil, err
+		}
+		consumptions = append(consumptions, newLotConsumption(lot.ID, lot.CostPrice, take, meat, movement))
+		remaining -= take
+	}
+	if remaining > lotQtyTolerance {
+		cost, err := lotRepo.GetLastCostPrice(meat.ID)
+		if err != nil {
+			return nil, err
+		}
+		consumptions = append(consumptions, newLotConsumption("", cost, remaining, meat, movement))
+	}
+
+	for _, consumption := range consumptions {
+		if err := lotRepo.CreateConsumption(consumption); err != nil {
+			return nil, err
+		}
+	}
+	return consumptions, nil
+}
+
+// restoreLots puts back what a source consumed into the lots it came from.
+func restoreLots(lotRepo repository.MeatLotRepository, sourceType string, sourceID string) error {
+	consumptions, err := lotRepo.GetConsumptionsBySource(sourceType, sourceID)
+	if err != nil {
+		return err
+	}
+	for _, consumption := range consumptions {
+		if consumption.LotID == "" {
+			continue
+		}
+		if err := lotRepo.UpdateLotRemaining(consumption.LotID, consumption.Qty); err != nil {
+			return err
+		}
+	}
+	return lotRepo.DeactivateConsumptionsBySource(sourceType, sourceID)
+}
+
+func newLotConsumption(lotID string, unitCost float64, qty float64, meat *model.Meat, movement *model.StockMovement) *model.LotConsumption {
+	return &model.LotConsumption{
+		ID:         uuid.NewString(),
+		LotID:      lotID,
+		MeatID:     meat.ID,
+		MovementID: movement.ID,
+		SourceType: movement.SourceType,
+		SourceID:   movement.SourceID,
+		Qty:        qty,
+		UnitCost:   unitCost,
+		IsActive:   true,
+		CreatedBy:  movement.CreatedBy,
+	}
+}
+
+// lotExpiryDate returns the given expiry date, or one derived from the meat's
+// shelf life when none is given. Nil means the lot does not expire.
+func lotExpiryDate(meat *model.Meat, receivedDate string, expiryDate string) (*string, error) {
+	if expiryDate != "" {
+		if _, err := time.Parse("2006-01-02", expiryDate); err != nil {
+			return nil, utils.ErrInvalidExpiryDate
+		}
+		return &expiryDate, nil
+	}
+	if meat.ShelfLifeDays <= 0 {
+		return nil, nil
+	}
+	received, err := time.Parse("2006-01-02", receivedDate)
+	if err != nil {
+		return nil, err
+	}
+	expiry := received.AddDate(0, 0, meat.ShelfLifeDays).Format("2006-01-02")
+	return &expiry, nil
+}
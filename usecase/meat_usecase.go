@@ -2,12 +2,16 @@ package usecase
 
 import (
 	"fmt"
+	"io"
+	"strings"
 	"time"
 	"trackprosto/delivery/utils"
 	model "trackprosto/models"
 	"trackprosto/repository"
 
+	"github.com/google/uuid"
 	log "github.com/sirupsen/logrus"
+	"gorm.io/gorm"
 )
 
 type MeatUseCase interface {
@@ -17,28 +21,45 @@ type MeatUseCase interface {
 	GetMeatByName(string) (*model.Meat, error)
 	UpdateMeat(meat *model.Meat) error
 	DeleteMeat(string) error
+	GetStockMovements(meatID string, startDate string, endDate string) ([]*model.StockMovement, error)
+	AdjustStock(meatID string, request *model.StockAdjustmentRequest, adjustedBy string) (*model.StockMovement, error)
+	GetMeatLots(meatID string, includeEmpty bool) ([]*model.MeatLot, error)
+	GetExpiringLots(days int) ([]*model.MeatLot, error)
+	ImportMeats(file io.Reader, dryRun bool, importedBy string) (*model.ImportResult, error)
 }
 
 type meatUseCase struct {
 	meatRepository repository.MeatRepository
 	txRepository   repository.TransactionRepository
+	meatLotRepo    repository.MeatLotRepository
 }
 
-func NewMeatUseCase(meatRepo repository.MeatRepository, txRepository repository.TransactionRepository) MeatUseCase {
+func NewMeatUseCase(meatRepo repository.MeatRepository, txRepository repository.TransactionRepository, meatLotRepo repository.MeatLotRepository) MeatUseCase {
 	return &meatUseCase{
 		meatRepository: meatRepo,
 		txRepository:   txRepository,
+		meatLotRepo:    meatLotRepo,
 	}
 }
 
 func (ms *meatUseCase) CreateMeat(meat *model.Meat) error {
-	isExist, _ := ms.meatRepository.GetMeatByName(meat.Name)
+	isExist, err := ms.meatRepository.GetMeatByName(meat.Name)
+	if err != nil {
+		log.WithField("error", err).Error("Failed to check meat name existence")
+		return err
+	}
 	if isExist != nil {
 		log.WithField("meatName", meat.Name).Error("Meat name already exists")
 		return utils.ErrMeatNameAlreadyExist
 	}
 	meat.IsActive = true
-	err := ms.meatRepository.CreateMeat(meat)
+	meat.PickingMethod = utils.NonEmpty(meat.PickingMethod, model.PickingFIFO)
+	if !model.IsValidPickingMethod(meat.PickingMethod) {
+		return utils.ErrInvalidPickingMethod
+	}
+	err = ms.txRepository.GetDB().Transaction(func(tx *gorm.DB) error {
+		return createMeat(ms.meatRepository.WithTx(tx), ms.meatLotRepo.WithTx(tx), meat)
+	})
 	if err != nil {
 		log.WithField("error", err).Error("Failed to create meat")
 		return err
@@ -47,6 +68,95 @@ func (ms *meatUseCase) CreateMeat(meat *model.Meat) error {
 	return nil
 }
 
+// createMeat stores a new meat and books its stock as the opening stock.
+func createMeat(meatRepo repository.MeatRepository, lotRepo repository.MeatLotRepository, meat *model.Meat) error {
+	openingStock := meat.Stock
+	meat.Stock = 0
+	if err := meatRepo.CreateMeat(meat); err != nil {
+		return err
+	}
+	if openingStock != 0 {
+		err := moveStock(meatRepo, lotRepo, meat, &model.StockMovement{
+			MeatID:     meat.ID,
+			Qty:        openingStock,
+			SourceType: model.StockSourceAdjustment,
+			SourceID:   meat.ID,
+			Notes:      "Opening stock",
+			CreatedBy:  meat.CreatedBy,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	meat.Stock = openingStock
+	return nil
+}
+
+// ImportMeats creates the meats listed in a CSV file with the columns name,
+// price, stock, picking_method and shelf_life_days, of which only name is
+// required. Nothing is stored unless every row is valid.
+func (uc *meatUseCase) ImportMeats(file io.Reader, dryRun bool, importedBy string) (*model.ImportResult, error) {
+	rows, err := readImportFile(file, "name")
+	if err != nil {
+		return nil, err
+	}
+
+	result := &model.ImportResult{DryRun: dryRun, Rows: len(rows)}
+	meats := make([]*model.Meat, 0, len(rows))
+	seen := make(map[string]int)
+	for _, row := range rows {
+		meat := &model.Meat{
+			ID:            uuid.NewString(),
+			Name:          row.required("name", result),
+			Price:         row.float("price", result),
+			Stock:         row.float("stock", result),
+			PickingMethod: utils.NonEmpty(strings.ToLower(row.get("picking_method")), model.PickingFIFO),
+			ShelfLifeDays: row.int("shelf_life_days", result),
+			IsActive:      true,
+			CreatedBy:     importedBy,
+			UpdatedBy:     importedBy,
+		}
+		if !model.IsValidPickingMethod(meat.PickingMethod) {
+			result.AddError(row.line, "picking_method", utils.ErrInvalidPickingMethod.Error())
+		}
+		if meat.Name != "" {
+			if line, ok := seen[strings.ToLower(meat.Name)]; ok {
+				result.AddError(row.line, "name", fmt.Sprintf("%s is already on line %d", meat.Name, line))
+			} else {
+				existing, err := uc.meatRepository.GetMeatByName(meat.Name)
+				if err != nil {
+					return nil, err
+				}
+				if existing != nil {
+					result.AddError(row.line, "name", utils.ErrMeatNameAlreadyExist.Error())
+				}
+			}
+			seen[strings.ToLower(meat.Name)] = row.line
+		}
+		meats = append(meats, meat)
+	}
+	if dryRun || len(result.Errors) > 0 {
+		return result, nil
+	}
+
+	err = uc.txRepository.GetDB().Transaction(func(tx *gorm.DB) error {
+		meatRepo := uc.meatRepository.WithTx(tx)
+		lotRepo := uc.meatLotRepo.WithTx(tx)
+		for _, meat := range meats {
+			if err := createMeat(meatRepo, lotRepo, meat); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		log.WithField("error", err).Error("Failed to import meats")
+		return nil, err
+	}
+	result.Imported = len(meats)
+	return result, nil
+}
+
 func (mc *meatUseCase) GetAllMeats(page int, itemsPerPage int) ([]*model.MeatWithStock, int, error) {
 	meats, totalPages, err := mc.meatRepository.GetAllMeats(page, itemsPerPage)
 	if err != nil {
@@ -106,8 +216,7 @@ func (mc *meatUseCase) DeleteMeat(id string) error {
 		log.WithField("meatName", id).Error("Meat name not found")
 		return utils.ErrMeatNotFound
 	}
-	existingMeat.IsActive = false
-	err = mc.meatRepository.UpdateMeat(existingMeat)
+	err = mc.meatRepository.DeleteMeat(existingMeat.ID)
 	if err != nil {
 		log.WithField("error", err).Error("Failed to delete meat")
 		return err
@@ -128,7 +237,11 @@ func (uc *meatUseCase) UpdateMeat(meat *model.Meat) error {
 		log.WithField("error", err).Error("Failed to get meat by ID")
 		return fmt.Errorf("failed to get meat by ID: %v", err)
 	}
-	existingMeat, _ := uc.meatRepository.GetMeatByName(meat.Name)
+	existingMeat, err := uc.meatRepository.GetMeatByName(meat.Name)
+	if err != nil {
+		log.WithField("error", err).Error("Failed to check meat name existence")
+		return err
+	}
 	if existingMeat != nil && existingMeat.ID != meat.ID {
 		log.WithField("meatName", meat.Name).Error("Meat name already exists")
 		return utils.ErrMeatNameAlreadyExist
@@ -136,14 +249,154 @@ func (uc *meatUseCase) UpdateMeat(meat *model.Meat) error {
 	meat.CreatedBy = currentMeatValue.CreatedBy
 	meat.CreatedAt = currentMeatValue.CreatedAt
 	meat.Name = utils.NonEmpty(meat.Name, currentMeatValue.Name)
-	meat.Stock = utils.NonZero(meat.Stock, currentMeatValue.Stock)
 	meat.Price = utils.NonZero(meat.Price, currentMeatValue.Price)
+	meat.PickingMethod = utils.NonEmpty(meat.PickingMethod, currentMeatValue.PickingMethod)
+	if !model.IsValidPickingMethod(meat.PickingMethod) {
+		return utils.ErrInvalidPickingMethod
+	}
+	if meat.ShelfLifeDays == 0 {
+		meat.ShelfLifeDays = currentMeatValue.ShelfLifeDays
+	}
 	meat.IsActive = currentMeatValue.IsActive
 	meat.UpdatedAt = time.Now()
-	err = uc.meatRepository.UpdateMeat(meat)
+	// The stock itself is only changed through the journal, as an adjustment
+	// against the stock read under lock.
+	var newStock float64
+	err = uc.txRepository.GetDB().Transaction(func(tx *gorm.DB) error {
+		meatRepo := uc.meatRepository.WithTx(tx)
+		lockedMeat, err := meatRepo.GetMeatByIDForUpdate(meat.ID)
+		if err != nil {
+			return err
+		}
+		if lockedMeat == nil {
+			return utils.ErrMeatNotFound
+		}
+		newStock = utils.NonZero(meat.Stock, lockedMeat.Stock)
+		meat.Stock = lockedMeat.Stock
+		if err := meatRepo.UpdateMeat(meat); err != nil {
+			return err
+		}
+		if newStock == lockedMeat.Stock {
+			return nil
+		}
+		return moveStock(meatRepo, uc.meatLotRepo.WithTx(tx), meat, &model.StockMovement{
+			MeatID:     meat.ID,
+			Qty:        newStock - lockedMeat.Stock,
+			SourceType: model.StockSourceAdjustment,
+			SourceID:   meat.ID,
+			Notes:      "Stock updated on meat",
+			CreatedBy:  meat.UpdatedBy,
+		})
+	})
 	if err != nil {
 		log.WithField("error", err).Error("Failed to update meat")
 		return err
 	}
+	meat.Stock = newStock
 	return nil
 }
+
+func (uc *meatUseCase) GetStockMovements(meatID string, startDate string, endDate string) ([]*model.StockMovement, error) {
+	meat, err := uc.meatRepository.GetMeatByID(meatID)
+	if err != nil {
+		log.WithField("error", err).Error("Failed to get meat by ID")
+		return nil, err
+	}
+	if meat == nil {
+		return nil, utils.ErrMeatNotFound
+	}
+	movements, err := uc.meatRepository.GetStockMovements(meatID, startDate, endDate)
+	if err != nil {
+		log.WithField("error", err).Error("Failed to get stock movements")
+		return nil, err
+	}
+	return movements, nil
+}
+
+// AdjustStock books a manual stock correction of request.Qty kg (signed).
+func (uc *meatUseCase) AdjustStock(meatID string, request *model.StockAdjustmentRequest, adjustedBy string) (*model.StockMovement, error) {
+	if !model.IsValidReasonCode(request.ReasonCode) {
+		return nil, utils.ErrInvalidReasonCode
+	}
+	if request.Qty == 0 {
+		return nil, utils.ErrInvalidQty
+	}
+
+	movement := &model.StockMovement{
+		MeatID:     meatID,
+		Qty:        request.Qty,
+		SourceType: model.AdjustmentSourceType(request.ReasonCode),
+		SourceID:   meatID,
+		ReasonCode: request.ReasonCode,
+		Notes:      request.Notes,
+		CreatedBy:  adjustedBy,
+	}
+	err := uc.txRepository.GetDB().Transaction(func(tx *gorm.DB) error {
+		meatRepo := uc.meatRepository.WithTx(tx)
+		meat, err := meatRepo.GetMeatByIDForUpdate(meatID)
+		if err != nil {
+			return err
+		}
+		if meat == nil {
+			return utils.ErrMeatNotFound
+		}
+		if meat.Stock+request.Qty < 0 {
+			return utils.ErrMeatStockNotEnough
+		}
+		return moveStock(meatRepo, uc.meatLotRepo.WithTx(tx), meat, movement)
+	})
+	if err != nil {
+		log.WithFields(log.Fields{
+			"meatID": meatID,
+			"error":  err,
+		}).Error("Failed to adjust meat stock")
+		return nil, err
+	}
+	return movement, nil
+}
+
+func (uc *meatUseCase) GetMeatLots(meatID string, includeEmpty bool) ([]*model.MeatLot, error) {
+	meat, err := uc.meatRepository.GetMeatByID(meatID)
+	if err != nil {
+		log.WithField("error", err).Error("Failed to get meat by ID")
+		return nil, err
+	}
+	if meat == nil {
+		return nil, utils.ErrMeatNotFound
+	}
+	lots, err := uc.meatLotRepo.GetLotsByMeatID(meatID, includeEmpty)
+	if err != nil {
+		log.WithField("error", err).Error("Failed to get meat lots")
+		return nil, err
+	}
+	setDaysToExpiry(lots)
+	return lots, nil
+}
+
+// GetExpiringLots returns the lots with stock left that expire within the
+// given number of days, already expired lots included.
+func (uc *meatUseCase) GetExpiringLots(days int) ([]*model.MeatLot, error) {
+	until := time.Now().AddDate(0, 0, days).Format("2006-01-02")
+	lots, err := uc.meatLotRepo.GetLotsExpiringBefore(until)
+	if err != nil {
+		log.WithField("error", err).Error("Failed to get expiring lots")
+		return nil, err
+	}
+	setDaysToExpiry(lots)
+	return lots, nil
+}
+
+func setDaysToExpiry(lots []*model.MeatLot) {
+	today, _ := time.Parse("2006-01-02", time.Now().Format("2006-01-02"))
+	for _, lot := range lots {
+		if lot.ExpiryDate == nil {
+			continue
+		}
+		expiry, err := time.Parse("2006-01-02", (*lot.ExpiryDate)[:min(len(*lot.ExpiryDate), 10)])
+		if err != nil {
+			continue
+		}
+		days := int(expiry.Sub(today).Hours() / 24)
+		lot.DaysToExpiry = &days
+	}
+}
@@ -0,0 +1,68 @@
+package usecase
+
+import (
+	"time"
+	"trackprosto/delivery/utils"
+	model "trackprosto/models"
+	"trackprosto/repository"
+
+	"github.com/sirupsen/logrus"
+)
+
+type NumberingSchemeUseCase interface {
+	GetAllNumberingSchemes() ([]*model.NumberingScheme, error)
+	UpdateNumberingScheme(documentType string, request *model.NumberingSchemeRequest, updatedBy string) (*model.NumberingScheme, error)
+}
+
+type numberingSchemeUseCase struct {
+	numberingSchemeRepo repository.NumberingSchemeRepository
+}
+
+func NewNumberingSchemeUseCase(numberingSchemeRepo repository.NumberingSchemeRepository) NumberingSchemeUseCase {
+	return &numberingSchemeUseCase{
+		numberingSchemeRepo: numberingSchemeRepo,
+	}
+}
+
+func (uc *numberingSchemeUseCase) GetAllNumberingSchemes() ([]*model.NumberingScheme, error) {
+	schemes, err := uc.numberingSchemeRepo.GetAllNumberingSchemes()
+	if err != nil {
+		logrus.WithField("error", err).Error("Failed to get numbering schemes")
+		return nil, err
+	}
+	now := time.Now()
+	for _, scheme := range schemes {
+		scheme.Example = renderDocumentNumber(scheme, now, 1)
+	}
+	return schemes, nil
+}
+
+// UpdateNumberingScheme changes how new numbers of a document type look.
+// Numbers already issued are not touched.
+func (uc *numberingSchemeUseCase) UpdateNumberingScheme(documentType string, request *model.NumberingSchemeRequest, updatedBy string) (*model.NumberingScheme, error) {
+	scheme, err := uc.numberingSchemeRepo.GetNumberingScheme(documentType)
+	if err != nil {
+		return nil, err
+	}
+	if scheme == nil {
+		return nil, utils.ErrNumberingSchemeNotFound
+	}
+
+	scheme.Prefix = request.Prefix
+	scheme.Template = request.Template
+	scheme.ResetPeriod = request.ResetPeriod
+	if err := validateNumberingScheme(scheme); err != nil {
+		return nil, err
+	}
+	scheme.UpdatedAt = time.Now()
+	scheme.UpdatedBy = updatedBy
+	if err := uc.numberingSchemeRepo.UpdateNumberingScheme(scheme); err != nil {
+		logrus.WithFields(logrus.Fields{
+			"error":        err,
+			"documentType": documentType,
+		}).Error("Failed to update numbering scheme")
+		return nil, err
+	}
+	scheme.Example = renderDocumentNumber(scheme, scheme.UpdatedAt, 1)
+	return scheme, nil
+}
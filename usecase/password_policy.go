@@ -0,0 +1,59 @@
+package usecase
+
+import (
+	"strings"
+	"trackprosto/config"
+	"trackprosto/delivery/utils"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// bcrypt ignores everything past the first 72 bytes of a password.
+const maxPasswordBytes = 72
+
+// validatePassword checks a new password of the user against the password
+// policy.
+func validatePassword(policy config.PasswordPolicy, username, password string) error {
+	if utf8.RuneCountInString(password) < policy.MinLength {
+		return utils.ErrPasswordTooShort
+	}
+	if len(password) > maxPasswordBytes {
+		return utils.ErrPasswordTooLong
+	}
+	var hasUpper, hasLower, hasDigit, hasSymbol bool
+	for _, r := range password {
+		switch {
+		case unicode.IsUpper(r):
+			hasUpper = true
+		case unicode.IsLower(r):
+			hasLower = true
+		case unicode.IsDigit(r):
+			hasDigit = true
+		default:
+			hasSymbol = true
+		}
+	}
+	if (policy.RequireUpper && !hasUpper) || (policy.RequireLower && !hasLower) ||
+		(policy.RequireDigit && !hasDigit) || (policy.RequireSymbol && !hasSymbol) {
+		return utils.ErrPasswordTooWeak
+	}
+	if username != "" && strings.Contains(strings.ToLower(password), strings.ToLower(username)) {
+		return utils.ErrPasswordContainsUsername
+	}
+	return nil
+}
+
+// hashPassword validates a new password and returns its bcrypt hash, the only
+// form passwords are stored in.
+func hashPassword(policy config.PasswordPolicy, username, password string) (string, error) {
+	if err := validatePassword(policy, username, password); err != nil {
+		return "", err
+	}
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return "", err
+	}
+	return string(hashedPassword), nil
+}
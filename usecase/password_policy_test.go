@@ -0,0 +1,59 @@
+package usecase
+
+import (
+	"strings"
+	"testing"
+	"trackprosto/config"
+	"trackprosto/delivery/utils"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestValidatePassword(t *testing.T) {
+	defaultPolicy := config.PasswordPolicy{MinLength: 8, RequireUpper: true, RequireLower: true, RequireDigit: true}
+	strictPolicy := config.PasswordPolicy{MinLength: 12, RequireUpper: true, RequireLower: true, RequireDigit: true, RequireSymbol: true}
+	loosePolicy := config.PasswordPolicy{MinLength: 4}
+	tests := []struct {
+		name     string
+		policy   config.PasswordPolicy
+		username string
+		password string
+		want     error
+	}{
+		{"meets policy", defaultPolicy, "budi", "Daging2026", nil},
+		{"too short", defaultPolicy, "budi", "Dg2026", utils.ErrPasswordTooShort},
+		{"length counts characters", loosePolicy, "budi", "ÄÖÜß", nil},
+		{"too long for bcrypt", defaultPolicy, "budi", "Aa1" + strings.Repeat("x", 70), utils.ErrPasswordTooLong},
+		{"missing upper case", defaultPolicy, "budi", "daging2026", utils.ErrPasswordTooWeak},
+		{"missing lower case", defaultPolicy, "budi", "DAGING2026", utils.ErrPasswordTooWeak},
+		{"missing digit", defaultPolicy, "budi", "DagingSapi", utils.ErrPasswordTooWeak},
+		{"missing symbol", strictPolicy, "budi", "DagingSapi2026", utils.ErrPasswordTooWeak},
+		{"with symbol", strictPolicy, "budi", "Daging-Sapi2026", nil},
+		{"nothing required", loosePolicy, "budi", "aaaa", nil},
+		{"contains username", defaultPolicy, "budi", "xBudi2026x", utils.ErrPasswordContainsUsername},
+		{"no username given", defaultPolicy, "", "Daging2026", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := validatePassword(tt.policy, tt.username, tt.password); got != tt.want {
+				t.Errorf("validatePassword(%q) = %v, want %v", tt.password, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHashPassword(t *testing.T) {
+	policy := config.PasswordPolicy{MinLength: 8, RequireUpper: true, RequireLower: true, RequireDigit: true}
+
+	hashed, err := hashPassword(policy, "budi", "Daging2026")
+	if err != nil {
+		t.Fatalf("hashPassword() error = %v", err)
+	}
+	if err := bcrypt.CompareHashAndPassword([]byte(hashed), []byte("Daging2026")); err != nil {
+		t.Errorf("hash does not match the password: %v", err)
+	}
+
+	if _, err := hashPassword(policy, "budi", "weak"); err != utils.ErrPasswordTooShort {
+		t.Errorf("hashPassword(weak) error = %v, want %v", err, utils.ErrPasswordTooShort)
+	}
+}
@@ -0,0 +1,104 @@
+package usecase
+
+import (
+	"slices"
+	"trackprosto/delivery/utils"
+	model "trackprosto/models"
+	"trackprosto/repository"
+	"trackprosto/utils/rolepermission"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+type PermissionUseCase interface {
+	GetAllPermissions() ([]*model.Permission, error)
+	GetAllRolePermissions() ([]*model.RolePermissions, error)
+	GetRolePermissions(role string) (*model.RolePermissions, error)
+	UpdateRolePermissions(role string, request *model.RolePermissionsRequest, callerRole string) (*model.RolePermissions, error)
+}
+
+type permissionUseCase struct {
+	permissionRepo  repository.PermissionRepository
+	rolePermissions *rolepermission.Cache
+}
+
+func NewPermissionUseCase(permissionRepo repository.PermissionRepository, rolePermissions *rolepermission.Cache) PermissionUseCase {
+	return &permissionUseCase{
+		permissionRepo:  permissionRepo,
+		rolePermissions: rolePermissions,
+	}
+}
+
+func (uc *permissionUseCase) GetAllPermissions() ([]*model.Permission, error) {
+	return uc.permissionRepo.GetAllPermissions()
+}
+
+func (uc *permissionUseCase) GetAllRolePermissions() ([]*model.RolePermissions, error) {
+	grants, err := uc.permissionRepo.GetAllRolePermissions()
+	if err != nil {
+		logrus.WithField("error", err).Error("Failed to get role permissions")
+		return nil, err
+	}
+	roles := make([]*model.RolePermissions, 0, len(model.Roles))
+	for _, role := range model.Roles {
+		roles = append(roles, &model.RolePermissions{Role: role, Permissions: nonNil(grants[role])})
+	}
+	return roles, nil
+}
+
+func (uc *permissionUseCase) GetRolePermissions(role string) (*model.RolePermissions, error) {
+	if !model.IsValidRole(role) {
+		return nil, utils.ErrInvalidRole
+	}
+	grants, err := uc.permissionRepo.GetAllRolePermissions()
+	if err != nil {
+		logrus.WithField("error", err).Error("Failed to get role permissions")
+		return nil, err
+	}
+	return &model.RolePermissions{Role: role, Permissions: nonNil(grants[role])}, nil
+}
+
+// UpdateRolePermissions replaces the permissions of a role. A role cannot
+// take permission:manage away from itself, so the caller cannot lock its own
+// role out of this endpoint.
+func (uc *permissionUseCase) UpdateRolePermissions(role string, request *model.RolePermissionsRequest, callerRole string) (*model.RolePermissions, error) {
+	if !model.IsValidRole(role) {
+		return nil, utils.ErrInvalidRole
+	}
+	known, err := uc.permissionRepo.GetAllPermissions()
+	if err != nil {
+		return nil, err
+	}
+	codes := make([]string, 0, len(request.Permissions))
+	for _, code := range request.Permissions {
+		if !slices.ContainsFunc(known, func(p *model.Permission) bool { return p.Code == code }) {
+			return nil, utils.ErrUnknownPermission
+		}
+		if !slices.Contains(codes, code) {
+			codes = append(codes, code)
+		}
+	}
+	if role == callerRole && !slices.Contains(codes, model.PermissionPermissionManage) {
+		return nil, utils.ErrCannotRevokeOwnPermissionManage
+	}
+	slices.Sort(codes)
+
+	err = uc.permissionRepo.GetDB().Transaction(func(tx *gorm.DB) error {
+		return uc.permissionRepo.WithTx(tx).ReplaceRolePermissions(role, codes)
+	})
+	if err != nil {
+		logrus.WithField("error", err).Error("Failed to update role permissions")
+		return nil, err
+	}
+	uc.rolePermissions.Invalidate()
+	return &model.RolePermissions{Role: role, Permissions: codes}, nil
+}
+
+// nonNil keeps a role without permissions from being returned as null.
+func nonNil(codes []string) []string {
+	if codes == nil {
+		return []string{}
+	}
+	return codes
+}
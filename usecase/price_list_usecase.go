@@ -0,0 +1,197 @@
+package usecase
+
+import (
+	"time"
+	"trackprosto/delivery/utils"
+	model "trackprosto/models"
+	"trackprosto/repository"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// defaultMaxDeviationPct is the allowed price deviation of a list that does
+// not set one.
+const defaultMaxDeviationPct = 10
+
+type PriceListUseCase interface {
+	CreatePriceList(request *model.PriceListRequest, createdBy string) (*model.PriceList, error)
+	UpdatePriceList(id string, request *model.PriceListRequest, updatedBy string) (*model.PriceList, error)
+	GetPriceListByID(id string) (*model.PriceList, error)
+	GetAllPriceLists(activeOn string) ([]*model.PriceList, error)
+	DeletePriceList(id string, deletedBy string) error
+	SuggestPrice(customerID string, meatID string) (*model.PriceSuggestion, error)
+}
+
+type priceListUseCase struct {
+	priceListRepo repository.PriceListRepository
+	meatRepo      repository.MeatRepository
+	companyRepo   repository.CompanyRepository
+	customerRepo  repository.CustomerRepository
+}
+
+func NewPriceListUseCase(priceListRepo repository.PriceListRepository, meatRepo repository.MeatRepository, companyRepo repository.CompanyRepository, customerRepo repository.CustomerRepository) PriceListUseCase {
+	return &priceListUseCase{
+		priceListRepo: priceListRepo,
+		meatRepo:      meatRepo,
+		companyRepo:   companyRepo,
+		customerRepo:  customerRepo,
+	}
+}
+
+func (uc *priceListUseCase) CreatePriceList(request *model.PriceListRequest, createdBy string) (*model.PriceList, error) {
+	priceList := &model.PriceList{
+		ID:        uuid.NewString(),
+		IsActive:  true,
+		CreatedBy: createdBy,
+	}
+	if err := uc.applyRequest(priceList, request, createdBy); err != nil {
+		return nil, err
+	}
+	if err := uc.priceListRepo.CreatePriceList(priceList); err != nil {
+		logrus.WithField("error", err).Error("Failed to create price list")
+		return nil, err
+	}
+	return priceList, nil
+}
+
+func (uc *priceListUseCase) UpdatePriceList(id string, request *model.PriceListRequest, updatedBy string) (*model.PriceList, error) {
+	priceList, err := uc.priceListRepo.GetPriceListByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if priceList == nil {
+		return nil, utils.ErrPriceListNotFound
+	}
+	if err := uc.applyRequest(priceList, request, updatedBy); err != nil {
+		return nil, err
+	}
+	err = uc.priceListRepo.GetDB().Transaction(func(tx *gorm.DB) error {
+		return uc.priceListRepo.WithTx(tx).UpdatePriceList(priceList)
+	})
+	if err != nil {
+		logrus.WithFields(logrus.Fields{
+			"error":       err,
+			"priceListID": id,
+		}).Error("Failed to update price list")
+		return nil, err
+	}
+	return priceList, nil
+}
+
+// applyRequest validates request and copies it onto priceList.
+func (uc *priceListUseCase) applyRequest(priceList *model.PriceList, request *model.PriceListRequest, updatedBy string) error {
+	if !model.IsValidPriceListType(request.ListType) {
+		return utils.ErrInvalidPriceListType
+	}
+	priceList.CompanyID = nil
+	if request.ListType == model.PriceListContract {
+		company, err := uc.companyRepo.GetCompanyById(request.CompanyID)
+		if err != nil {
+			return err
+		}
+		if company == nil {
+			return utils.ErrCompanyNotFound
+		}
+		priceList.CompanyID = &company.ID
+	}
+
+	validFrom, err := time.Parse("2006-01-02", request.ValidFrom)
+	if err != nil {
+		return utils.ErrInvalidDate
+	}
+	priceList.ValidTo = nil
+	if request.ValidTo != "" {
+		validTo, err := time.Parse("2006-01-02", request.ValidTo)
+		if err != nil {
+			return utils.ErrInvalidDate
+		}
+		if validTo.Before(validFrom) {
+			return utils.ErrInvalidDate
+		}
+		priceList.ValidTo = &request.ValidTo
+	}
+
+	priceList.MaxDeviationPct = defaultMaxDeviationPct
+	if request.MaxDeviationPct != nil {
+		if *request.MaxDeviationPct < 0 {
+			return utils.ErrInvalidMaxDeviation
+		}
+		priceList.MaxDeviationPct = *request.MaxDeviationPct
+	}
+
+	seen := make(map[string]bool, len(request.Items))
+	for _, item := range request.Items {
+		if item.Price <= 0 {
+			return utils.ErrInvalidPrice
+		}
+		if seen[item.MeatID] {
+			return utils.ErrDuplicatePriceListItem
+		}
+		seen[item.MeatID] = true
+		meat, err := uc.meatRepo.GetMeatByID(item.MeatID)
+		if err != nil {
+			return err
+		}
+		if meat == nil {
+			return utils.ErrMeatNotFound
+		}
+		item.PriceListID = priceList.ID
+	}
+
+	priceList.Name = request.Name
+	priceList.ListType = request.ListType
+	priceList.ValidFrom = request.ValidFrom
+	priceList.Items = request.Items
+	priceList.UpdatedBy = updatedBy
+	return nil
+}
+
+func (uc *priceListUseCase) GetPriceListByID(id string) (*model.PriceList, error) {
+	priceList, err := uc.priceListRepo.GetPriceListByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if priceList == nil {
+		return nil, utils.ErrPriceListNotFound
+	}
+	return priceList, nil
+}
+
+func (uc *priceListUseCase) GetAllPriceLists(activeOn string) ([]*model.PriceList, error) {
+	if activeOn != "" {
+		if _, err := time.Parse("2006-01-02", activeOn); err != nil {
+			return nil, utils.ErrInvalidDate
+		}
+	}
+	return uc.priceListRepo.GetAllPriceLists(activeOn)
+}
+
+func (uc *priceListUseCase) DeletePriceList(id string, deletedBy string) error {
+	priceList, err := uc.priceListRepo.GetPriceListByID(id)
+	if err != nil {
+		return err
+	}
+	if priceList == nil {
+		return utils.ErrPriceListNotFound
+	}
+	return uc.priceListRepo.DeactivatePriceList(id, deletedBy)
+}
+
+// SuggestPrice returns the price a sale of the meat to the customer would get
+// today when the cashier leaves the price out.
+func (uc *priceListUseCase) SuggestPrice(customerID string, meatID string) (*model.PriceSuggestion, error) {
+	customer, err := uc.customerRepo.GetCustomerById(customerID)
+	if err != nil || customer == nil {
+		return nil, utils.ErrCustomerNotFound
+	}
+	meat, err := uc.meatRepo.GetMeatByID(meatID)
+	if err != nil {
+		return nil, err
+	}
+	if meat == nil {
+		return nil, utils.ErrMeatNotFound
+	}
+	return suggestPrice(uc.priceListRepo, customer, meat, time.Now().Format("2006-01-02"))
+}
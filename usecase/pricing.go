@@ -0,0 +1,48 @@
+package usecase
+
+import (
+	"math"
+	model "trackprosto/models"
+	"trackprosto/repository"
+)
+
+// suggestPrice returns the price a customer pays for a meat on date: the
+// contract price of the customer's company, else the price of the customer's
+// tier, else the retail price, else the meat's own price.
+func suggestPrice(priceListRepo repository.PriceListRepository, customer *model.CustomerModel, meat *model.Meat, date string) (*model.PriceSuggestion, error) {
+	lookups := []string{model.PriceListContract}
+	if customer.PriceTier == model.PriceListWholesale {
+		lookups = append(lookups, model.PriceListWholesale)
+	}
+	lookups = append(lookups, model.PriceListRetail)
+
+	for _, listType := range lookups {
+		suggestion, err := priceListRepo.FindPrice(meat.ID, listType, customer.CompanyId, date)
+		if err != nil {
+			return nil, err
+		}
+		if suggestion != nil {
+			suggestion.MeatName = meat.Name
+			return suggestion, nil
+		}
+	}
+	return &model.PriceSuggestion{
+		MeatID:   meat.ID,
+		MeatName: meat.Name,
+		Price:    meat.Price,
+	}, nil
+}
+
+// priceDeviationTolerance absorbs float rounding in the deviation percentage,
+// so a price exactly at the allowed deviation is accepted.
+const priceDeviationTolerance = 1e-9
+
+// priceDeviates tells whether price is further from a list price than the list
+// allows. Prices not taken from a list never deviate.
+func priceDeviates(suggestion *model.PriceSuggestion, price float64) bool {
+	if suggestion.PriceListID == "" || suggestion.Price <= 0 {
+		return false
+	}
+	deviationPct := math.Abs(price-suggestion.Price) / suggestion.Price * 100
+	return deviationPct > suggestion.MaxDeviationPct+priceDeviationTolerance
+}
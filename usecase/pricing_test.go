@@ -0,0 +1,36 @@
+package usecase
+
+import (
+	"testing"
+	model "trackprosto/models"
+)
+
+func TestPriceDeviates(t *testing.T) {
+	listed := func(price float64, maxDeviationPct float64) *model.PriceSuggestion {
+		return &model.PriceSuggestion{PriceListID: "pl1", Price: price, MaxDeviationPct: maxDeviationPct}
+	}
+	tests := []struct {
+		name       string
+		suggestion *model.PriceSuggestion
+		price      float64
+		want       bool
+	}{
+		{"list price", listed(100000, 5), 100000, false},
+		{"within deviation", listed(100000, 5), 103000, false},
+		{"at deviation above", listed(100000, 5), 105000, false},
+		{"at deviation below", listed(100000, 5), 95000, false},
+		{"beyond deviation above", listed(100000, 5), 105001, true},
+		{"beyond deviation below", listed(100000, 5), 94999, true},
+		{"at deviation with float rounding", listed(1100, 10), 1210, false},
+		{"no deviation allowed", listed(100000, 0), 100001, true},
+		{"not from a price list", &model.PriceSuggestion{Price: 100000}, 200000, false},
+		{"free list price", listed(0, 5), 1000, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := priceDeviates(tt.suggestion, tt.price); got != tt.want {
+				t.Errorf("priceDeviates(%v, %v) = %v, want %v", tt.suggestion.Price, tt.price, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,433 @@
+package usecase
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+	"trackprosto/config"
+	"trackprosto/delivery/utils"
+	model "trackprosto/models"
+	"trackprosto/repository"
+	"trackprosto/utils/escpos"
+	"trackprosto/utils/pdf"
+
+	"github.com/sirupsen/logrus"
+)
+
+type PrintUseCase interface {
+	GetInvoicePDF(invoiceNumber string) ([]byte, error)
+	GetCreditPaymentReceiptPDF(id string) ([]byte, error)
+	GetInvoiceESCPOS(invoiceNumber string, paperWidth int) ([]byte, error)
+	GetCreditPaymentReceiptESCPOS(id string, paperWidth int) ([]byte, error)
+}
+
+type printUseCase struct {
+	transactionRepo   repository.TransactionRepository
+	creditPaymentRepo repository.CreditPaymentRepository
+	shop              config.ShopConfig
+}
+
+func NewPrintUseCase(transactionRepo repository.TransactionRepository, creditPaymentRepo repository.CreditPaymentRepository, shop config.ShopConfig) PrintUseCase {
+	return &printUseCase{
+		transactionRepo:   transactionRepo,
+		creditPaymentRepo: creditPaymentRepo,
+		shop:              shop,
+	}
+}
+
+// Page layout of the printed documents, in points.
+const (
+	printMarginLeft   = 40.0
+	printMarginRight  = pdf.PageWidth - 40
+	printMarginBottom = pdf.PageHeight - 60
+	printLineHeight   = 15.0
+)
+
+func (uc *printUseCase) GetInvoicePDF(invoiceNumber string) ([]byte, error) {
+	transaction, err := uc.getTransaction(invoiceNumber)
+	if err != nil {
+		return nil, err
+	}
+
+	title, partyLabel := "INVOICE", "Bill to"
+	if transaction.TxType == "in" {
+		title, partyLabel = "PURCHASE INVOICE", "Supplier"
+	}
+	doc := pdf.New()
+	y := uc.printHeader(doc, title, []string{
+		"No. " + transaction.InvoiceNumber,
+		"Date: " + printDate(transaction.Date),
+		"Due date: " + printDate(transaction.DueDate),
+	})
+	y = printParty(doc, y, partyLabel, transaction.Name, transaction.Company, transaction.Address, transaction.PhoneNumber)
+
+	// Columns: number, item, then qty, price and total aligned on their right edge.
+	itemRight := 300.0
+	qtyRight, priceRight := 370.0, 460.0
+	tableHeader := func(y float64) float64 {
+		doc.Text(printMarginLeft, y, 9, true, "No")
+		doc.Text(printMarginLeft+25, y, 9, true, "Item")
+		doc.TextRight(qtyRight, y, 9, true, "Qty (kg)")
+		doc.TextRight(priceRight, y, 9, true, "Price")
+		doc.TextRight(printMarginRight, y, 9, true, "Total")
+		doc.Line(printMarginLeft, y+5, printMarginRight, y+5)
+		return y + printLineHeight + 3
+	}
+	y = tableHeader(y)
+	for i, detail := range transaction.TransactionDetails {
+		if y > printMarginBottom {
+			doc.AddPage()
+			y = tableHeader(60)
+		}
+		doc.Text(printMarginLeft, y, 9, false, fmt.Sprintf("%d", i+1))
+		doc.Text(printMarginLeft+25, y, 9, false, fitText(detail.MeatName, itemRight-printMarginLeft-25, 9, false))
+		doc.TextRight(qtyRight, y, 9, false, formatQty(detail.Qty))
+		doc.TextRight(priceRight, y, 9, false, formatRupiah(detail.Price))
+		doc.TextRight(printMarginRight, y, 9, false, formatRupiah(detail.Subtotal))
+		if detail.Discount > 0 {
+			y += printLineHeight
+			doc.Text(printMarginLeft+25, y, 8, false, "Discount"+discountLabel(detail.DiscountType, detail.DiscountValue))
+			doc.TextRight(printMarginRight, y, 8, false, formatRupiah(-detail.Discount))
+		}
+		y += printLineHeight
+	}
+	doc.Line(printMarginLeft, y-10, printMarginRight, y-10)
+
+	summary := invoiceSummary(transaction)
+	if y+float64(len(summary)+3)*printLineHeight > printMarginBottom {
+		doc.AddPage()
+		y = 60
+	}
+	y += 5
+	doc.Text(printMarginLeft, y, 9, false, "Payment status: "+strings.ToUpper(transaction.PaymentStatus))
+	doc.Text(printMarginLeft, y+printLineHeight, 9, false, "Created by: "+transaction.CreatedBy)
+	for _, line := range summary {
+		printAmount(doc, y, line.label, line.amount, false)
+		y += printLineHeight
+	}
+	printAmount(doc, y, "Total", transaction.Total, true)
+	printAmount(doc, y+printLineHeight, "Paid", transaction.Total-transaction.Debt, false)
+	printAmount(doc, y+2*printLineHeight, "Remaining debt", transaction.Debt, true)
+
+	return doc.Bytes(), nil
+}
+
+// GetCreditPaymentReceiptPDF prints the receipt of one installment. The paid
+// and remaining amounts are those right after the installment was made.
+func (uc *printUseCase) GetCreditPaymentReceiptPDF(id string) ([]byte, error) {
+	payment, transaction, paidToDate, err := uc.getReceipt(id)
+	if err != nil {
+		return nil, err
+	}
+
+	partyLabel := "Received from"
+	if transaction.TxType == "in" {
+		partyLabel = "Paid to"
+	}
+	doc := pdf.New()
+	y := uc.printHeader(doc, "PAYMENT RECEIPT", []string{
+		"No. " + payment.ID,
+		"Date: " + printDate(payment.PaymentDate),
+	})
+	y = printParty(doc, y, partyLabel, transaction.Name, transaction.Company, transaction.Address, transaction.PhoneNumber)
+
+	doc.Text(printMarginLeft, y, 10, false, "Payment for invoice "+transaction.InvoiceNumber+" dated "+printDate(transaction.Date))
+	y += printLineHeight
+	if payment.Notes != "" {
+		doc.Text(printMarginLeft, y, 10, false, payment.Notes)
+		y += printLineHeight
+	}
+	y += printLineHeight
+	printAmount(doc, y, "Amount paid", payment.Amount, true)
+	y += printLineHeight + 5
+	doc.Line(printMarginRight-230, y-10, printMarginRight, y-10)
+	printAmount(doc, y, "Invoice total", transaction.Total, false)
+	printAmount(doc, y+printLineHeight, "Paid to date", paidToDate, false)
+	printAmount(doc, y+2*printLineHeight, "Remaining debt", math.Max(transaction.Total-paidToDate, 0), true)
+
+	y += 5 * printLineHeight
+	doc.Text(printMarginRight-150, y, 9, false, "Received by")
+	doc.Line(printMarginRight-150, y+45, printMarginRight, y+45)
+	doc.Text(printMarginRight-150, y+58, 9, false, payment.CreatedBy)
+
+	return doc.Bytes(), nil
+}
+
+func (uc *printUseCase) GetInvoiceESCPOS(invoiceNumber string, paperWidth int) ([]byte, error) {
+	columns := escpos.Columns(paperWidth)
+	if columns == 0 {
+		return nil, utils.ErrInvalidPaperWidth
+	}
+	transaction, err := uc.getTransaction(invoiceNumber)
+	if err != nil {
+		return nil, err
+	}
+
+	receipt := uc.escposHeader(columns)
+	receipt.Pair("No", transaction.InvoiceNumber)
+	receipt.Pair("Date", printDate(transaction.Date))
+	receipt.Pair("Customer", transaction.Name)
+	if transaction.Company != "" {
+		receipt.Pair("Company", transaction.Company)
+	}
+	receipt.Separator()
+
+	// A wide roll has room for the meat name beside the numbers, a narrow one
+	// prints the name on its own line above them.
+	var widths []int
+	if columns >= 48 {
+		widths = []int{columns - 31, 7, 11, 13}
+		receipt.Row(widths, "Item", "Qty", "Price", "Total")
+	} else {
+		widths = []int{columns - 26, 6, 10, 10}
+		receipt.Line("Item")
+		receipt.Row(widths, "", "Qty", "Price", "Total")
+	}
+	receipt.Separator()
+	for _, detail := range transaction.TransactionDetails {
+		name := detail.MeatName
+		if columns < 48 {
+			receipt.Line(name)
+			name = ""
+		}
+		receipt.Row(widths, name, formatQty(detail.Qty), formatThousands(detail.Price), formatThousands(detail.Subtotal))
+		if detail.Discount > 0 {
+			receipt.Pair(" Discount"+discountLabel(detail.DiscountType, detail.DiscountValue), formatThousands(-detail.Discount))
+		}
+	}
+	receipt.Separator()
+
+	for _, line := range invoiceSummary(transaction) {
+		receipt.Pair(line.label, formatRupiah(line.amount))
+	}
+	receipt.SetBold(true)
+	receipt.Pair("TOTAL", formatRupiah(transaction.Total))
+	receipt.SetBold(false)
+	receipt.Pair("Paid", formatRupiah(transaction.Total-transaction.Debt))
+	receipt.Pair("Remaining debt", formatRupiah(transaction.Debt))
+	uc.escposFooter(receipt)
+	return receipt.Bytes(), nil
+}
+
+func (uc *printUseCase) GetCreditPaymentReceiptESCPOS(id string, paperWidth int) ([]byte, error) {
+	columns := escpos.Columns(paperWidth)
+	if columns == 0 {
+		return nil, utils.ErrInvalidPaperWidth
+	}
+	payment, transaction, paidToDate, err := uc.getReceipt(id)
+	if err != nil {
+		return nil, err
+	}
+
+	receipt := uc.escposHeader(columns)
+	receipt.SetAlign(escpos.AlignCenter)
+	receipt.SetBold(true)
+	receipt.Line("PAYMENT RECEIPT")
+	receipt.SetBold(false)
+	receipt.SetAlign(escpos.AlignLeft)
+	receipt.Pair("Date", printDate(payment.PaymentDate))
+	receipt.Pair("Invoice", transaction.InvoiceNumber)
+	receipt.Pair("Customer", transaction.Name)
+	if payment.Notes != "" {
+		receipt.Line(payment.Notes)
+	}
+	receipt.Separator()
+
+	receipt.SetBold(true)
+	receipt.Pair("PAID", formatRupiah(payment.Amount))
+	receipt.SetBold(false)
+	receipt.Pair("Invoice total", formatRupiah(transaction.Total))
+	receipt.Pair("Paid to date", formatRupiah(paidToDate))
+	receipt.Pair("Remaining debt", formatRupiah(math.Max(transaction.Total-paidToDate, 0)))
+	uc.escposFooter(receipt)
+	return receipt.Bytes(), nil
+}
+
+// escposHeader starts a thermal receipt with the shop details centered on top.
+func (uc *printUseCase) escposHeader(columns int) *escpos.Receipt {
+	receipt := escpos.New(columns)
+	receipt.SetAlign(escpos.AlignCenter)
+	receipt.SetBold(true)
+	receipt.SetDoubleSize(true)
+	receipt.Line(uc.shop.Name)
+	receipt.SetDoubleSize(false)
+	receipt.SetBold(false)
+	for _, line := range []string{uc.shop.Address, uc.shop.PhoneNumber} {
+		if line != "" {
+			receipt.Line(line)
+		}
+	}
+	receipt.SetAlign(escpos.AlignLeft)
+	receipt.Separator()
+	return receipt
+}
+
+func (uc *printUseCase) escposFooter(receipt *escpos.Receipt) {
+	receipt.Separator()
+	receipt.SetAlign(escpos.AlignCenter)
+	if uc.shop.ReceiptFooter != "" {
+		receipt.Line(uc.shop.ReceiptFooter)
+	}
+	receipt.Line(time.Now().Format("02 Jan 2006 15:04"))
+	receipt.SetAlign(escpos.AlignLeft)
+	receipt.Cut()
+}
+
+// getReceipt loads an installment with its invoice and the amount paid on the
+// invoice up to and including the installment.
+func (uc *printUseCase) getReceipt(id string) (*model.CreditPayment, *model.TransactionHeader, float64, error) {
+	payment, err := uc.creditPaymentRepo.GetCreditPaymentByID(id)
+	if err != nil {
+		logrus.WithField("error", err).Error("Failed to get credit payment by ID")
+		return nil, nil, 0, err
+	}
+	if payment == nil || !payment.IsActive {
+		return nil, nil, 0, utils.ErrCreditPaymentNotFound
+	}
+	transaction, err := uc.getTransaction(payment.InvoiceNumber)
+	if err != nil {
+		return nil, nil, 0, err
+	}
+	payments, err := uc.creditPaymentRepo.GetCreditPaymentsByInvoiceNumber(payment.InvoiceNumber)
+	if err != nil {
+		logrus.WithField("error", err).Error("Failed to get credit payments by invoice number")
+		return nil, nil, 0, err
+	}
+	paidToDate := 0.0
+	for _, p := range payments {
+		if p.IsActive && !p.CreatedAt.After(payment.CreatedAt) {
+			paidToDate += p.Amount
+		}
+	}
+	return payment, transaction, paidToDate, nil
+}
+
+func (uc *printUseCase) getTransaction(invoiceNumber string) (*model.TransactionHeader, error) {
+	transaction, err := uc.transactionRepo.GetByInvoiceNumber(invoiceNumber)
+	if err != nil {
+		logrus.WithField("error", err).Error("Failed to get transaction by invoice number")
+		return nil, err
+	}
+	if transaction == nil {
+		return nil, utils.ErrTransactionNotFound
+	}
+	return transaction, nil
+}
+
+// printHeader draws the shop details on the left and the document title and
+// reference lines on the right, and returns where the body starts.
+func (uc *printUseCase) printHeader(doc *pdf.Document, title string, references []string) float64 {
+	y := 60.0
+	doc.Text(printMarginLeft, y, 16, true, uc.shop.Name)
+	doc.TextRight(printMarginRight, y, 16, true, title)
+	for i, line := range []string{uc.shop.Address, uc.shop.PhoneNumber} {
+		if line != "" {
+			doc.Text(printMarginLeft, y+float64(i+1)*13, 9, false, line)
+		}
+	}
+	for i, line := range references {
+		doc.TextRight(printMarginRight, y+float64(i+1)*13, 9, false, line)
+	}
+	y += float64(max(len(references), 2)+1) * 13
+	doc.Line(printMarginLeft, y, printMarginRight, y)
+	return y + 25
+}
+
+func printParty(doc *pdf.Document, y float64, label string, name string, company string, address string, phoneNumber string) float64 {
+	doc.Text(printMarginLeft, y, 10, true, label)
+	y += printLineHeight
+	for _, line := range []string{name, company, address, phoneNumber} {
+		if line == "" {
+			continue
+		}
+		doc.Text(printMarginLeft, y, 10, false, line)
+		y += printLineHeight - 2
+	}
+	return y + 20
+}
+
+func printAmount(doc *pdf.Document, y float64, label string, amount float64, bold bool) {
+	doc.TextRight(printMarginRight-130, y, 10, bold, label)
+	doc.TextRight(printMarginRight, y, 10, bold, formatRupiah(amount))
+}
+
+// fitText shortens s with an ellipsis until it fits the given width.
+func fitText(s string, width float64, size float64, bold bool) string {
+	if pdf.TextWidth(s, size, bold) <= width {
+		return s
+	}
+	runes := []rune(s)
+	for len(runes) > 0 && pdf.TextWidth(string(runes)+"...", size, bold) > width {
+		runes = runes[:len(runes)-1]
+	}
+	return string(runes) + "..."
+}
+
+// printDate formats a stored date as 02 Jan 2006, or leaves it as it is when
+// it cannot be read.
+func printDate(date string) string {
+	parsed, err := time.Parse("2006-01-02", date[:min(len(date), 10)])
+	if err != nil {
+		return date
+	}
+	return parsed.Format("02 Jan 2006")
+}
+
+// formatRupiah formats an amount as whole rupiah with dots between thousands.
+type summaryLine struct {
+	label  string
+	amount float64
+}
+
+// invoiceSummary lists the amounts between the detail lines and the grand
+// total of an invoice. An invoice without discount, tax or rounding has none.
+func invoiceSummary(transaction *model.TransactionHeader) []summaryLine {
+	if transaction.Discount == 0 && transaction.Tax == 0 && transaction.Rounding == 0 {
+		return nil
+	}
+	lines := []summaryLine{{"Subtotal", transaction.Subtotal}}
+	if transaction.Discount != 0 {
+		lines = append(lines, summaryLine{"Discount" + discountLabel(transaction.DiscountType, transaction.DiscountValue), -transaction.Discount})
+	}
+	if transaction.Tax != 0 {
+		lines = append(lines, summaryLine{"PPN " + formatQty(transaction.TaxRate) + "%", transaction.Tax})
+	}
+	if transaction.Rounding != 0 {
+		lines = append(lines, summaryLine{"Rounding", transaction.Rounding})
+	}
+	return lines
+}
+
+func discountLabel(discountType string, value float64) string {
+	if discountType == model.DiscountPercent {
+		return " " + formatQty(value) + "%"
+	}
+	return ""
+}
+
+func formatRupiah(amount float64) string {
+	return "Rp " + formatThousands(amount)
+}
+
+func formatThousands(amount float64) string {
+	sign := ""
+	if amount < 0 {
+		sign = "-"
+		amount = -amount
+	}
+	digits := fmt.Sprintf("%.0f", math.Round(amount))
+	var grouped strings.Builder
+	for i, digit := range digits {
+		if i > 0 && (len(digits)-i)%3 == 0 {
+			grouped.WriteByte('.')
+		}
+		grouped.WriteRune(digit)
+	}
+	return sign + grouped.String()
+}
+
+func formatQty(qty float64) string {
+	formatted := strings.TrimRight(fmt.Sprintf("%.2f", qty), "0")
+	return strings.TrimSuffix(formatted, ".")
+}
@@ -0,0 +1,412 @@
+package usecase
+
+import (
+	"math"
+	"sort"
+	"time"
+	"trackprosto/delivery/utils"
+	model "trackprosto/models"
+	"trackprosto/repository"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+type ReportUseCase interface {
+	GetMarginReport(groupBy string, startDate string, endDate string) (*model.MarginReport, error)
+	GetProfitLossReport(granularity string, startDate string, endDate string) (*model.ProfitLossReport, error)
+	GetDailyClosing(date string) (*model.DailyClosingReport, error)
+	CloseDay(request *model.DayClosingRequest, closedBy string) (*model.DailyClosingReport, error)
+	ReopenDay(date string) error
+	GetReceivablesAging(asOf string) (*model.AgingReport, error)
+	GetPayables(supplierID string, asOf string) (*model.PayableList, error)
+	GetPayablesAging(asOf string) (*model.AgingReport, error)
+}
+
+type reportUseCase struct {
+	reportRepo     repository.ReportRepository
+	dayClosingRepo repository.DayClosingRepository
+}
+
+func NewReportUseCase(reportRepo repository.ReportRepository, dayClosingRepo repository.DayClosingRepository) ReportUseCase {
+	return &reportUseCase{
+		reportRepo:     reportRepo,
+		dayClosingRepo: dayClosingRepo,
+	}
+}
+
+// GetMarginReport compares the revenue of sales with their FIFO cost of goods
+// sold, per invoice, meat or customer.
+func (uc *reportUseCase) GetMarginReport(groupBy string, startDate string, endDate string) (*model.MarginReport, error) {
+	if groupBy != model.MarginByInvoice && groupBy != model.MarginByMeat && groupBy != model.MarginByCustomer {
+		return nil, utils.ErrInvalidGroupBy
+	}
+	lines, err := uc.reportRepo.GetMarginLines(groupBy, startDate, endDate)
+	if err != nil {
+		logrus.WithFields(logrus.Fields{
+			"error":   err,
+			"groupBy": groupBy,
+		}).Error("Failed to get margin lines")
+		return nil, err
+	}
+
+	report := &model.MarginReport{
+		StartDate: startDate,
+		EndDate:   endDate,
+		GroupBy:   groupBy,
+		Lines:     lines,
+	}
+	for _, line := range lines {
+		line.MarginPct = marginPct(line.GrossMargin, line.Revenue)
+		report.Revenue += line.Revenue
+		report.Discount += line.Discount
+		report.Tax += line.Tax
+		report.Total += line.Total
+		report.COGS += line.COGS
+		report.GrossMargin += line.GrossMargin
+	}
+	report.MarginPct = marginPct(report.GrossMargin, report.Revenue)
+	return report, nil
+}
+
+// GetProfitLossReport combines sales, their COGS, purchases and operational
+// expenditures into a P&L per day, week or month.
+func (uc *reportUseCase) GetProfitLossReport(granularity string, startDate string, endDate string) (*model.ProfitLossReport, error) {
+	if granularity != model.GranularityDay && granularity != model.GranularityWeek && granularity != model.GranularityMonth {
+		return nil, utils.ErrInvalidGranularity
+	}
+
+	revenue, err := uc.reportRepo.GetInvoiceAmountsByPeriod("out", "revenue", granularity, startDate, endDate)
+	if err != nil {
+		logrus.WithField("error", err).Error("Failed to get revenue")
+		return nil, err
+	}
+	discounts, err := uc.reportRepo.GetInvoiceAmountsByPeriod("out", "discount", granularity, startDate, endDate)
+	if err != nil {
+		logrus.WithField("error", err).Error("Failed to get discounts")
+		return nil, err
+	}
+	taxes, err := uc.reportRepo.GetInvoiceAmountsByPeriod("out", "tax", granularity, startDate, endDate)
+	if err != nil {
+		logrus.WithField("error", err).Error("Failed to get tax")
+		return nil, err
+	}
+	cogs, err := uc.reportRepo.GetInvoiceAmountsByPeriod("out", "cogs", granularity, startDate, endDate)
+	if err != nil {
+		logrus.WithField("error", err).Error("Failed to get cost of goods sold")
+		return nil, err
+	}
+	purchases, err := uc.reportRepo.GetInvoiceAmountsByPeriod("in", "revenue", granularity, startDate, endDate)
+	if err != nil {
+		logrus.WithField("error", err).Error("Failed to get purchases")
+		return nil, err
+	}
+	expenditures, err := uc.reportRepo.GetOperationalExpendituresByPeriod(granularity, startDate, endDate)
+	if err != nil {
+		logrus.WithField("error", err).Error("Failed to get operational expenditures")
+		return nil, err
+	}
+
+	periods := make(map[string]*model.ProfitLossLine)
+	var order []string
+	line := func(period string) *model.ProfitLossLine {
+		if periods[period] == nil {
+			periods[period] = &model.ProfitLossLine{Period: period}
+			order = append(order, period)
+		}
+		return periods[period]
+	}
+	for _, amount := range revenue {
+		line(amount.Period).Revenue = amount.Amount
+	}
+	for _, amount := range discounts {
+		line(amount.Period).Discount = amount.Amount
+	}
+	for _, amount := range taxes {
+		line(amount.Period).Tax = amount.Amount
+	}
+	for _, amount := range cogs {
+		line(amount.Period).COGS = amount.Amount
+	}
+	for _, amount := range purchases {
+		line(amount.Period).Purchases = amount.Amount
+	}
+	for _, amount := range expenditures {
+		line(amount.Period).Expenditures = amount.Amount
+	}
+	sort.Strings(order)
+
+	report := &model.ProfitLossReport{
+		StartDate:   startDate,
+		EndDate:     endDate,
+		Granularity: granularity,
+		Total:       &model.ProfitLossLine{Period: "total"},
+	}
+	for _, period := range order {
+		l := periods[period]
+		l.GrossProfit = l.Revenue - l.COGS
+		l.NetProfit = l.GrossProfit - l.Expenditures
+		report.Periods = append(report.Periods, l)
+
+		report.Total.Revenue += l.Revenue
+		report.Total.Discount += l.Discount
+		report.Total.Tax += l.Tax
+		report.Total.Purchases += l.Purchases
+		report.Total.COGS += l.COGS
+		report.Total.GrossProfit += l.GrossProfit
+		report.Total.Expenditures += l.Expenditures
+		report.Total.NetProfit += l.NetProfit
+	}
+	return report, nil
+}
+
+// GetDailyClosing builds the cash book of one day: the opening balance carried
+// from all earlier days, customer payments in, supplier payments and
+// operational expenditures out, and the closing balance.
+func (uc *reportUseCase) GetDailyClosing(date string) (*model.DailyClosingReport, error) {
+	return dailyClosingReport(uc.dayClosingRepo, date)
+}
+
+// dailyClosingReport builds the cash book of date from dayClosingRepo.
+func dailyClosingReport(dayClosingRepo repository.DayClosingRepository, date string) (*model.DailyClosingReport, error) {
+	if _, err := time.Parse("2006-01-02", date); err != nil {
+		return nil, utils.ErrInvalidDate
+	}
+	report := &model.DailyClosingReport{
+		Date:     date,
+		Inflows:  []*model.CashLine{},
+		Outflows: []*model.CashLine{},
+	}
+
+	for _, cashType := range []string{model.CashCustomerPayment, model.CashSupplierPayment, model.CashExpenditure} {
+		before, err := dayClosingRepo.GetCashTotalBefore(cashType, date)
+		if err != nil {
+			logrus.WithFields(logrus.Fields{
+				"error":    err,
+				"cashType": cashType,
+			}).Error("Failed to get cash total before date")
+			return nil, err
+		}
+		lines, err := dayClosingRepo.GetCashLines(cashType, date)
+		if err != nil {
+			logrus.WithFields(logrus.Fields{
+				"error":    err,
+				"cashType": cashType,
+			}).Error("Failed to get cash lines")
+			return nil, err
+		}
+		if cashType == model.CashCustomerPayment {
+			report.OpeningBalance += before
+			report.Inflows = append(report.Inflows, lines...)
+		} else {
+			report.OpeningBalance -= before
+			report.Outflows = append(report.Outflows, lines...)
+		}
+	}
+	for _, line := range report.Inflows {
+		report.TotalInflow += line.Amount
+	}
+	for _, line := range report.Outflows {
+		report.TotalOutflow += line.Amount
+	}
+	report.ClosingBalance = report.OpeningBalance + report.TotalInflow - report.TotalOutflow
+
+	closing, err := dayClosingRepo.GetDayClosing(date)
+	if err != nil {
+		return nil, err
+	}
+	report.IsLocked = closing != nil
+	report.Closing = closing
+	return report, nil
+}
+
+// CloseDay snapshots the cash book of a day and locks it. Changes to the
+// day's cash still in flight are waited for, and later ones are kept out
+// until the closing is stored.
+func (uc *reportUseCase) CloseDay(request *model.DayClosingRequest, closedBy string) (*model.DailyClosingReport, error) {
+	var report *model.DailyClosingReport
+	err := uc.dayClosingRepo.GetDB().Transaction(func(tx *gorm.DB) error {
+		dayClosingRepo := uc.dayClosingRepo.WithTx(tx)
+		if err := dayClosingRepo.LockDate(request.Date); err != nil {
+			return err
+		}
+		var err error
+		report, err = dailyClosingReport(dayClosingRepo, request.Date)
+		if err != nil {
+			return err
+		}
+		if report.IsLocked {
+			return utils.ErrDayAlreadyClosed
+		}
+
+		closing := &model.DayClosing{
+			Date:           request.Date,
+			OpeningBalance: report.OpeningBalance,
+			TotalInflow:    report.TotalInflow,
+			TotalOutflow:   report.TotalOutflow,
+			ClosingBalance: report.ClosingBalance,
+			CountedCash:    request.CountedCash,
+			Notes:          request.Notes,
+			ClosedAt:       time.Now(),
+			ClosedBy:       closedBy,
+		}
+		if request.CountedCash != nil {
+			difference := *request.CountedCash - report.ClosingBalance
+			closing.CashDifference = &difference
+		}
+		if err := dayClosingRepo.CreateDayClosing(closing); err != nil {
+			logrus.WithFields(logrus.Fields{
+				"error": err,
+				"date":  request.Date,
+			}).Error("Failed to close day")
+			return err
+		}
+
+		report.IsLocked = true
+		report.Closing = closing
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+// ReopenDay removes the lock of a closed day.
+func (uc *reportUseCase) ReopenDay(date string) error {
+	closing, err := uc.dayClosingRepo.GetDayClosing(date)
+	if err != nil {
+		return err
+	}
+	if closing == nil {
+		return utils.ErrDayNotClosed
+	}
+	return uc.dayClosingRepo.DeleteDayClosing(date)
+}
+
+// GetReceivablesAging buckets what customers still owe on "out" invoices by
+// days past due, per customer and per company.
+func (uc *reportUseCase) GetReceivablesAging(asOf string) (*model.AgingReport, error) {
+	return uc.getAgingReport("out", asOf)
+}
+
+// GetPayablesAging buckets what we still owe suppliers on "in" invoices by
+// days past due, per supplier and per company.
+func (uc *reportUseCase) GetPayablesAging(asOf string) (*model.AgingReport, error) {
+	return uc.getAgingReport("in", asOf)
+}
+
+// GetPayables lists the unpaid "in" invoices, optionally of one supplier, with
+// what we owe each supplier. Invoices due first come first.
+func (uc *reportUseCase) GetPayables(supplierID string, asOf string) (*model.PayableList, error) {
+	if _, err := time.Parse("2006-01-02", asOf); err != nil {
+		return nil, utils.ErrInvalidDate
+	}
+	invoices, err := uc.reportRepo.GetOutstandingInvoices("in", asOf)
+	if err != nil {
+		logrus.WithField("error", err).Error("Failed to get outstanding supplier invoices")
+		return nil, err
+	}
+
+	list := &model.PayableList{
+		AsOf:      asOf,
+		Suppliers: []*model.SupplierPayable{},
+		Invoices:  []*model.OutstandingInvoice{},
+	}
+	suppliers := make(map[string]*model.SupplierPayable)
+	for _, invoice := range invoices {
+		if supplierID != "" && invoice.CustomerID != supplierID {
+			continue
+		}
+		setDaysOverdue(invoice, asOf)
+		list.Invoices = append(list.Invoices, invoice)
+
+		supplier := suppliers[invoice.CustomerID]
+		if supplier == nil {
+			supplier = &model.SupplierPayable{
+				SupplierID:  invoice.CustomerID,
+				Name:        invoice.Name,
+				Company:     invoice.Company,
+				NextDueDate: invoice.DueDate,
+			}
+			suppliers[invoice.CustomerID] = supplier
+			list.Suppliers = append(list.Suppliers, supplier)
+		}
+		supplier.Outstanding += invoice.Outstanding
+		supplier.InvoiceCount++
+		list.Outstanding += invoice.Outstanding
+		if invoice.DaysOverdue > 0 {
+			supplier.Overdue += invoice.Outstanding
+			list.Overdue += invoice.Outstanding
+		}
+	}
+	return list, nil
+}
+
+func (uc *reportUseCase) getAgingReport(txType string, asOf string) (*model.AgingReport, error) {
+	if _, err := time.Parse("2006-01-02", asOf); err != nil {
+		return nil, utils.ErrInvalidDate
+	}
+	invoices, err := uc.reportRepo.GetOutstandingInvoices(txType, asOf)
+	if err != nil {
+		logrus.WithFields(logrus.Fields{
+			"error":  err,
+			"txType": txType,
+		}).Error("Failed to get outstanding invoices")
+		return nil, err
+	}
+
+	report := &model.AgingReport{
+		AsOf:      asOf,
+		Customers: []*model.AgingLine{},
+		Companies: []*model.AgingLine{},
+	}
+	customers := make(map[string]*model.AgingLine)
+	companies := make(map[string]*model.AgingLine)
+	for _, invoice := range invoices {
+		setDaysOverdue(invoice, asOf)
+
+		customer := customers[invoice.CustomerID]
+		if customer == nil {
+			customer = &model.AgingLine{Key: invoice.CustomerID, Name: invoice.Name}
+			customers[invoice.CustomerID] = customer
+			report.Customers = append(report.Customers, customer)
+		}
+		customer.Add(invoice.DaysOverdue, invoice.Outstanding)
+		customer.Invoices = append(customer.Invoices, invoice)
+
+		company := companies[invoice.Company]
+		if company == nil {
+			company = &model.AgingLine{Key: invoice.Company, Name: invoice.Company}
+			companies[invoice.Company] = company
+			report.Companies = append(report.Companies, company)
+		}
+		company.Add(invoice.DaysOverdue, invoice.Outstanding)
+
+		report.Total.Add(invoice.DaysOverdue, invoice.Outstanding)
+	}
+	sort.Slice(report.Customers, func(i, j int) bool { return report.Customers[i].Total > report.Customers[j].Total })
+	sort.Slice(report.Companies, func(i, j int) bool { return report.Companies[i].Total > report.Companies[j].Total })
+	return report, nil
+}
+
+// setDaysOverdue sets how many days past its due date an invoice is on asOf.
+func setDaysOverdue(invoice *model.OutstandingInvoice, asOf string) {
+	asOfDate, err := time.Parse("2006-01-02", asOf)
+	if err != nil {
+		return
+	}
+	dueDate, err := time.Parse("2006-01-02", invoice.DueDate)
+	if err != nil {
+		return
+	}
+	invoice.DaysOverdue = int(asOfDate.Sub(dueDate).Hours() / 24)
+}
+
+// marginPct returns margin as a percentage of revenue, rounded to two decimals.
+func marginPct(margin float64, revenue float64) float64 {
+	if revenue == 0 {
+		return 0
+	}
+	return math.Round(margin/revenue*10000) / 100
+}
@@ -0,0 +1,282 @@
+package usecase
+
+import (
+	"time"
+	"trackprosto/delivery/utils"
+	model "trackprosto/models"
+	"trackprosto/repository"
+
+	"github.com/google/uuid"
+	log "github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+type StockOpnameUseCase interface {
+	StartStockOpname(notes string, startedBy string) (*model.StockOpname, error)
+	GetStockOpnameByID(id string) (*model.StockOpname, error)
+	GetAllStockOpnames() ([]*model.StockOpname, error)
+	SaveCounts(id string, items []*model.StockOpnameItemRequest, countedBy string) (*model.StockOpname, error)
+	GetVarianceReport(id string) (*model.StockVarianceReport, error)
+	PostStockOpname(id string, postedBy string) (*model.StockVarianceReport, error)
+	CancelStockOpname(id string, cancelledBy string) error
+}
+
+type stockOpnameUseCase struct {
+	stockOpnameRepo repository.StockOpnameRepository
+	meatRepo        repository.MeatRepository
+	meatLotRepo     repository.MeatLotRepository
+}
+
+func NewStockOpnameUseCase(stockOpnameRepo repository.StockOpnameRepository, meatRepo repository.MeatRepository, meatLotRepo repository.MeatLotRepository) StockOpnameUseCase {
+	return &stockOpnameUseCase{
+		stockOpnameRepo: stockOpnameRepo,
+		meatRepo:        meatRepo,
+		meatLotRepo:     meatLotRepo,
+	}
+}
+
+// StartStockOpname opens a count session. Only one session may be open at a
+// time; concurrent starts wait on the table lock so the second one sees the
+// first session.
+func (uc *stockOpnameUseCase) StartStockOpname(notes string, startedBy string) (*model.StockOpname, error) {
+	now := time.Now()
+	opname := &model.StockOpname{
+		ID:        uuid.NewString(),
+		Status:    model.StockOpnameOpen,
+		Notes:     notes,
+		StartedAt: now,
+		StartedBy: startedBy,
+		UpdatedBy: startedBy,
+		Items:     []*model.StockOpnameItem{},
+	}
+	err := uc.stockOpnameRepo.GetDB().Transaction(func(tx *gorm.DB) error {
+		stockOpnameRepo := uc.stockOpnameRepo.WithTx(tx)
+		if err := stockOpnameRepo.LockStockOpnames(); err != nil {
+			return err
+		}
+		openOpname, err := stockOpnameRepo.GetOpenStockOpname()
+		if err != nil {
+			log.WithField("error", err).Error("Failed to get open stock opname")
+			return err
+		}
+		if openOpname != nil {
+			log.WithField("opnameID", openOpname.ID).Error("A stock opname is already in progress")
+			return utils.ErrStockOpnameInProgress
+		}
+		return stockOpnameRepo.CreateStockOpname(opname)
+	})
+	if err != nil {
+		log.WithField("error", err).Error("Failed to create stock opname")
+		return nil, err
+	}
+	return opname, nil
+}
+
+func (uc *stockOpnameUseCase) GetStockOpnameByID(id string) (*model.StockOpname, error) {
+	opname, err := uc.stockOpnameRepo.GetStockOpnameByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if opname == nil {
+		return nil, utils.ErrStockOpnameNotFound
+	}
+	return opname, nil
+}
+
+func (uc *stockOpnameUseCase) GetAllStockOpnames() ([]*model.StockOpname, error) {
+	return uc.stockOpnameRepo.GetAllStockOpnames()
+}
+
+// SaveCounts records the counted kg per meat; a meat counted twice keeps the latest count.
+func (uc *stockOpnameUseCase) SaveCounts(id string, items []*model.StockOpnameItemRequest, countedBy string) (*model.StockOpname, error) {
+	err := uc.stockOpnameRepo.GetDB().Transaction(func(tx *gorm.DB) error {
+		stockOpnameRepo := uc.stockOpnameRepo.WithTx(tx)
+		meatRepo := uc.meatRepo.WithTx(tx)
+
+		opname, err := stockOpnameRepo.GetStockOpnameByIDForUpdate(id)
+		if err != nil {
+			return err
+		}
+		if opname == nil {
+			return utils.ErrStockOpnameNotFound
+		}
+		if opname.Status != model.StockOpnameOpen {
+			return utils.ErrStockOpnameNotOpen
+		}
+
+		now := time.Now()
+		for _, item := range items {
+			if *item.CountedStock < 0 {
+				return utils.ErrInvalidQty
+			}
+			if item.ReasonCode != "" && !model.IsValidReasonCode(item.ReasonCode) {
+				return utils.ErrInvalidReasonCode
+			}
+			meat, err := meatRepo.GetMeatByID(item.MeatID)
+			if err != nil {
+				return err
+			}
+			if meat == nil {
+				return utils.ErrMeatNotFound
+			}
+			err = stockOpnameRepo.SaveStockOpnameItem(&model.StockOpnameItem{
+				ID:           uuid.NewString(),
+				OpnameID:     opname.ID,
+				MeatID:       meat.ID,
+				MeatName:     meat.Name,
+				SystemStock:  meat.Stock,
+				CountedStock: *item.CountedStock,
+				Variance:     *item.CountedStock - meat.Stock,
+				ReasonCode:   item.ReasonCode,
+				Notes:        item.Notes,
+				CountedAt:    now,
+				CountedBy:    countedBy,
+			})
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		log.WithFields(log.Fields{
+			"opnameID": id,
+			"error":    err,
+		}).Error("Failed to save stock opname counts")
+		return nil, err
+	}
+
+	return uc.GetStockOpnameByID(id)
+}
+
+// GetVarianceReport shows the variance of every count against the stock at
+// the time it was counted, which is what posting the session adjusts by.
+func (uc *stockOpnameUseCase) GetVarianceReport(id string) (*model.StockVarianceReport, error) {
+	opname, err := uc.GetStockOpnameByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &model.StockVarianceReport{
+		OpnameID: opname.ID,
+		Status:   opname.Status,
+		Lines:    []*model.StockVarianceLine{},
+	}
+	for _, item := range opname.Items {
+		meat, err := uc.meatRepo.GetMeatByID(item.MeatID)
+		if err != nil {
+			return nil, err
+		}
+		line := &model.StockVarianceLine{
+			MeatID:       item.MeatID,
+			MeatName:     item.MeatName,
+			SystemStock:  item.SystemStock,
+			CountedStock: item.CountedStock,
+			Variance:     item.Variance,
+			ReasonCode:   item.ReasonCode,
+		}
+		if meat != nil {
+			line.Price = meat.Price
+		}
+		line.VarianceValue = line.Variance * line.Price
+		report.TotalVarianceQty += line.Variance
+		report.TotalVarianceValue += line.VarianceValue
+		report.Lines = append(report.Lines, line)
+	}
+	return report, nil
+}
+
+// PostStockOpname turns every non-zero variance into an adjustment movement
+// and closes the session. Variances are the ones taken at count time, so
+// sales made between a count and the posting stay deducted from stock.
+func (uc *stockOpnameUseCase) PostStockOpname(id string, postedBy string) (*model.StockVarianceReport, error) {
+	err := uc.stockOpnameRepo.GetDB().Transaction(func(tx *gorm.DB) error {
+		stockOpnameRepo := uc.stockOpnameRepo.WithTx(tx)
+		meatRepo := uc.meatRepo.WithTx(tx)
+		meatLotRepo := uc.meatLotRepo.WithTx(tx)
+
+		opname, err := stockOpnameRepo.GetStockOpnameByIDForUpdate(id)
+		if err != nil {
+			return err
+		}
+		if opname == nil {
+			return utils.ErrStockOpnameNotFound
+		}
+		if opname.Status != model.StockOpnameOpen {
+			return utils.ErrStockOpnameNotOpen
+		}
+
+		if err := postStockOpnameItems(meatRepo, meatLotRepo, opname, postedBy); err != nil {
+			return err
+		}
+
+		now := time.Now()
+		opname.Status = model.StockOpnamePosted
+		opname.PostedAt = &now
+		opname.PostedBy = postedBy
+		opname.UpdatedBy = postedBy
+		return stockOpnameRepo.UpdateStockOpname(opname)
+	})
+	if err != nil {
+		log.WithFields(log.Fields{
+			"opnameID": id,
+			"error":    err,
+		}).Error("Failed to post stock opname")
+		return nil, err
+	}
+
+	return uc.GetVarianceReport(id)
+}
+
+// postStockOpnameItems moves the stock of every counted meat by the variance
+// saved with its count.
+func postStockOpnameItems(meatRepo repository.MeatRepository, meatLotRepo repository.MeatLotRepository, opname *model.StockOpname, postedBy string) error {
+	for _, item := range opname.Items {
+		if item.Variance == 0 {
+			continue
+		}
+		if item.ReasonCode == "" {
+			log.WithField("meatName", item.MeatName).Error("Variance without reason code")
+			return utils.ErrReasonCodeRequired
+		}
+		meat, err := meatRepo.GetMeatByID(item.MeatID)
+		if err != nil {
+			return err
+		}
+		if meat == nil {
+			return utils.ErrMeatNotFound
+		}
+		err = moveStock(meatRepo, meatLotRepo, meat, &model.StockMovement{
+			MeatID:     item.MeatID,
+			Qty:        item.Variance,
+			SourceType: model.AdjustmentSourceType(item.ReasonCode),
+			SourceID:   opname.ID,
+			ReasonCode: item.ReasonCode,
+			Notes:      item.Notes,
+			CreatedBy:  postedBy,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (uc *stockOpnameUseCase) CancelStockOpname(id string, cancelledBy string) error {
+	return uc.stockOpnameRepo.GetDB().Transaction(func(tx *gorm.DB) error {
+		stockOpnameRepo := uc.stockOpnameRepo.WithTx(tx)
+		opname, err := stockOpnameRepo.GetStockOpnameByIDForUpdate(id)
+		if err != nil {
+			return err
+		}
+		if opname == nil {
+			return utils.ErrStockOpnameNotFound
+		}
+		if opname.Status != model.StockOpnameOpen {
+			return utils.ErrStockOpnameNotOpen
+		}
+		opname.Status = model.StockOpnameCancelled
+		opname.UpdatedBy = cancelledBy
+		return stockOpnameRepo.UpdateStockOpname(opname)
+	})
+}
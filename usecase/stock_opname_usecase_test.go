@@ -0,0 +1,108 @@
+package usecase
+
+import (
+	"testing"
+	model "trackprosto/models"
+	"trackprosto/repository"
+)
+
+// fakeMeatRepository keeps meats in memory and applies stock movements to
+// them.
+type fakeMeatRepository struct {
+	repository.MeatRepository
+	meats     map[string]*model.Meat
+	movements []*model.StockMovement
+}
+
+func (r *fakeMeatRepository) GetMeatByID(id string) (*model.Meat, error) {
+	meat, ok := r.meats[id]
+	if !ok {
+		return nil, nil
+	}
+	copied := *meat
+	return &copied, nil
+}
+
+func (r *fakeMeatRepository) MoveStock(movement *model.StockMovement) error {
+	r.meats[movement.MeatID].Stock += movement.Qty
+	r.movements = append(r.movements, movement)
+	return nil
+}
+
+// fakeMeatLotRepository has no lots, so every consumption is untracked.
+type fakeMeatLotRepository struct {
+	repository.MeatLotRepository
+}
+
+func (r *fakeMeatLotRepository) GetAvailableLotsForUpdate(meatID string, pickingMethod string) ([]*model.MeatLot, error) {
+	return nil, nil
+}
+
+func (r *fakeMeatLotRepository) GetLastCostPrice(meatID string) (float64, error) {
+	return 0, nil
+}
+
+func (r *fakeMeatLotRepository) CreateLot(lot *model.MeatLot) error {
+	return nil
+}
+
+func (r *fakeMeatLotRepository) CreateConsumption(consumption *model.LotConsumption) error {
+	return nil
+}
+
+func TestPostStockOpnameItemsKeepsSalesMadeAfterCount(t *testing.T) {
+	tests := []struct {
+		name          string
+		systemStock   float64
+		countedStock  float64
+		stockAtPost   float64
+		wantStock     float64
+		wantMovements int
+	}{
+		{"sale after matching count", 10, 10, 7, 7, 0},
+		{"sale after short count", 10, 9, 7, 6, 1},
+		{"sale after long count", 10, 12, 7, 9, 1},
+		{"no sale", 10, 8, 10, 8, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			meatRepo := &fakeMeatRepository{meats: map[string]*model.Meat{
+				"m1": {ID: "m1", Name: "Sirloin", Stock: tt.stockAtPost},
+			}}
+			item := &model.StockOpnameItem{
+				MeatID:       "m1",
+				MeatName:     "Sirloin",
+				SystemStock:  tt.systemStock,
+				CountedStock: tt.countedStock,
+				Variance:     tt.countedStock - tt.systemStock,
+				ReasonCode:   model.ReasonCountCorrection,
+			}
+			opname := &model.StockOpname{ID: "o1", Items: []*model.StockOpnameItem{item}}
+
+			if err := postStockOpnameItems(meatRepo, &fakeMeatLotRepository{}, opname, "tester"); err != nil {
+				t.Fatalf("postStockOpnameItems() error = %v", err)
+			}
+			if got := meatRepo.meats["m1"].Stock; got != tt.wantStock {
+				t.Errorf("stock = %v, want %v", got, tt.wantStock)
+			}
+			if got := len(meatRepo.movements); got != tt.wantMovements {
+				t.Errorf("movements = %v, want %v", got, tt.wantMovements)
+			}
+			if item.SystemStock != tt.systemStock || item.Variance != tt.countedStock-tt.systemStock {
+				t.Errorf("item changed to system stock %v, variance %v", item.SystemStock, item.Variance)
+			}
+		})
+	}
+}
+
+func TestPostStockOpnameItemsRequiresReasonCode(t *testing.T) {
+	meatRepo := &fakeMeatRepository{meats: map[string]*model.Meat{
+		"m1": {ID: "m1", Stock: 10},
+	}}
+	opname := &model.StockOpname{Items: []*model.StockOpnameItem{
+		{MeatID: "m1", SystemStock: 10, CountedStock: 8, Variance: -2},
+	}}
+	if err := postStockOpnameItems(meatRepo, &fakeMeatLotRepository{}, opname, "tester"); err == nil {
+		t.Fatal("postStockOpnameItems() error = nil, want reason code required")
+	}
+}
@@ -2,22 +2,28 @@ package usecase
 
 import (
 	"fmt"
+	"io"
+	"slices"
+	"sort"
 	"strings"
 	"time"
 	"trackprosto/delivery/utils"
 	model "trackprosto/models"
 	"trackprosto/repository"
+	"trackprosto/utils/export"
 
 	"github.com/google/uuid"
 	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
 )
 
 type TransactionUseCase interface {
 	CreateTransaction(transaction *model.TransactionHeader) (*model.TransactionHeaderResponse, error)
 	GetAllTransactions(page int, itemsPerPage int) ([]*model.TransactionHeader, int, error)
 	GetTransactionByID(id string) (*model.TransactionHeader, error)
-	DeleteTransaction(id string) error
+	VoidTransaction(id string, reason string, voidedBy string) (*model.TransactionHeader, error)
 	GetTransactionByInvoiceNumber(inv_number string) (*model.TransactionHeader, error)
+	ExportTransactions(filter *model.ExportFilter, format string, w io.Writer) error
 }
 
 type transactionUseCase struct {
@@ -27,20 +33,17 @@ type transactionUseCase struct {
 	companyRepo          repository.CompanyRepository
 	creditPaymentRepo    repository.CreditPaymentRepository
 	dailyExpenditureRepo repository.DailyExpenditureRepository
+	customerLedgerRepo   repository.CustomerLedgerRepository
+	meatLotRepo          repository.MeatLotRepository
+	dayClosingRepo       repository.DayClosingRepository
+	sequenceRepo         repository.SequenceRepository
+	numberingSchemeRepo  repository.NumberingSchemeRepository
+	priceListRepo        repository.PriceListRepository
+	roundingUnit         float64
 }
 
 // CreateTransaction implements TransactionUseCase.
 func (uc *transactionUseCase) CreateTransaction(transaction *model.TransactionHeader) (*model.TransactionHeaderResponse, error) {
-	// Generate invoice number
-	tx := uc.transactionRepo.GetDB().Begin()
-	defer tx.Rollback()
-	today := time.Now().Format("20060102")
-	todayDate := time.Now().Format("2006-01-02")
-	number, err := uc.transactionRepo.CountTransactions()
-	notes := "Settled"
-	if err != nil {
-		return nil, err
-	}
 	customerCh := make(chan *model.CustomerModel, 1)
 	go func() {
 		customer, err := uc.customerRepo.GetCustomerById(transaction.CustomerID)
@@ -84,38 +87,167 @@ func (uc *transactionUseCase) CreateTransaction(transaction *model.TransactionHe
 		return nil, utils.ErrCompanyNotFound
 	}
 
-	invoiceNumberFormat := "MJP-%s-%04d"
+	// Stock moves, expenditure, header, customer debt and the first credit
+	// payment are written in one database transaction so a failing detail
+	// line leaves nothing behind.
+	var result *model.TransactionHeader
+	err := uc.transactionRepo.GetDB().Transaction(func(tx *gorm.DB) error {
+		var err error
+		result, err = uc.createTransaction(tx, transaction, customer, company)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
 
+	transactionResponse := &model.TransactionHeaderResponse{
+		ID:                 result.ID,
+		Date:               result.Date,
+		DueDate:            result.DueDate,
+		InvoiceNumber:      result.InvoiceNumber,
+		CustomerID:         result.CustomerID,
+		Name:               result.Name,
+		Address:            result.Address,
+		Company:            result.Company,
+		PhoneNumber:        result.PhoneNumber,
+		TxType:             result.TxType,
+		PaymentStatus:      result.PaymentStatus,
+		PaymentAmount:      result.PaymentAmount,
+		Subtotal:           result.Subtotal,
+		Discount:           result.Discount,
+		TaxRate:            result.TaxRate,
+		Tax:                result.Tax,
+		Rounding:           result.Rounding,
+		Total:              result.Total,
+		IsActive:           result.IsActive,
+		CreatedAt:          time.Time{},
+		UpdatedAt:          time.Time{},
+		CreatedBy:          result.CreatedBy,
+		UpdatedBy:          result.UpdatedBy,
+		Debt:               result.Debt,
+		PriceApprovedBy:    result.PriceApprovedBy,
+		TransactionDetails: transaction.TransactionDetails,
+	}
+
+	return transactionResponse, nil
+}
+
+// lockSoldMeats locks the meats a sale takes stock from until its database
+// transaction ends, so concurrent sales cannot both pass the stock check. Rows
+// are locked in meat ID order so invoices sharing meats cannot deadlock.
+func lockSoldMeats(meatRepo repository.MeatRepository, transaction *model.TransactionHeader) (map[string]*model.Meat, error) {
+	lockedMeats := make(map[string]*model.Meat)
+	if transaction.TxType != "out" {
+		return lockedMeats, nil
+	}
+	meatIDs := make([]string, 0, len(transaction.TransactionDetails))
+	for _, detail := range transaction.TransactionDetails {
+		meatIDs = append(meatIDs, detail.MeatID)
+	}
+	sort.Strings(meatIDs)
+	for _, meatID := range slices.Compact(meatIDs) {
+		meat, err := meatRepo.GetMeatByIDForUpdate(meatID)
+		if err != nil {
+			return nil, err
+		}
+		lockedMeats[meatID] = meat
+	}
+	return lockedMeats, nil
+}
+
+// createTransaction writes the whole invoice using repositories bound to tx.
+func (uc *transactionUseCase) createTransaction(tx *gorm.DB, transaction *model.TransactionHeader, customer *model.CustomerModel, company *model.Company) (*model.TransactionHeader, error) {
+	transactionRepo := uc.transactionRepo.WithTx(tx)
+	meatRepo := uc.meatRepo.WithTx(tx)
+	creditPaymentRepo := uc.creditPaymentRepo.WithTx(tx)
+	dailyExpenditureRepo := uc.dailyExpenditureRepo.WithTx(tx)
+	customerLedgerRepo := uc.customerLedgerRepo.WithTx(tx)
+	meatLotRepo := uc.meatLotRepo.WithTx(tx)
+	dayClosingRepo := uc.dayClosingRepo.WithTx(tx)
+	sequenceRepo := uc.sequenceRepo.WithTx(tx)
+	numberingSchemeRepo := uc.numberingSchemeRepo.WithTx(tx)
+	priceListRepo := uc.priceListRepo.WithTx(tx)
+
+	// Generate invoice number
+	todayDate := time.Now().Format("2006-01-02")
+	if err := ensureDayOpen(dayClosingRepo, todayDate); err != nil {
+		return nil, err
+	}
+	documentType := model.DocumentInvoiceIn
 	if transaction.TxType == "out" {
-		invoiceNumberFormat = "INV-%s-%04d"
+		documentType = model.DocumentInvoiceOut
+	}
+	invoiceNumber, err := nextDocumentNumber(numberingSchemeRepo, sequenceRepo, documentType, time.Now())
+	notes := "Settled"
+	if err != nil {
+		return nil, err
 	}
 
-	invoiceNumber := fmt.Sprintf(invoiceNumberFormat, today, number)
 	transaction.ID = uuid.NewString()
 	transaction.Date = todayDate
+	transaction.DueDate = todayDate
+	if customer.PaymentTermDays != nil {
+		transaction.DueDate = time.Now().AddDate(0, 0, *customer.PaymentTermDays).Format("2006-01-02")
+	}
 	transaction.Name = customer.FullName
 	transaction.InvoiceNumber = invoiceNumber
 	transaction.Address = customer.Address
 	transaction.PhoneNumber = customer.PhoneNumber
 	transaction.Company = company.CompanyName
+	transaction.TaxRate = company.TaxRate
 	transaction.UpdatedBy = transaction.CreatedBy
 	transaction.PaymentStatus = "paid"
 
+	lockedMeats, err := lockSoldMeats(meatRepo, transaction)
+	if err != nil {
+		return nil, err
+	}
+
 	var allmeat []string
 	for _, detail := range transaction.TransactionDetails {
-		meat, err := uc.meatRepo.GetMeatByID(detail.MeatID)
-		if err != nil {
-			return nil, err
+		meat, locked := lockedMeats[detail.MeatID]
+		if !locked {
+			meat, err = meatRepo.GetMeatByID(detail.MeatID)
+			if err != nil {
+				return nil, err
+			}
 		}
 		if meat == nil {
 			return nil, utils.ErrMeatNotFound
 		}
+		// Sales are priced from the customer's price list; a line without a
+		// price gets the list price, one too far off it needs an approval.
+		if transaction.TxType == "out" {
+			suggestion, err := suggestPrice(priceListRepo, customer, meat, todayDate)
+			if err != nil {
+				return nil, err
+			}
+			detail.ListPrice = suggestion.Price
+			if detail.Price == 0 {
+				detail.Price = suggestion.Price
+			}
+			if priceDeviates(suggestion, detail.Price) {
+				if !transaction.ApproveDeviation {
+					logrus.WithFields(logrus.Fields{
+						"meat_name":  meat.Name,
+						"price":      detail.Price,
+						"list_price": suggestion.Price,
+					}).Error("Price deviates from the price list")
+					return nil, utils.ErrPriceNeedsApproval
+				}
+				transaction.PriceApprovedBy = transaction.CreatedBy
+			}
+		}
 		if detail.Price <= 0 {
 			return nil, utils.ErrInvalidPrice
 		}
 		if detail.Qty <= 0 {
 			return nil, utils.ErrInvalidQty
 		}
+		if !model.IsValidDiscount(detail.DiscountType, detail.DiscountValue, detail.Price*detail.Qty) {
+			return nil, utils.ErrInvalidDiscount
+		}
+		detail.CalculateTotal()
 		detail.ID = uuid.NewString()
 		detail.MeatID = meat.ID
 		detail.MeatName = meat.Name
@@ -124,7 +256,18 @@ func (uc *transactionUseCase) CreateTransaction(transaction *model.TransactionHe
 		detail.CreatedBy = transaction.CreatedBy
 
 		if transaction.TxType == "in" {
-			err := uc.meatRepo.IncreaseStock(meat.ID, detail.Qty)
+			expiryDate, err := lotExpiryDate(meat, transaction.Date, detail.ExpiryDate)
+			if err != nil {
+				return nil, err
+			}
+			err = meatRepo.MoveStock(&model.StockMovement{
+				MeatID:     meat.ID,
+				Qty:        detail.Qty,
+				SourceType: model.StockSourcePurchase,
+				SourceID:   detail.ID,
+				Reference:  transaction.InvoiceNumber,
+				CreatedBy:  transaction.CreatedBy,
+			})
 			if err != nil {
 				logrus.WithFields(logrus.Fields{
 					"error":     err,
@@ -133,12 +276,48 @@ func (uc *transactionUseCase) CreateTransaction(transaction *model.TransactionHe
 				}).Error("Failed to increase meat stock")
 				return nil, err
 			}
+			err = meatLotRepo.CreateLot(&model.MeatLot{
+				MeatID:              meat.ID,
+				MeatName:            meat.Name,
+				TransactionDetailID: detail.ID,
+				Reference:           transaction.InvoiceNumber,
+				SupplierID:          customer.Id,
+				SupplierName:        customer.FullName,
+				ReceivedDate:        transaction.Date,
+				ExpiryDate:          expiryDate,
+				CostPrice:           detail.Total / detail.Qty,
+				QtyReceived:         detail.Qty,
+				QtyRemaining:        detail.Qty,
+				IsActive:            true,
+				CreatedBy:           transaction.CreatedBy,
+			})
+			if err != nil {
+				return nil, err
+			}
 		}
 		if transaction.TxType == "out" {
 			if detail.Qty >= meat.Stock {
 				return nil, utils.ErrMeatStockNotEnough
 			}
-			err = uc.meatRepo.ReduceStock(meat.ID, detail.Qty)
+			movement := &model.StockMovement{
+				ID:         uuid.NewString(),
+				MeatID:     meat.ID,
+				Qty:        -detail.Qty,
+				SourceType: model.StockSourceSale,
+				SourceID:   detail.ID,
+				Reference:  transaction.InvoiceNumber,
+				CreatedBy:  transaction.CreatedBy,
+			}
+			// The cost of the lots a sale consumes is its cost of goods sold.
+			consumptions, err := consumeLots(meatLotRepo, meat, detail.Qty, movement)
+			if err != nil {
+				return nil, err
+			}
+			detail.COGS = 0
+			for _, consumption := range consumptions {
+				detail.COGS += consumption.Qty * consumption.UnitCost
+			}
+			err = meatRepo.MoveStock(movement)
 			if err != nil {
 				logrus.WithFields(logrus.Fields{
 					"error":     err,
@@ -147,16 +326,29 @@ func (uc *transactionUseCase) CreateTransaction(transaction *model.TransactionHe
 				}).Error("Failed to reduce meat stock")
 				return nil, err
 			}
+			// Later lines of the same meat are checked against what is left.
+			meat.Stock -= detail.Qty
 		}
 		allmeat = append(allmeat, meat.Name)
 	}
-	transaction.CalulatedTotal()
-	newTotal := uc.UpdateTotalTransaction(transaction)
+	transaction.CalulatedTotal(uc.roundingUnit)
+	if !model.IsValidDiscount(transaction.DiscountType, transaction.DiscountValue, transaction.Subtotal) {
+		return nil, utils.ErrInvalidDiscount
+	}
+	newTotal := transaction.Total
+
+	if transaction.PaymentAmount > newTotal {
+		logrus.WithFields(logrus.Fields{
+			"payment_amount": transaction.PaymentAmount,
+			"new_total":      newTotal,
+		}).Error("Amount greater than total")
+		return nil, utils.ErrAmountGreaterThanTotal
+	}
 
 	if transaction.TxType == "in" {
 
 		// create expenditure
-		err := uc.dailyExpenditureRepo.CreateDailyExpenditure(&model.DailyExpenditure{
+		err := dailyExpenditureRepo.CreateDailyExpenditure(&model.DailyExpenditure{
 			ID:          uuid.NewString(),
 			DeNote:      transaction.InvoiceNumber,
 			Amount:      transaction.PaymentAmount,
@@ -167,7 +359,6 @@ func (uc *transactionUseCase) CreateTransaction(transaction *model.TransactionHe
 			Date:        transaction.Date,
 		})
 		if err != nil {
-			tx.Rollback()
 			logrus.WithFields(logrus.Fields{
 				"error": err,
 			}).Error("Failed to create daily expenditure")
@@ -176,48 +367,28 @@ func (uc *transactionUseCase) CreateTransaction(transaction *model.TransactionHe
 		}
 	}
 
-	if transaction.PaymentAmount > newTotal {
-		logrus.WithFields(logrus.Fields{
-			"payment_amount": transaction.PaymentAmount,
-			"new_total":      newTotal,
-		}).Error("Amount greater than total")
-		return nil, utils.ErrAmountGreaterThanTotal
-	}
-
 	if newTotal > transaction.PaymentAmount {
 		transaction.PaymentStatus = "unpaid"
 		notes = "Down Payment"
 		transaction.Debt = newTotal - transaction.PaymentAmount
-		if transaction.TxType == "in" {
-			transaction.Debt = transaction.PaymentAmount - newTotal
-		}
 	}
 	// Create transaction header
-	result, err := uc.transactionRepo.CreateTransactionHeader(transaction)
+	result, err := transactionRepo.CreateTransactionHeader(transaction)
 	if err != nil {
-		tx.Rollback()
 		logrus.WithFields(logrus.Fields{
 			"error": err,
 		}).Error("Failed to create transaction header")
 		return nil, fmt.Errorf("failed to create transaction: %w", err)
 	}
-	err = uc.transactionRepo.UpdateCustomerDebt(transaction.CustomerID, transaction.Debt)
-
+	err = customerLedgerRepo.CreateEntry(newInvoiceLedgerEntry(transaction))
 	if err != nil {
-		tx.Rollback()
 		logrus.WithFields(logrus.Fields{
 			"error": err,
-		}).Error("Failed to update customer debt")
-		return nil, err
-	}
-	if err := tx.Commit().Error; err != nil {
-		logrus.WithFields(logrus.Fields{
-			"error": err,
-		}).Error("Failed to commit transaction")
+		}).Error("Failed to book invoice on customer ledger")
 		return nil, err
 	}
 
-	err = uc.creditPaymentRepo.CreateCreditPayment(&model.CreditPayment{
+	payment := &model.CreditPayment{
 		ID:            uuid.New().String(),
 		InvoiceNumber: transaction.InvoiceNumber,
 		Amount:        transaction.PaymentAmount,
@@ -227,35 +398,24 @@ func (uc *transactionUseCase) CreateTransaction(transaction *model.TransactionHe
 		CreatedBy:     transaction.CreatedBy,
 		UpdatedBy:     transaction.CreatedBy,
 		Notes:         notes,
-	})
+		IsActive:      true,
+	}
+	err = creditPaymentRepo.CreateCreditPayment(payment)
 	if err != nil {
-		tx.Rollback()
+		logrus.WithFields(logrus.Fields{
+			"error": err,
+		}).Error("Failed to create credit payment")
 		return nil, err
 	}
-
-	transactionResponse := &model.TransactionHeaderResponse{
-		ID:                 result.ID,
-		Date:               result.Date,
-		InvoiceNumber:      result.InvoiceNumber,
-		CustomerID:         result.CustomerID,
-		Name:               result.Name,
-		Address:            result.Address,
-		Company:            result.Company,
-		PhoneNumber:        result.PhoneNumber,
-		TxType:             result.TxType,
-		PaymentStatus:      result.PaymentStatus,
-		PaymentAmount:      result.PaymentAmount,
-		Total:              result.Total,
-		IsActive:           result.IsActive,
-		CreatedAt:          time.Time{},
-		UpdatedAt:          time.Time{},
-		CreatedBy:          result.CreatedBy,
-		UpdatedBy:          result.UpdatedBy,
-		Debt:               result.Debt,
-		TransactionDetails: transaction.TransactionDetails,
+	err = customerLedgerRepo.CreateEntry(newPaymentLedgerEntry(transaction, payment))
+	if err != nil {
+		logrus.WithFields(logrus.Fields{
+			"error": err,
+		}).Error("Failed to book payment on customer ledger")
+		return nil, err
 	}
 
-	return transactionResponse, nil
+	return result, nil
 }
 
 func (uc *transactionUseCase) GetAllTransactions(page int, itemsPerPage int) ([]*model.TransactionHeader, int, error) {
@@ -287,26 +447,202 @@ func (uc *transactionUseCase) GetTransactionByID(id string) (*model.TransactionH
 	return transaction, nil
 }
 
-func (uc *transactionUseCase) DeleteTransaction(id string) error {
-	transaction, err := uc.transactionRepo.GetTransactionByID(id)
-	if transaction == nil {
-		return utils.ErrTransactionNotFound
-	}
+// VoidTransaction cancels an invoice and compensates everything it caused:
+// stock moves are reversed, the linked expenditures and credit payments are
+// deactivated and the customer ledger gets an entry cancelling the invoice
+// balance.
+func (uc *transactionUseCase) VoidTransaction(id string, reason string, voidedBy string) (*model.TransactionHeader, error) {
+	var transaction *model.TransactionHeader
+	err := uc.transactionRepo.GetDB().Transaction(func(tx *gorm.DB) error {
+		var err error
+		transaction, err = uc.voidTransaction(tx, id, reason, voidedBy)
+		return err
+	})
 	if err != nil {
-		return fmt.Errorf("failed to get transaction: %w", err)
+		return nil, err
 	}
 
-	return uc.transactionRepo.DeleteTransaction(id)
+	return transaction, nil
 }
 
-func (uc *transactionUseCase) UpdateTotalTransaction(transaction *model.TransactionHeader) float64 {
-	var newTotal float64
+func (uc *transactionUseCase) voidTransaction(tx *gorm.DB, id string, reason string, voidedBy string) (*model.TransactionHeader, error) {
+	transactionRepo := uc.transactionRepo.WithTx(tx)
+	meatRepo := uc.meatRepo.WithTx(tx)
+	creditPaymentRepo := uc.creditPaymentRepo.WithTx(tx)
+	dailyExpenditureRepo := uc.dailyExpenditureRepo.WithTx(tx)
+	customerLedgerRepo := uc.customerLedgerRepo.WithTx(tx)
+	meatLotRepo := uc.meatLotRepo.WithTx(tx)
+	dayClosingRepo := uc.dayClosingRepo.WithTx(tx)
+
+	transaction, err := transactionRepo.GetTransactionByIDForUpdate(id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get transaction: %w", err)
+	}
+	if transaction == nil {
+		return nil, utils.ErrTransactionNotFound
+	}
+
+	// Voiding takes back the invoice's payments and expenditures, which is not
+	// allowed once any of their days is closed.
+	payments, err := creditPaymentRepo.GetCreditPaymentsByInvoiceNumber(transaction.InvoiceNumber)
+	if err != nil {
+		return nil, err
+	}
+	dates := []string{transaction.Date}
+	for _, payment := range payments {
+		if payment.IsActive {
+			dates = append(dates, payment.PaymentDate)
+		}
+	}
+	if err := ensureDayOpen(dayClosingRepo, dates...); err != nil {
+		return nil, err
+	}
+
 	for _, detail := range transaction.TransactionDetails {
-		detail.Total = detail.Price * detail.Qty
-		newTotal = newTotal + detail.Total
+		if transaction.TxType == "in" {
+			meat, err := meatRepo.GetMeatByID(detail.MeatID)
+			if err != nil {
+				return nil, err
+			}
+			if meat != nil && meat.Stock < detail.Qty {
+				logrus.WithFields(logrus.Fields{
+					"meat_id":   detail.MeatID,
+					"meat_name": detail.MeatName,
+					"stock":     meat.Stock,
+					"qty":       detail.Qty,
+				}).Error("Stock already used, cannot void incoming transaction")
+				return nil, utils.ErrMeatStockNotEnough
+			}
+			movement := &model.StockMovement{
+				MeatID:     detail.MeatID,
+				Qty:        -detail.Qty,
+				SourceType: model.StockSourceVoid,
+				SourceID:   detail.ID,
+				Reference:  transaction.InvoiceNumber,
+				Notes:      reason,
+				CreatedBy:  voidedBy,
+			}
+			// The lot of this purchase goes away with it. Purchases made before
+			// lot tracking have no lot and are taken out of the oldest lots.
+			lot, err := meatLotRepo.GetLotByTransactionDetailIDForUpdate(detail.ID)
+			if err != nil {
+				return nil, err
+			}
+			if lot != nil && lot.QtyRemaining < lot.QtyReceived {
+				logrus.WithFields(logrus.Fields{
+					"meat_id":   detail.MeatID,
+					"meat_name": detail.MeatName,
+					"lot_id":    lot.ID,
+					"remaining": lot.QtyRemaining,
+				}).Error("Lot already consumed, cannot void incoming transaction")
+				return nil, utils.ErrLotAlreadyConsumed
+			}
+			switch {
+			case lot != nil:
+				err = meatLotRepo.RetireLot(lot.ID)
+				if err == nil {
+					err = meatRepo.MoveStock(movement)
+				}
+			case meat != nil:
+				err = moveStock(meatRepo, meatLotRepo, meat, movement)
+			default:
+				err = meatRepo.MoveStock(movement)
+			}
+			if err != nil {
+				logrus.WithFields(logrus.Fields{
+					"error":     err,
+					"meat_id":   detail.MeatID,
+					"meat_name": detail.MeatName,
+				}).Error("Failed to reduce meat stock")
+				return nil, err
+			}
+		}
+		if transaction.TxType == "out" {
+			// The sold qty goes back into the lots it was taken from.
+			err = restoreLots(meatLotRepo, model.StockSourceSale, detail.ID)
+			if err != nil {
+				return nil, err
+			}
+			err = meatRepo.MoveStock(&model.StockMovement{
+				MeatID:     detail.MeatID,
+				Qty:        detail.Qty,
+				SourceType: model.StockSourceVoid,
+				SourceID:   detail.ID,
+				Reference:  transaction.InvoiceNumber,
+				Notes:      reason,
+				CreatedBy:  voidedBy,
+			})
+			if err != nil {
+				logrus.WithFields(logrus.Fields{
+					"error":     err,
+					"meat_id":   detail.MeatID,
+					"meat_name": detail.MeatName,
+				}).Error("Failed to increase meat stock")
+				return nil, err
+			}
+		}
+	}
+
+	err = dailyExpenditureRepo.DeactivateDailyExpendituresByNote(transaction.InvoiceNumber, voidedBy)
+	if err != nil {
+		logrus.WithFields(logrus.Fields{
+			"error": err,
+		}).Error("Failed to deactivate daily expenditures")
+		return nil, err
+	}
+	err = creditPaymentRepo.DeactivateCreditPaymentsByInvoiceNumber(transaction.InvoiceNumber, voidedBy)
+	if err != nil {
+		logrus.WithFields(logrus.Fields{
+			"error": err,
+		}).Error("Failed to deactivate credit payments")
+		return nil, err
+	}
+
+	balance, err := customerLedgerRepo.GetBalanceByReference(transaction.CustomerID, transaction.InvoiceNumber)
+	if err != nil {
+		return nil, err
+	}
+	if balance != 0 {
+		entry := &model.CustomerLedgerEntry{
+			ID:          uuid.NewString(),
+			CustomerID:  transaction.CustomerID,
+			TxType:      transaction.TxType,
+			EntryDate:   time.Now().Format("2006-01-02"),
+			SourceType:  model.LedgerSourceVoid,
+			SourceID:    transaction.ID,
+			Reference:   transaction.InvoiceNumber,
+			Description: "Void " + transaction.InvoiceNumber + ": " + reason,
+			CreatedBy:   voidedBy,
+		}
+		if balance > 0 {
+			entry.Credit = balance
+		} else {
+			entry.Debit = -balance
+		}
+		err = customerLedgerRepo.CreateEntry(entry)
+		if err != nil {
+			logrus.WithFields(logrus.Fields{
+				"error": err,
+			}).Error("Failed to book void on customer ledger")
+			return nil, err
+		}
 	}
 
-	return newTotal
+	err = transactionRepo.VoidTransaction(transaction.ID, voidedBy, reason)
+	if err != nil {
+		logrus.WithFields(logrus.Fields{
+			"error": err,
+		}).Error("Failed to void transaction")
+		return nil, err
+	}
+
+	voidedAt := time.Now()
+	transaction.IsActive = false
+	transaction.VoidedAt = &voidedAt
+	transaction.VoidedBy = voidedBy
+	transaction.VoidReason = reason
+
+	return transaction, nil
 }
 
 func (uc *transactionUseCase) GetTransactionByInvoiceNumber(inv_number string) (*model.TransactionHeader, error) {
@@ -321,7 +657,35 @@ func (uc *transactionUseCase) GetTransactionByInvoiceNumber(inv_number string) (
 	return transaction, nil
 }
 
-func NewTransactionUseCase(transactionRepo repository.TransactionRepository, customerRepo repository.CustomerRepository, meatRepo repository.MeatRepository, companyRepo repository.CompanyRepository, creditPaymentRepo repository.CreditPaymentRepository, dailyExpenditureRepo repository.DailyExpenditureRepository) TransactionUseCase {
+// ExportTransactions writes the invoices matching the filter to w as a
+// spreadsheet, one row per invoice.
+func (uc *transactionUseCase) ExportTransactions(filter *model.ExportFilter, format string, w io.Writer) error {
+	if filter.TxType != "" && filter.TxType != "in" && filter.TxType != "out" {
+		return utils.ErrInvalidTxType
+	}
+	if filter.PaymentStatus != "" && filter.PaymentStatus != "paid" && filter.PaymentStatus != "unpaid" {
+		return utils.ErrInvalidPaymentStatus
+	}
+	writer, err := export.NewWriter(format, w, "Invoice Number", "Date", "Due Date", "Type", "Customer", "Company",
+		"Phone Number", "Payment Status", "Subtotal", "Discount", "Tax", "Rounding", "Total", "Paid", "Debt", "Created By")
+	if err != nil {
+		return utils.ErrInvalidExportFormat
+	}
+	err = uc.transactionRepo.StreamTransactions(filter, func(transaction *model.TransactionHeader) error {
+		return writer.WriteRow(transaction.InvoiceNumber, transaction.Date[:min(len(transaction.Date), 10)],
+			transaction.DueDate[:min(len(transaction.DueDate), 10)], transaction.TxType, transaction.Name, transaction.Company,
+			transaction.PhoneNumber, transaction.PaymentStatus, transaction.Subtotal, transaction.Discount, transaction.Tax,
+			transaction.Rounding, transaction.Total, transaction.Total-transaction.Debt,
+			transaction.Debt, transaction.CreatedBy)
+	})
+	if err != nil {
+		logrus.WithField("error", err).Error("Failed to export transactions")
+		return err
+	}
+	return writer.Close()
+}
+
+func NewTransactionUseCase(transactionRepo repository.TransactionRepository, customerRepo repository.CustomerRepository, meatRepo repository.MeatRepository, companyRepo repository.CompanyRepository, creditPaymentRepo repository.CreditPaymentRepository, dailyExpenditureRepo repository.DailyExpenditureRepository, customerLedgerRepo repository.CustomerLedgerRepository, meatLotRepo repository.MeatLotRepository, dayClosingRepo repository.DayClosingRepository, sequenceRepo repository.SequenceRepository, numberingSchemeRepo repository.NumberingSchemeRepository, priceListRepo repository.PriceListRepository, roundingUnit float64) TransactionUseCase {
 	return &transactionUseCase{
 		transactionRepo:      transactionRepo,
 		customerRepo:         customerRepo,
@@ -329,5 +693,12 @@ func NewTransactionUseCase(transactionRepo repository.TransactionRepository, cus
 		companyRepo:          companyRepo,
 		creditPaymentRepo:    creditPaymentRepo,
 		dailyExpenditureRepo: dailyExpenditureRepo,
+		customerLedgerRepo:   customerLedgerRepo,
+		meatLotRepo:          meatLotRepo,
+		dayClosingRepo:       dayClosingRepo,
+		sequenceRepo:         sequenceRepo,
+		numberingSchemeRepo:  numberingSchemeRepo,
+		priceListRepo:        priceListRepo,
+		roundingUnit:         roundingUnit,
 	}
 }
@@ -3,14 +3,18 @@ package usecase
 import (
 	"fmt"
 	"time"
+	"trackprosto/config"
 	"trackprosto/delivery/utils"
 	model "trackprosto/models"
 	"trackprosto/repository"
+	"trackprosto/utils/tokenversion"
+
+	"gorm.io/gorm"
 )
 
 type UserUseCase interface {
 	CreateUser(user *model.User) error
-	UpdateUser(user *model.UserRequest) error
+	UpdateUser(user *model.UserRequest) (*model.User, error)
 	GetUserByID(id string) (*model.User, error)
 	GetAllUsers() ([]*model.User, error)
 	DeleteUser(id string) error
@@ -18,18 +22,31 @@ type UserUseCase interface {
 }
 
 type userUseCase struct {
-	userRepository repository.UserRepository
+	userRepository         repository.UserRepository
+	refreshTokenRepository repository.RefreshTokenRepository
+	tokenVersions          *tokenversion.Cache
+	passwordPolicy         config.PasswordPolicy
 }
 
-func NewUserUseCase(userRepo repository.UserRepository) UserUseCase {
+func NewUserUseCase(userRepo repository.UserRepository, refreshTokenRepo repository.RefreshTokenRepository, tokenVersions *tokenversion.Cache, passwordPolicy config.PasswordPolicy) UserUseCase {
 	return &userUseCase{
-		userRepository: userRepo,
+		userRepository:         userRepo,
+		refreshTokenRepository: refreshTokenRepo,
+		tokenVersions:          tokenVersions,
+		passwordPolicy:         passwordPolicy,
 	}
 }
 
+// CreateUser stores a new user. user.Password holds the plain password, which
+// is replaced by its hash.
 func (uc *userUseCase) CreateUser(user *model.User) error {
-	// Implement any business logic or validation before creating the user
-	// You can also perform data manipulation or enrichment if needed
+	if user.Username == "" {
+		return utils.ErrUsernameRequired
+	}
+	hashedPassword, err := hashPassword(uc.passwordPolicy, user.Username, user.Password)
+	if err != nil {
+		return err
+	}
 
 	existingUser, err := uc.userRepository.GetByUsername(user.Username)
 	if err != nil {
@@ -39,6 +56,7 @@ func (uc *userUseCase) CreateUser(user *model.User) error {
 		return fmt.Errorf("username already exists")
 	}
 
+	user.Password = hashedPassword
 	user.IsActive = true
 	user.CreatedAt = time.Now()
 	user.CreatedBy = "admin"
@@ -52,35 +70,64 @@ func (uc *userUseCase) CreateUser(user *model.User) error {
 	return nil
 }
 
-func (uc *userUseCase) UpdateUser(userRequest *model.UserRequest) error {
+// UpdateUser changes the fields given in userRequest. A new password is
+// checked against the password policy and stored hashed.
+func (uc *userUseCase) UpdateUser(userRequest *model.UserRequest) (*model.User, error) {
 
 	
 	userRepo, err := uc.userRepository.GetUserByID(userRequest.ID)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	if userRepo == nil {
-		return utils.ErrUserNotFound
+		return nil, utils.ErrUserNotFound
 	}
 
 	
 	user := &model.User{
-		ID:        userRequest.ID,
-		Username:  utils.NonEmpty(userRequest.Username, userRepo.Username),
-		Password:  utils.NonEmpty(userRequest.Password, userRepo.Password),
-		Role:      utils.NonEmpty(userRequest.Role, userRepo.Role),
-		IsActive:  userRepo.IsActive,
-		UpdatedBy: userRequest.UpdatedBy,
-		UpdatedAt: time.Now(),
-		CreatedAt: userRepo.CreatedAt,
-		CreatedBy: userRepo.CreatedBy,
-	}
-	err = uc.userRepository.UpdateUser(user)
-	if err != nil {	
-		return err
+		ID:                 userRequest.ID,
+		Username:           utils.NonEmpty(userRequest.Username, userRepo.Username),
+		Password:           userRepo.Password,
+		Role:               utils.NonEmpty(userRequest.Role, userRepo.Role),
+		IsActive:           userRepo.IsActive,
+		TokenVersion:       userRepo.TokenVersion,
+		MustChangePassword: userRepo.MustChangePassword,
+		UpdatedBy:          userRequest.UpdatedBy,
+		UpdatedAt:          time.Now(),
+		CreatedAt:          userRepo.CreatedAt,
+		CreatedBy:          userRepo.CreatedBy,
+	}
+	// Tokens carry the username and role they were issued with; changing
+	// either revokes them, refresh tokens included.
+	if user.Role != userRepo.Role || user.Username != userRepo.Username {
+		user.TokenVersion++
+	}
+	// Setting a new password also ends the sessions opened with the old one.
+	if userRequest.Password != "" {
+		hashedPassword, err := hashPassword(uc.passwordPolicy, user.Username, userRequest.Password)
+		if err != nil {
+			return nil, err
+		}
+		user.Password = hashedPassword
+		if user.TokenVersion == userRepo.TokenVersion {
+			user.TokenVersion++
+		}
+	}
+	err = uc.userRepository.GetDB().Transaction(func(tx *gorm.DB) error {
+		if err := uc.userRepository.WithTx(tx).UpdateUser(user); err != nil {
+			return err
+		}
+		if user.TokenVersion == userRepo.TokenVersion {
+			return nil
+		}
+		return uc.refreshTokenRepository.WithTx(tx).RevokeUserRefreshTokens(user.ID)
+	})
+	if err != nil {
+		return nil, err
 	}
+	uc.tokenVersions.Invalidate(user.ID)
 
-	return nil
+	return user, nil
 }
 
 func (uc *userUseCase) GetUserByID(id string) (*model.User, error) {
@@ -128,6 +175,7 @@ func (uc *userUseCase) DeleteUser(username string) error {
 	if err != nil {
 		return err
 	}
+	uc.tokenVersions.Invalidate(existingUser.ID)
 
 	return nil
 }
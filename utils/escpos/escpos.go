@@ -0,0 +1,133 @@
+// Package escpos builds receipts for ESC/POS thermal printers. Text is sent in
+// the printer's default font A, whose line width depends on the paper roll.
+package escpos
+
+import (
+	"bytes"
+	"strings"
+)
+
+type Align byte
+
+const (
+	AlignLeft   Align = 0
+	AlignCenter Align = 1
+	AlignRight  Align = 2
+)
+
+// Columns returns how many font A characters fit on a line of the given paper
+// width in millimetres, or 0 when the width is not supported.
+func Columns(paperWidth int) int {
+	switch paperWidth {
+	case 58:
+		return 32
+	case 80:
+		return 48
+	}
+	return 0
+}
+
+type Receipt struct {
+	buf     bytes.Buffer
+	Columns int
+}
+
+// New starts a receipt for a printer with the given number of columns.
+func New(columns int) *Receipt {
+	r := &Receipt{Columns: columns}
+	r.buf.Write([]byte{0x1b, 0x40}) // ESC @: reset the printer
+	return r
+}
+
+func (r *Receipt) SetAlign(align Align) {
+	r.buf.Write([]byte{0x1b, 0x61, byte(align)})
+}
+
+func (r *Receipt) SetBold(bold bool) {
+	r.buf.Write([]byte{0x1b, 0x45, boolByte(bold)})
+}
+
+// SetDoubleSize prints double width and height, halving the columns.
+func (r *Receipt) SetDoubleSize(double bool) {
+	size := byte(0x00)
+	if double {
+		size = 0x11
+	}
+	r.buf.Write([]byte{0x1d, 0x21, size})
+}
+
+// Line prints s and moves to the next line. Text longer than a line is left to
+// the printer to wrap.
+func (r *Receipt) Line(s string) {
+	r.buf.WriteString(toASCII(s))
+	r.buf.WriteByte('\n')
+}
+
+// Separator prints a full line of dashes.
+func (r *Receipt) Separator() {
+	r.Line(strings.Repeat("-", r.Columns))
+}
+
+// Pair prints left and right on one line, pushed to both edges.
+func (r *Receipt) Pair(left string, right string) {
+	right = Truncate(toASCII(right), r.Columns)
+	left = Truncate(toASCII(left), r.Columns-len(right)-1)
+	r.Line(left + strings.Repeat(" ", r.Columns-len(left)-len(right)) + right)
+}
+
+// Row prints cells in fixed width columns. The first column is left aligned,
+// the others right aligned; cells are cut to their width.
+func (r *Receipt) Row(widths []int, cells ...string) {
+	var line strings.Builder
+	for i, cell := range cells {
+		cell = Truncate(toASCII(cell), widths[i])
+		padding := strings.Repeat(" ", widths[i]-len(cell))
+		if i == 0 {
+			line.WriteString(cell + padding)
+		} else {
+			line.WriteString(padding + cell)
+		}
+	}
+	r.Line(line.String())
+}
+
+// Cut feeds the paper past the cutter and cuts it.
+func (r *Receipt) Cut() {
+	r.buf.Write([]byte{0x1b, 0x64, 0x03}) // ESC d 3: feed three lines
+	r.buf.Write([]byte{0x1d, 0x56, 0x42, 0x00})
+}
+
+func (r *Receipt) Bytes() []byte {
+	return r.buf.Bytes()
+}
+
+// Truncate cuts s to at most width characters.
+func Truncate(s string, width int) string {
+	if width <= 0 {
+		return ""
+	}
+	if len(s) > width {
+		return s[:width]
+	}
+	return s
+}
+
+// toASCII replaces what the printer's code page may not have, and control
+// characters that would be taken as commands.
+func toASCII(s string) string {
+	out := make([]byte, 0, len(s))
+	for _, c := range s {
+		if c < 32 || c > 126 {
+			c = '?'
+		}
+		out = append(out, byte(c))
+	}
+	return string(out)
+}
+
+func boolByte(b bool) byte {
+	if b {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,203 @@
+// Package export streams tables as CSV or XLSX files. Rows are written as they
+// come, so a large export never has to be held in memory.
+package export
+
+import (
+	"archive/zip"
+	"encoding/csv"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+const (
+	FormatCSV  = "csv"
+	FormatXLSX = "xlsx"
+)
+
+func IsValidFormat(format string) bool {
+	return format == FormatCSV || format == FormatXLSX
+}
+
+func ContentType(format string) string {
+	if format == FormatXLSX {
+		return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	}
+	return "text/csv"
+}
+
+// Writer writes the rows of one table. Cells may be strings or numbers; the
+// header is written together with the first row, so nothing reaches the
+// output until there is something to export or the writer is closed.
+type Writer interface {
+	WriteRow(cells ...interface{}) error
+	Close() error
+}
+
+// NewWriter returns a Writer for the given format writing to w.
+func NewWriter(format string, w io.Writer, header ...string) (Writer, error) {
+	switch format {
+	case FormatCSV:
+		return &csvWriter{w: csv.NewWriter(w), header: header}, nil
+	case FormatXLSX:
+		return &xlsxWriter{zip: zip.NewWriter(w), header: header}, nil
+	}
+	return nil, fmt.Errorf("unknown export format %q", format)
+}
+
+type csvWriter struct {
+	w       *csv.Writer
+	header  []string
+	started bool
+}
+
+func (cw *csvWriter) start() error {
+	if cw.started {
+		return nil
+	}
+	cw.started = true
+	return cw.w.Write(cw.header)
+}
+
+func (cw *csvWriter) WriteRow(cells ...interface{}) error {
+	if err := cw.start(); err != nil {
+		return err
+	}
+	record := make([]string, len(cells))
+	for i, cell := range cells {
+		record[i] = formatCell(cell)
+		if _, ok := cell.(string); ok {
+			record[i] = escapeFormula(record[i])
+		}
+	}
+	return cw.w.Write(record)
+}
+
+// escapeFormula prefixes text a spreadsheet would run as a formula with a
+// quote, so it opens as plain text.
+func escapeFormula(text string) string {
+	if text != "" && strings.ContainsRune("=+-@", rune(text[0])) {
+		return "'" + text
+	}
+	return text
+}
+
+func (cw *csvWriter) Close() error {
+	if err := cw.start(); err != nil {
+		return err
+	}
+	cw.w.Flush()
+	return cw.w.Error()
+}
+
+// xlsxWriter writes a workbook with a single sheet, using inline strings so
+// the sheet can be written in one pass.
+type xlsxWriter struct {
+	zip     *zip.Writer
+	sheet   io.Writer
+	header  []string
+	rows    int
+	started bool
+}
+
+var xlsxParts = []struct{ name, content string }{
+	{"[Content_Types].xml", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types"><Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/><Default Extension="xml" ContentType="application/xml"/><Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/><Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/></Types>`},
+	{"_rels/.rels", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships"><Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/></Relationships>`},
+	{"xl/workbook.xml", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"><sheets><sheet name="Sheet1" sheetId="1" r:id="rId1"/></sheets></workbook>`},
+	{"xl/_rels/workbook.xml.rels", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships"><Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/></Relationships>`},
+}
+
+func (xw *xlsxWriter) start() error {
+	if xw.started {
+		return nil
+	}
+	xw.started = true
+	for _, part := range xlsxParts {
+		f, err := xw.zip.Create(part.name)
+		if err != nil {
+			return err
+		}
+		if _, err := io.WriteString(f, part.content); err != nil {
+			return err
+		}
+	}
+	sheet, err := xw.zip.Create("xl/worksheets/sheet1.xml")
+	if err != nil {
+		return err
+	}
+	xw.sheet = sheet
+	_, err = io.WriteString(sheet, `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`)
+	if err != nil {
+		return err
+	}
+	header := make([]interface{}, len(xw.header))
+	for i, name := range xw.header {
+		header[i] = name
+	}
+	return xw.writeRow(header)
+}
+
+func (xw *xlsxWriter) WriteRow(cells ...interface{}) error {
+	if err := xw.start(); err != nil {
+		return err
+	}
+	return xw.writeRow(cells)
+}
+
+func (xw *xlsxWriter) writeRow(cells []interface{}) error {
+	xw.rows++
+	if _, err := fmt.Fprintf(xw.sheet, `<row r="%d">`, xw.rows); err != nil {
+		return err
+	}
+	for _, cell := range cells {
+		var err error
+		switch value := cell.(type) {
+		case int, int64, float64:
+			_, err = fmt.Fprintf(xw.sheet, `<c><v>%s</v></c>`, formatCell(value))
+		default:
+			if _, err = io.WriteString(xw.sheet, `<c t="inlineStr"><is><t xml:space="preserve">`); err == nil {
+				if err = xml.EscapeText(xw.sheet, []byte(formatCell(value))); err == nil {
+					_, err = io.WriteString(xw.sheet, `</t></is></c>`)
+				}
+			}
+		}
+		if err != nil {
+			return err
+		}
+	}
+	_, err := io.WriteString(xw.sheet, `</row>`)
+	return err
+}
+
+func (xw *xlsxWriter) Close() error {
+	if err := xw.start(); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(xw.sheet, `</sheetData></worksheet>`); err != nil {
+		return err
+	}
+	return xw.zip.Close()
+}
+
+func formatCell(cell interface{}) string {
+	switch value := cell.(type) {
+	case nil:
+		return ""
+	case string:
+		return value
+	case int:
+		return strconv.Itoa(value)
+	case int64:
+		return strconv.FormatInt(value, 10)
+	case float64:
+		return strconv.FormatFloat(value, 'f', -1, 64)
+	}
+	return fmt.Sprint(cell)
+}
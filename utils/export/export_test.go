@@ -0,0 +1,143 @@
+package export
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestCSVWriter(t *testing.T) {
+	tests := []struct {
+		name string
+		rows [][]interface{}
+		want string
+	}{
+		{"header only", nil, "Name,Qty,Total\n"},
+		{
+			"cells formatted",
+			[][]interface{}{
+				{"Sirloin", 1.5, 150000},
+				{"Tenderloin", int64(2), nil},
+			},
+			"Name,Qty,Total\nSirloin,1.5,150000\nTenderloin,2,\n",
+		},
+		{
+			"text quoted",
+			[][]interface{}{{`Iga "super", 1kg`, 0.25, 1e6}},
+			"Name,Qty,Total\n\"Iga \"\"super\"\", 1kg\",0.25,1000000\n",
+		},
+		{
+			"formulas escaped",
+			[][]interface{}{
+				{"=HYPERLINK(\"x\")", -1.5, "+62"},
+				{"-Iga", -2, "@SUM(A1)"},
+			},
+			"Name,Qty,Total\n\"'=HYPERLINK(\"\"x\"\")\",-1.5,'+62\n'-Iga,-2,'@SUM(A1)\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			writer, err := NewWriter(FormatCSV, &buf, "Name", "Qty", "Total")
+			if err != nil {
+				t.Fatalf("NewWriter() error = %v", err)
+			}
+			for _, row := range tt.rows {
+				if err := writer.WriteRow(row...); err != nil {
+					t.Fatalf("WriteRow() error = %v", err)
+				}
+			}
+			if err := writer.Close(); err != nil {
+				t.Fatalf("Close() error = %v", err)
+			}
+			if got := buf.String(); got != tt.want {
+				t.Errorf("output = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestXLSXWriter(t *testing.T) {
+	var buf bytes.Buffer
+	writer, err := NewWriter(FormatXLSX, &buf, "Name", "Total")
+	if err != nil {
+		t.Fatalf("NewWriter() error = %v", err)
+	}
+	if err := writer.WriteRow("Iga <sapi> & co", 150000.5); err != nil {
+		t.Fatalf("WriteRow() error = %v", err)
+	}
+	if err := writer.WriteRow("Tenderloin", 7); err != nil {
+		t.Fatalf("WriteRow() error = %v", err)
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	archive, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("output is not a zip archive: %v", err)
+	}
+	parts := make(map[string]string)
+	for _, file := range archive.File {
+		f, err := file.Open()
+		if err != nil {
+			t.Fatalf("open %s: %v", file.Name, err)
+		}
+		content, err := io.ReadAll(f)
+		f.Close()
+		if err != nil {
+			t.Fatalf("read %s: %v", file.Name, err)
+		}
+		parts[file.Name] = string(content)
+	}
+	for _, name := range []string{"[Content_Types].xml", "_rels/.rels", "xl/workbook.xml", "xl/_rels/workbook.xml.rels", "xl/worksheets/sheet1.xml"} {
+		if _, ok := parts[name]; !ok {
+			t.Errorf("workbook misses %s", name)
+		}
+	}
+
+	var sheet struct {
+		Rows []struct {
+			R     int `xml:"r,attr"`
+			Cells []struct {
+				Type   string `xml:"t,attr"`
+				Value  string `xml:"v"`
+				Inline string `xml:"is>t"`
+			} `xml:"c"`
+		} `xml:"sheetData>row"`
+	}
+	if err := xml.Unmarshal([]byte(parts["xl/worksheets/sheet1.xml"]), &sheet); err != nil {
+		t.Fatalf("sheet is not valid XML: %v", err)
+	}
+	var got []string
+	for i, row := range sheet.Rows {
+		if row.R != i+1 {
+			t.Errorf("row %d numbered %d", i+1, row.R)
+		}
+		var cells []string
+		for _, cell := range row.Cells {
+			if cell.Type == "inlineStr" {
+				cells = append(cells, "s:"+cell.Inline)
+			} else {
+				cells = append(cells, "n:"+cell.Value)
+			}
+		}
+		got = append(got, strings.Join(cells, "|"))
+	}
+	want := []string{"s:Name|s:Total", "s:Iga <sapi> & co|n:150000.5", "s:Tenderloin|n:7"}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("rows = %q, want %q", got, want)
+	}
+}
+
+func TestNewWriterUnknownFormat(t *testing.T) {
+	if _, err := NewWriter("pdf", io.Discard, "Name"); err == nil {
+		t.Error("NewWriter(pdf) error = nil, want unknown format")
+	}
+	if IsValidFormat("pdf") {
+		t.Error("IsValidFormat(pdf) = true")
+	}
+}
@@ -0,0 +1,144 @@
+// Package pdf writes simple single-column documents such as invoices and
+// receipts as PDF, using only the standard Helvetica fonts every reader ships
+// with so no font files have to be embedded.
+package pdf
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// A4 page size in points.
+const (
+	PageWidth  = 595.28
+	PageHeight = 841.89
+)
+
+type Document struct {
+	pages []*bytes.Buffer
+}
+
+func New() *Document {
+	return &Document{}
+}
+
+// AddPage starts a new page; everything drawn afterwards goes on it.
+func (d *Document) AddPage() {
+	d.pages = append(d.pages, &bytes.Buffer{})
+}
+
+func (d *Document) page() *bytes.Buffer {
+	if len(d.pages) == 0 {
+		d.AddPage()
+	}
+	return d.pages[len(d.pages)-1]
+}
+
+// Text draws s with its baseline starting at x, y. Coordinates are in points
+// measured from the top left corner of the page.
+func (d *Document) Text(x, y, size float64, bold bool, s string) {
+	font := "F1"
+	if bold {
+		font = "F2"
+	}
+	fmt.Fprintf(d.page(), "BT /%s %.2f Tf %.2f %.2f Td (%s) Tj ET\n", font, size, x, PageHeight-y, escape(s))
+}
+
+// TextRight draws s so that it ends at x.
+func (d *Document) TextRight(x, y, size float64, bold bool, s string) {
+	d.Text(x-TextWidth(s, size, bold), y, size, bold, s)
+}
+
+// Line draws a thin line from x1, y1 to x2, y2.
+func (d *Document) Line(x1, y1, x2, y2 float64) {
+	fmt.Fprintf(d.page(), "0.5 w %.2f %.2f m %.2f %.2f l S\n", x1, PageHeight-y1, x2, PageHeight-y2)
+}
+
+// TextWidth returns the width of s in points when drawn at the given size.
+func TextWidth(s string, size float64, bold bool) float64 {
+	widths := helveticaWidths
+	if bold {
+		widths = helveticaBoldWidths
+	}
+	total := 0
+	for _, r := range toLatin(s) {
+		if r >= 32 && int(r)-32 < len(widths) {
+			total += widths[r-32]
+		} else {
+			total += widths['?'-32]
+		}
+	}
+	return float64(total) * size / 1000
+}
+
+// Bytes returns the finished document.
+func (d *Document) Bytes() []byte {
+	d.page()
+
+	var out bytes.Buffer
+	var offsets []int
+	object := func(body string) {
+		offsets = append(offsets, out.Len())
+		fmt.Fprintf(&out, "%d 0 obj\n%s\nendobj\n", len(offsets), body)
+	}
+
+	out.WriteString("%PDF-1.4\n")
+	// Objects 1 to 4 are fixed, each page then takes a page and a content object.
+	kids := make([]string, len(d.pages))
+	for i := range d.pages {
+		kids[i] = fmt.Sprintf("%d 0 R", 5+i*2)
+	}
+	object("<< /Type /Catalog /Pages 2 0 R >>")
+	object(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(d.pages)))
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>")
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica-Bold /Encoding /WinAnsiEncoding >>")
+	for i, content := range d.pages {
+		object(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %.2f %.2f] /Resources << /Font << /F1 3 0 R /F2 4 0 R >> >> /Contents %d 0 R >>",
+			PageWidth, PageHeight, 6+i*2))
+		object(fmt.Sprintf("<< /Length %d >>\nstream\n%sendstream", content.Len(), content.String()))
+	}
+
+	xref := out.Len()
+	fmt.Fprintf(&out, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&out, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&out, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xref)
+	return out.Bytes()
+}
+
+// toLatin replaces the characters the standard fonts cannot show.
+func toLatin(s string) []byte {
+	out := make([]byte, 0, len(s))
+	for _, r := range s {
+		if r < 32 || r > 126 {
+			r = '?'
+		}
+		out = append(out, byte(r))
+	}
+	return out
+}
+
+func escape(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "(", `\(`, ")", `\)`).Replace(string(toLatin(s)))
+}
+
+// Glyph widths of the printable ASCII characters, from the Adobe font metrics.
+var helveticaWidths = []int{
+	278, 278, 355, 556, 556, 889, 667, 191, 333, 333, 389, 584, 278, 333, 278, 278,
+	556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 278, 278, 584, 584, 584, 556,
+	1015, 667, 667, 722, 722, 667, 611, 778, 722, 278, 500, 667, 556, 833, 722, 778,
+	667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 278, 278, 278, 469, 556,
+	333, 556, 556, 500, 556, 556, 278, 556, 556, 222, 222, 500, 222, 833, 556, 556,
+	556, 556, 333, 500, 278, 556, 500, 722, 500, 500, 500, 334, 260, 334, 584,
+}
+
+var helveticaBoldWidths = []int{
+	278, 333, 474, 556, 556, 889, 722, 238, 333, 333, 389, 584, 278, 333, 278, 278,
+	556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 333, 333, 584, 584, 584, 611,
+	975, 722, 722, 722, 722, 667, 611, 778, 722, 278, 556, 722, 611, 833, 722, 778,
+	667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 333, 278, 333, 584, 556,
+	333, 556, 611, 556, 611, 556, 333, 611, 611, 278, 278, 556, 278, 889, 611, 611,
+	611, 611, 389, 556, 333, 611, 556, 778, 556, 556, 500, 389, 280, 389, 584,
+}
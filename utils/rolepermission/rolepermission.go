@@ -0,0 +1,59 @@
+// Package rolepermission keeps the permissions granted to each role in
+// memory, so authorizing a request does not hit the database.
+package rolepermission
+
+import (
+	"sync"
+	"time"
+)
+
+// TTL bounds how long the cached grants are trusted. Changes made in this
+// process invalidate the cache at once, changes made by another instance are
+// picked up within TTL.
+const TTL = 30 * time.Second
+
+// Loader returns the permission codes granted to every role.
+type Loader func() (map[string][]string, error)
+
+type Cache struct {
+	load     Loader
+	mu       sync.RWMutex
+	grants   map[string]map[string]bool
+	loadedAt time.Time
+}
+
+func NewCache(load Loader) *Cache {
+	return &Cache{load: load}
+}
+
+// Allowed reports whether role has been granted permission.
+func (c *Cache) Allowed(role string, permission string) (bool, error) {
+	c.mu.RLock()
+	grants, loadedAt := c.grants, c.loadedAt
+	c.mu.RUnlock()
+	if grants == nil || time.Since(loadedAt) > TTL {
+		roles, err := c.load()
+		if err != nil {
+			return false, err
+		}
+		grants = make(map[string]map[string]bool, len(roles))
+		for r, permissions := range roles {
+			grants[r] = make(map[string]bool, len(permissions))
+			for _, p := range permissions {
+				grants[r][p] = true
+			}
+		}
+		c.mu.Lock()
+		c.grants, c.loadedAt = grants, time.Now()
+		c.mu.Unlock()
+	}
+	return grants[role][permission], nil
+}
+
+// Invalidate drops the cached grants, so the next request reads them from
+// the database again.
+func (c *Cache) Invalidate() {
+	c.mu.Lock()
+	c.grants = nil
+	c.mu.Unlock()
+}
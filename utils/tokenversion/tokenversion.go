@@ -0,0 +1,61 @@
+// Package tokenversion keeps the token version of users in memory. A user's
+// tokens carry the version current when they were issued; bumping the version
+// in the users table invalidates them all.
+package tokenversion
+
+import (
+	"sync"
+	"time"
+)
+
+// TTL bounds how long a cached version is trusted. Changes made in this
+// process invalidate the cache at once, changes made by another instance are
+// picked up within TTL.
+const TTL = 30 * time.Second
+
+// Loader returns the current token version of a user. ok is false when the
+// user no longer exists or is inactive.
+type Loader func(userID string) (version int, ok bool, err error)
+
+type Cache struct {
+	load    Loader
+	mu      sync.RWMutex
+	entries map[string]entry
+}
+
+type entry struct {
+	version  int
+	ok       bool
+	loadedAt time.Time
+}
+
+func NewCache(load Loader) *Cache {
+	return &Cache{load: load, entries: make(map[string]entry)}
+}
+
+// Valid reports whether a token issued to userID with the given version is
+// still valid.
+func (c *Cache) Valid(userID string, version int) (bool, error) {
+	c.mu.RLock()
+	current, cached := c.entries[userID]
+	c.mu.RUnlock()
+	if !cached || time.Since(current.loadedAt) > TTL {
+		v, ok, err := c.load(userID)
+		if err != nil {
+			return false, err
+		}
+		current = entry{version: v, ok: ok, loadedAt: time.Now()}
+		c.mu.Lock()
+		c.entries[userID] = current
+		c.mu.Unlock()
+	}
+	return current.ok && current.version == version, nil
+}
+
+// Invalidate drops the cached version of a user, so the next request reads
+// it from the database again.
+func (c *Cache) Invalidate(userID string) {
+	c.mu.Lock()
+	delete(c.entries, userID)
+	c.mu.Unlock()
+}